@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/archive"
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+	"github.com/jedipunkz/datadog-log-tail/internal/credentials"
+	"github.com/jedipunkz/datadog-log-tail/internal/datadog"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	archiveLogs bool
+	archiveDir  string
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Fetch a time range into the local archive",
+	Long: `Fetch the logs of a time range (--since or --timestamp) matching -q and -l into
+the local archive, without printing them. Parts of the range the archive already
+covers for the query are not fetched again.
+
+Archived time ranges can then be searched without the API with dlt search
+--local. With --archive, logs fetched by other commands are archived as well.
+
+The archive is kept per profile in $XDG_DATA_HOME/dlt/archive/<profile>
+(~/.local/share/dlt/archive/<profile>), or in --archive-dir. Archived logs are
+not redacted, so the archive cannot be used with --redact or production
+profiles.
+
+Examples:
+  dlt archive -q service:api --since 6h
+  dlt archive status
+  dlt search --local -q service:api -l error --since 2h
+  dlt archive clear`,
+	Args: cobra.NoArgs,
+	RunE: runArchive,
+}
+
+var archiveStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the size of the local archive and the time ranges it covers",
+	Args:  cobra.NoArgs,
+	RunE:  runArchiveStatus,
+}
+
+var archiveClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove the local archive",
+	Args:  cobra.NoArgs,
+	RunE:  runArchiveClear,
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&archiveLogs, "archive", false, "Persist fetched logs to the local archive (see dlt archive)")
+	rootCmd.PersistentFlags().StringVar(&archiveDir, "archive-dir", "", "Local archive directory (default $XDG_DATA_HOME/dlt/archive/<profile>)")
+	archiveCmd.AddCommand(archiveStatusCmd, archiveClearCmd)
+	rootCmd.AddCommand(archiveCmd)
+}
+
+func runArchive(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+	if cfg.GetTimestamp() == "" {
+		return fmt.Errorf("a time range is required: use --since or --timestamp")
+	}
+	if err := cfg.ValidateArchive(); err != nil {
+		return err
+	}
+
+	client, err := datadog.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create Datadog client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	a, err := openArchive(cfg)
+	if err != nil {
+		return err
+	}
+	client.SetArchive(a)
+	client.PreferArchive()
+
+	from, to, err := client.TimeRange()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	before := a.Stats().Records
+	logs, err := client.FetchLogs(ctx, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to fetch logs: %w", err)
+	}
+	fmt.Printf("Archived %d logs from %s to %s in %s (%d new)\n",
+		len(logs), from.Format(time.RFC3339), to.Format(time.RFC3339), a.Dir(), a.Stats().Records-before)
+	return nil
+}
+
+func runArchiveStatus(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	a, err := openArchive(cfg)
+	if err != nil {
+		return err
+	}
+	s := a.Stats()
+
+	if cfg.GetOutputFormat() == "json" {
+		return json.NewEncoder(os.Stdout).Encode(struct {
+			Dir string `json:"dir"`
+			archive.Stats
+		}{a.Dir(), s})
+	}
+
+	fmt.Printf("Archive:  %s\n", a.Dir())
+	fmt.Printf("Segments: %d (%s)\n", s.Segments, formatBytes(s.Bytes))
+	if s.Records == 0 {
+		fmt.Println("Logs:     0")
+	} else {
+		fmt.Printf("Logs:     %d (%s - %s)\n", s.Records,
+			s.Oldest.Local().Format(time.DateTime), s.Newest.Local().Format(time.DateTime))
+	}
+	if len(s.Coverage) == 0 {
+		fmt.Println("\nNo time ranges covered yet")
+		return nil
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "QUERY\tFROM\tTO")
+	for _, q := range slices.Sorted(maps.Keys(s.Coverage)) {
+		label := q
+		if label == "" {
+			label = "*"
+		}
+		for _, span := range s.Coverage[q] {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", label,
+				span.From.Local().Format(time.DateTime), span.To.Local().Format(time.DateTime))
+		}
+	}
+	return w.Flush()
+}
+
+func runArchiveClear(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	dir, err := archivePath(cfg)
+	if err != nil {
+		return err
+	}
+	found, err := archive.Remove(dir)
+	if err != nil {
+		return err
+	}
+	if !found {
+		fmt.Printf("No archive in %s\n", dir)
+		return nil
+	}
+	fmt.Printf("Removed archive %s\n", dir)
+	return nil
+}
+
+// setupArchive persists the logs fetched by the client to the local
+// archive if enabled, or if required by the command. It fails with
+// redaction, as archived logs are not redacted.
+func setupArchive(client *datadog.Client, cfg *config.Config, required bool) error {
+	if !cfg.GetArchive() && !required {
+		return nil
+	}
+	if err := cfg.ValidateArchive(); err != nil {
+		return err
+	}
+	a, err := openArchive(cfg)
+	if err != nil {
+		return err
+	}
+	client.SetArchive(a)
+	return nil
+}
+
+// openArchive opens the local archive of the configured profile
+func openArchive(cfg *config.Config) (*archive.Archive, error) {
+	dir, err := archivePath(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return archive.Open(dir)
+}
+
+// archivePath returns the archive directory, by default one per profile
+func archivePath(cfg *config.Config) (string, error) {
+	if dir := cfg.GetArchiveDir(); dir != "" {
+		return credentials.ExpandHome(dir), nil
+	}
+	dir := archive.DefaultDir()
+	if dir == "" {
+		return "", fmt.Errorf("cannot determine the archive directory: use --archive-dir")
+	}
+	name := cfg.GetProfile()
+	if name == "" {
+		name = "default"
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// formatBytes formats a size in bytes with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
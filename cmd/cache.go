@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/respcache"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	noCache      bool
+	cacheHorizon time.Duration
+	cacheMaxSize int
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the response cache",
+	Long: `Manage the on-disk cache of API responses.
+
+Search responses for time windows that ended more than --cache-horizon ago are
+cached in $XDG_CACHE_HOME/dlt/responses (~/.cache/dlt/responses), so repeating a
+batch query over the same past window is answered without API requests. The
+least recently used responses are removed once the cache grows beyond
+--cache-max-size. Disable the cache with --no-cache.
+
+Responses are stored as returned by the API, before redaction, so nothing is
+cached with --redact (the default for production profiles).
+
+Examples:
+  dlt cache clear`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached responses",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheClear,
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Do not cache API responses of past time windows")
+	rootCmd.PersistentFlags().DurationVar(&cacheHorizon, "cache-horizon", time.Hour, "How long ago a time window must have ended for its responses to be cached")
+	rootCmd.PersistentFlags().IntVar(&cacheMaxSize, "cache-max-size", 256, "Maximum response cache size in MiB")
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	dir := respcache.DefaultDir()
+	if dir == "" {
+		return fmt.Errorf("cannot determine the response cache directory")
+	}
+	n, size, err := respcache.Clear(dir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d cached responses (%s) from %s\n", n, formatBytes(size), dir)
+	return nil
+}
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+	"github.com/jedipunkz/datadog-log-tail/internal/datadog"
+	"github.com/jedipunkz/datadog-log-tail/internal/facetcache"
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+// completionFacets are the tags whose values are completed after -q
+var completionFacets = []string{"service", "env"}
+
+const (
+	// facetLookback is the time range of the facet lookup
+	facetLookback = time.Hour
+	// facetLimit is the maximum number of values looked up per facet
+	facetLimit = 100
+	// facetTimeout bounds the facet lookup so that completion stays responsive
+	facetTimeout = 3 * time.Second
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion <bash|zsh|fish>",
+	Short: "Generate the shell completion script",
+	Long: `Generate the completion script for bash, zsh or fish.
+
+Besides subcommands and flags, --level, --format, --profile, --saved-query and
+the service: and env: values of --query are completed. Tag values are looked up
+from the logs of the last hour and cached per profile and site in
+$XDG_CACHE_HOME/dlt/facets.json for an hour; when the lookup fails (e.g. offline)
+the cached values are used. Lookups never run credential commands or query the
+keyring: use DD_API_KEY/DD_APP_KEY or a keys file for live completion.
+
+Examples:
+  source <(dlt completion bash)                     # bash, current shell
+  dlt completion zsh > "${fpath[1]}/_dlt"           # zsh
+  dlt completion fish > ~/.config/fish/completions/dlt.fish  # fish`,
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			return rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return rootCmd.GenZshCompletion(os.Stdout)
+		default:
+			return rootCmd.GenFishCompletion(os.Stdout, true)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
+
+// registerCompletions registers the completion of flag values and
+// arguments. It is called from Execute because the flags are defined in the
+// init functions of several files.
+func registerCompletions() {
+	registerFlagCompletion(rootCmd, "level", completeList([]string{"debug", "info", "warn", "error"}))
+	registerFlagCompletion(rootCmd, "format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	registerFlagCompletion(rootCmd, "multiline", cobra.FixedCompletions(output.MultilineModes, cobra.ShellCompDirectiveNoFileComp))
+	registerFlagCompletion(rootCmd, "color", cobra.FixedCompletions([]string{"auto", "always", "never"}, cobra.ShellCompDirectiveNoFileComp))
+	registerFlagCompletion(rootCmd, "profile", completeProfiles)
+	registerFlagCompletion(rootCmd, "saved-query", completeQueries)
+	registerFlagCompletion(rootCmd, "query", completeTags)
+	registerFlagCompletion(getCmd, "output", cobra.FixedCompletions([]string{"yaml", "json"}, cobra.ShellCompDirectiveNoFileComp))
+
+	queryRunCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeQueries(cmd, args, toComplete)
+	}
+}
+
+// registerFlagCompletion registers a completion function for a flag. A
+// failure means the flag does not exist, which is a programming error.
+func registerFlagCompletion(cmd *cobra.Command, flag string, fn cobra.CompletionFunc) {
+	if err := cmd.RegisterFlagCompletionFunc(flag, fn); err != nil {
+		panic(err)
+	}
+}
+
+// completeList completes the last value of a comma-separated list, leaving
+// out values already in the list
+func completeList(values []string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		prefix, last := splitLast(toComplete)
+		given := strings.Split(prefix, ",")
+
+		var completions []string
+		for _, v := range values {
+			if strings.HasPrefix(v, last) && !slices.Contains(given, v) {
+				completions = append(completions, prefix+v)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeProfiles completes the profile names of the config file
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	file, err := loadConfigFile()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return file.ProfileNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeQueries completes the saved query names of the config file
+func completeQueries(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	file, err := loadConfigFile()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return file.QueryNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTags completes the last tag of a --query value: first the tag
+// names (service:, env:), then their values
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix, last := splitLast(toComplete)
+
+	facet, value, ok := strings.Cut(last, ":")
+	if !ok {
+		var completions []string
+		for _, f := range completionFacets {
+			if strings.HasPrefix(f, last) {
+				completions = append(completions, prefix+f+":")
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+	if !slices.Contains(completionFacets, facet) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []string
+	for _, v := range facetValues(cmd, facet) {
+		if strings.HasPrefix(v, value) {
+			completions = append(completions, prefix+facet+":"+v)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// facetValues returns the values of a facet from the facet cache, looking
+// them up if the cached values are missing or expired. When the lookup
+// fails or takes too long, stale cached values are returned.
+func facetValues(cmd *cobra.Command, facet string) []string {
+	cfg, err := loadConfig(cmd)
+	if err == nil {
+		err = cfg.ValidateSite()
+	}
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil
+	}
+
+	path := facetcache.DefaultPath()
+	cache, err := facetcache.Load(path)
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		cache = &facetcache.Cache{Entries: map[string]facetcache.Entry{}}
+	}
+	site := cfg.GetAPIURL()
+	if site == "" {
+		site = cfg.GetSite()
+	}
+	key := facetcache.Key(cfg.GetProfile(), site, facet)
+	now := time.Now()
+	cached, fresh := cache.Get(key, facetcache.DefaultTTL, now)
+	if fresh {
+		return cached
+	}
+
+	// The whole lookup, including credential resolution, is bounded
+	ctx, cancel := context.WithTimeout(context.Background(), facetTimeout)
+	defer cancel()
+	type result struct {
+		values []string
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		values, err := lookupFacet(ctx, cfg, facet, now)
+		done <- result{values, err}
+	}()
+
+	var r result
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		r.err = ctx.Err()
+	}
+	if r.err != nil {
+		cobra.CompDebugln(fmt.Sprintf("facet lookup failed, using cached values: %v", r.err), true)
+		return cached
+	}
+	cache.Put(key, r.values, now)
+	if err := facetcache.Save(path, cache); err != nil {
+		cobra.CompDebugln(err.Error(), true)
+	}
+	return r.values
+}
+
+// lookupFacet queries the most frequent values of a facet in the last hour.
+// Credential commands and keyrings are not used, as they may prompt.
+func lookupFacet(ctx context.Context, cfg *config.Config, facet string, now time.Time) ([]string, error) {
+	cfg.NonInteractive = true
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	client, err := datadog.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Close() }()
+
+	counts, err := client.FacetValues(ctx, facet, "", now.Add(-facetLookback), now, facetLimit)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(counts))
+	for _, fv := range counts {
+		values = append(values, fv.Value)
+	}
+	return values, nil
+}
+
+// loadConfigFile loads the config file selected by --config or DLT_CONFIG
+func loadConfigFile() (*config.File, error) {
+	path, optional := configFilePath()
+	return config.LoadFile(path, optional)
+}
+
+// splitLast splits a comma-separated list into everything up to and
+// including the last comma, and the last element
+func splitLast(s string) (string, string) {
+	i := strings.LastIndex(s, ",")
+	return s[:i+1], s[i+1:]
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+	"github.com/jedipunkz/datadog-log-tail/internal/logging"
+	"github.com/jedipunkz/datadog-log-tail/internal/respcache"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	configFile string
+	profile    string
+	apiKeyCmd  string
+	appKeyCmd  string
+
+	apiURL             string
+	proxy              string
+	caCert             string
+	clientCert         string
+	clientKey          string
+	insecureSkipVerify bool
+
+	logOptions logging.Options
+	closeLog   = func() error { return nil }
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file (default $XDG_CONFIG_HOME/dlt/config.yaml, env DLT_CONFIG)")
+	rootCmd.PersistentFlags().StringVarP(&profile, "profile", "p", "", "Config profile to use (env DLT_PROFILE)")
+	rootCmd.PersistentFlags().StringVar(&apiKeyCmd, "api-key-cmd", "", "Command that prints the API key (e.g. \"pass show dd/api\")")
+	rootCmd.PersistentFlags().StringVar(&appKeyCmd, "app-key-cmd", "", "Command that prints the application key")
+	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "", "API base URL overriding the site, e.g. a relay or http://localhost:8080 (env DD_API_URL)")
+	rootCmd.PersistentFlags().StringVar(&proxy, "proxy", "", "Proxy URL for API requests (default from HTTPS_PROXY/NO_PROXY)")
+	rootCmd.PersistentFlags().StringVar(&caCert, "ca-cert", "", "PEM file with additional CA certificates to trust")
+	rootCmd.PersistentFlags().StringVar(&clientCert, "client-cert", "", "PEM client certificate for mutual TLS")
+	rootCmd.PersistentFlags().StringVar(&clientKey, "client-key", "", "PEM client key for mutual TLS")
+	rootCmd.PersistentFlags().BoolVarP(&logOptions.Verbose, "verbose", "v", false, "Log API requests, latency, rate limits and pagination cursors")
+	rootCmd.PersistentFlags().BoolVar(&logOptions.Quiet, "quiet", false, "Only log errors")
+	rootCmd.PersistentFlags().BoolVar(&logOptions.DebugHTTP, "debug-http", false, "Also log request headers and response bodies (API keys are redacted)")
+	rootCmd.PersistentFlags().StringVar(&logOptions.File, "log-file", "", "Write diagnostic logs to this file instead of stderr")
+	rootCmd.PersistentFlags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "Disable TLS certificate verification (insecure)")
+}
+
+// setupLogging installs the diagnostic logger selected by the flags
+func setupLogging(cmd *cobra.Command, args []string) error {
+	closer, err := logging.Setup(logOptions)
+	if err != nil {
+		return err
+	}
+	closeLog = closer
+	return nil
+}
+
+// loadConfig builds the configuration from the config file, the selected
+// profile and the command line flags, in increasing order of precedence
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	cfg := config.New()
+
+	// Load config file; the default location is optional
+	path, optional := configFilePath()
+	file, err := config.LoadFile(path, optional)
+	if err != nil {
+		return nil, err
+	}
+
+	name := profile
+	if name == "" {
+		name = os.Getenv("DLT_PROFILE")
+	}
+	if err := cfg.Apply(file, name); err != nil {
+		return nil, fmt.Errorf("%w (available: %v)", err, file.ProfileNames())
+	}
+
+	// A saved query sets defaults that other flags can override
+	if savedQuery != "" {
+		if err := cfg.ApplyQuery(file, savedQuery); err != nil {
+			return nil, fmt.Errorf("%w (available: %v)", err, file.QueryNames())
+		}
+	}
+
+	// Flags that can also be set in the config file only override it when given
+	flags := cmd.Flags()
+	if flags.Changed("query") {
+		cfg.Tags = query
+	}
+	if flags.Changed("level") {
+		cfg.LogLevel = level
+	}
+	if flags.Changed("format") {
+		cfg.OutputFormat = format
+	}
+	if flags.Changed("multiline") {
+		cfg.Multiline = multiline
+	}
+	if flags.Changed("parse-json") {
+		cfg.ParseJSON = parseJSON
+	}
+	if flags.Changed("message-keys") {
+		cfg.MessageKeys = messageKeys
+	}
+	for _, spec := range fieldMap {
+		if err := cfg.FieldMap.Set(spec); err != nil {
+			return nil, err
+		}
+	}
+	if flags.Changed("redact") {
+		cfg.Redact = redactLogs
+	}
+	if flags.Changed("since") {
+		cfg.Since = since
+	} else if flags.Changed("timestamp") {
+		// An explicit time range replaces the since of a saved query
+		cfg.Since = 0
+	}
+	if flags.Changed("timeout") {
+		cfg.Timeout = timeout
+	}
+	if flags.Changed("retry-count") {
+		cfg.RetryCount = retryCount
+	}
+	if flags.Changed("api-url") {
+		cfg.APIURL = apiURL
+	}
+	if flags.Changed("proxy") {
+		cfg.Proxy = proxy
+	}
+	if flags.Changed("ca-cert") {
+		cfg.CACert = caCert
+	}
+	if flags.Changed("client-cert") {
+		cfg.ClientCert = clientCert
+	}
+	if flags.Changed("client-key") {
+		cfg.ClientKey = clientKey
+	}
+	if flags.Changed("insecure-skip-verify") {
+		cfg.InsecureSkipVerify = insecureSkipVerify
+	}
+
+	cfg.Color, err = colorEnabled(colorMode)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Timestamp = timestamp
+	cfg.AlertsFile = alertsFile
+	cfg.Sinks = sinks
+	cfg.StateFile = stateFile
+	cfg.MaxCatchup = maxCatchup
+	cfg.MaxLogsPerPoll = maxPerPoll
+	cfg.MetricsAddr = metricsAddr
+	if flags.Changed("archive") {
+		cfg.Archive = archiveLogs
+	}
+	if flags.Changed("archive-dir") {
+		cfg.ArchiveDir = archiveDir
+	}
+	cfg.CacheDir = respcache.DefaultDir()
+	if flags.Changed("no-cache") {
+		cfg.NoCache = noCache
+	}
+	if flags.Changed("cache-horizon") {
+		cfg.CacheHorizon = cacheHorizon
+	}
+	if flags.Changed("cache-max-size") {
+		cfg.CacheMaxSize = cacheMaxSize
+	}
+
+	// --context sets both directions; -A and -B override one of them
+	if flags.Changed("context") {
+		cfg.ContextBefore = contextLines
+		cfg.ContextAfter = contextLines
+	}
+	if flags.Changed("before-context") {
+		cfg.ContextBefore = beforeContext
+	}
+	if flags.Changed("after-context") {
+		cfg.ContextAfter = afterContext
+	}
+	cfg.APIKeyCmd = apiKeyCmd
+	cfg.AppKeyCmd = appKeyCmd
+
+	return cfg, nil
+}
+
+// configFilePath returns the configuration file path from --config,
+// DLT_CONFIG or the default location, and whether the file is optional
+func configFilePath() (string, bool) {
+	if configFile != "" {
+		return configFile, false
+	}
+	if path := os.Getenv("DLT_CONFIG"); path != "" {
+		return path, false
+	}
+	return config.DefaultFilePath(), true
+}
+
+// colorEnabled resolves the --color mode. auto colors output to a terminal
+// unless NO_COLOR is set.
+func colorEnabled(mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		return stdoutIsTerminal(), nil
+	default:
+		return false, fmt.Errorf("invalid color mode: %s (auto, always or never must be specified)", mode)
+	}
+}
+
+// stdoutIsTerminal reports whether stdout is a terminal
+func stdoutIsTerminal() bool {
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/datadog"
+	"github.com/jedipunkz/datadog-log-tail/internal/diff"
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+	"github.com/jedipunkz/datadog-log-tail/internal/patterns"
+	"github.com/jedipunkz/datadog-log-tail/internal/timerange"
+
+	"github.com/spf13/cobra"
+)
+
+// groupByPattern groups logs by message pattern in --by
+const groupByPattern = "pattern"
+
+var (
+	diffBefore    string
+	diffAfter     string
+	diffBy        []string
+	diffThreshold float64
+	diffMinCount  int
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the logs of two time windows",
+	Long: `Compare the logs of two time windows, e.g. before and after a deploy, and report
+the groups of logs that are new, have vanished, or whose rate changed by at least
+--threshold times.
+
+Logs are grouped by message pattern (see dlt patterns) by default, or by the
+fields given with --by: service, status, host, source, an @attribute path or a
+tag key; "pattern" can be combined with fields. Rates are compared per hour, so
+the windows can differ in length.
+
+Times are RFC3339 (seconds and zone optional), a time of day such as 10:00Z,
+which takes the date of the previous time (the first one is its most recent
+past occurrence), or now-<duration>.
+
+Examples:
+  dlt diff --before 2024-01-15T09:00Z,10:00Z --after 10:00Z,11:00Z -q service:api
+  dlt diff --before now-2h,now-1h --after now-1h,now -l error
+  dlt diff --before now-2h,now-1h --after now-1h,now --by service,status`,
+	Args: cobra.NoArgs,
+	RunE: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffBefore, "before", "", "Time range of the baseline window (from,to)")
+	diffCmd.Flags().StringVar(&diffAfter, "after", "", "Time range of the compared window (from,to)")
+	diffCmd.Flags().StringSliceVar(&diffBy, "by", []string{groupByPattern}, "Group logs by message pattern and/or fields (service, status, host, source, @attribute, tag key)")
+	diffCmd.Flags().Float64Var(&diffThreshold, "threshold", 2, "Rate ratio from which a group counts as increased (or decreased by its inverse)")
+	diffCmd.Flags().IntVar(&diffMinCount, "min-count", 5, "Count a group needs in one of the windows to be reported as increased or decreased")
+	_ = diffCmd.MarkFlagRequired("before")
+	_ = diffCmd.MarkFlagRequired("after")
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	if diffThreshold <= 1 {
+		return fmt.Errorf("invalid threshold: %v (must be greater than 1)", diffThreshold)
+	}
+	beforeFrom, beforeTo, err := timerange.Parse(diffBefore, time.Time{})
+	if err != nil {
+		return fmt.Errorf("invalid --before: %w", err)
+	}
+	afterFrom, afterTo, err := timerange.Parse(diffAfter, beforeTo)
+	if err != nil {
+		return fmt.Errorf("invalid --after: %w", err)
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+	client, err := datadog.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create Datadog client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+	if err := setupArchive(client, cfg, false); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	before, err := client.FetchLogs(ctx, beforeFrom, beforeTo)
+	if err != nil {
+		return fmt.Errorf("failed to fetch logs of the before window: %w", err)
+	}
+	after, err := client.FetchLogs(ctx, afterFrom, afterTo)
+	if err != nil {
+		return fmt.Errorf("failed to fetch logs of the after window: %w", err)
+	}
+
+	groups := groupLogs(before, after, diffBy)
+	changes := diff.Compare(groups, diff.Options{
+		Before:    beforeTo.Sub(beforeFrom),
+		After:     afterTo.Sub(afterFrom),
+		Threshold: diffThreshold,
+		MinCount:  diffMinCount,
+	})
+
+	if cfg.GetOutputFormat() == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		for _, c := range changes {
+			if err := enc.Encode(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	rows := make([]output.DiffRow, 0, len(changes))
+	for _, c := range changes {
+		rows = append(rows, output.DiffRow{
+			Change:    string(c.Kind),
+			Group:     c.Key,
+			Before:    c.Before,
+			After:     c.After,
+			Ratio:     c.Ratio,
+			ExampleID: c.ExampleID,
+		})
+	}
+	return output.WriteDiff(os.Stdout,
+		output.DiffWindow{From: beforeFrom, To: beforeTo, Logs: len(before)},
+		output.DiffWindow{From: afterFrom, To: afterTo, Logs: len(after)},
+		rows, len(groups)-len(changes))
+}
+
+// groupLogs counts the logs of both windows per group. Both windows are
+// clustered by the same miner so that their patterns match.
+func groupLogs(before, after []datadog.LogEntry, by []string) []diff.Group {
+	type sample struct {
+		log     datadog.LogEntry
+		after   bool
+		cluster *patterns.Cluster
+	}
+
+	miner := patterns.NewMiner(patterns.DefaultOptions())
+	samples := make([]sample, 0, len(before)+len(after))
+	for i, logs := range [][]datadog.LogEntry{before, after} {
+		for _, log := range logs {
+			s := sample{log: log, after: i == 1}
+			for _, field := range by {
+				if field == groupByPattern {
+					s.cluster = miner.Add(log.ID, log.Message, time.Unix(log.Timestamp, 0))
+					break
+				}
+			}
+			samples = append(samples, s)
+		}
+	}
+
+	// Keys are built once clustering is done, as templates change while
+	// messages are added
+	index := make(map[string]int)
+	var groups []diff.Group
+	for _, s := range samples {
+		parts := make([]string, 0, len(by))
+		for _, field := range by {
+			if field == groupByPattern {
+				parts = append(parts, s.cluster.Template)
+				continue
+			}
+			v := s.log.Field(field)
+			if v == "" {
+				v = "-"
+			}
+			parts = append(parts, field+"="+v)
+		}
+		key := strings.Join(parts, " ")
+
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, diff.Group{Key: key})
+		}
+		g := &groups[i]
+		if s.after {
+			// Prefer an example from the window the group changed in
+			if g.After == 0 {
+				g.ExampleID = s.log.ID
+			}
+			g.After++
+		} else {
+			if g.ExampleID == "" {
+				g.ExampleID = s.log.ID
+			}
+			g.Before++
+		}
+	}
+	return groups
+}
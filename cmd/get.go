@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/datadog"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	getOutput        string
+	getContextWindow time.Duration
+	getLookback      time.Duration
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get <log-id>",
+	Short: "Show a single log event with all of its attributes",
+	Long: `Retrieve a single log event by ID and print it with all attributes, including
+nested JSON, as YAML or JSON (-o/--output; the global --format does not apply).
+
+With --context (or -A/-B), the logs just before and after the event from the same
+host and service are included as well.
+
+Examples:
+  dlt get AQAAAYnY8U1uAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA
+  dlt get AQAAAYnY8U1uAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA -o json --context 5`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGet,
+}
+
+func init() {
+	getCmd.Flags().StringVarP(&getOutput, "output", "o", "yaml", "Output format (yaml, json)")
+	getCmd.Flags().DurationVar(&getContextWindow, "context-window", 5*time.Minute, "How far from the event context logs are searched")
+	getCmd.Flags().DurationVar(&getLookback, "lookback", 7*24*time.Hour, "How far back to search when the log ID does not encode a timestamp")
+	rootCmd.AddCommand(getCmd)
+}
+
+func runGet(cmd *cobra.Command, args []string) error {
+	// The global --format selects text or JSON lines, which a single event
+	// is not printed as
+	if cmd.Flags().Changed("format") {
+		return fmt.Errorf("--format is not supported by dlt get: use -o/--output (yaml or json)")
+	}
+	if getOutput != "yaml" && getOutput != "json" {
+		return fmt.Errorf("invalid output format: %s (yaml or json must be specified)", getOutput)
+	}
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	client, err := datadog.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create Datadog client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	detail, err := client.GetLog(ctx, args[0], datadog.GetOptions{
+		Lookback:      getLookback,
+		Before:        cfg.GetContextBefore(),
+		After:         cfg.GetContextAfter(),
+		ContextWindow: getContextWindow,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get log: %w", err)
+	}
+
+	var out []byte
+	if getOutput == "json" {
+		out, err = json.MarshalIndent(detail, "", "  ")
+		out = append(out, '\n')
+	} else {
+		out, err = yaml.Marshal(detail)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode log: %w", err)
+	}
+	_, err = os.Stdout.Write(out)
+	return err
+}
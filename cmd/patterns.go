@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/datadog"
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+	"github.com/jedipunkz/datadog-log-tail/internal/patterns"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	patternsLive     bool
+	patternsInterval time.Duration
+	patternsTop      int
+)
+
+var patternsCmd = &cobra.Command{
+	Use:   "patterns",
+	Short: "Group logs into message patterns",
+	Long: `Group log messages into patterns and print each pattern with its count, when it
+was first and last seen and an example log ID.
+
+Variable parts of the first line of each message (numbers, UUIDs, IP addresses,
+hex values and quoted strings) are masked, and similar messages are clustered
+with a Drain-like algorithm; the tokens in which they differ become <*>.
+
+A time range (--since or --timestamp) is searched in batch mode. With --live,
+logs are tailed instead and the summary is refreshed every --interval. With
+--format json, patterns are printed as JSON lines.
+
+Examples:
+  dlt patterns --since 1h -l error
+  dlt patterns -q "service:api" --timestamp "2024-01-15T10:00:00Z,2024-01-15T11:00:00Z"
+  dlt patterns --live -l error --interval 1m`,
+	Args: cobra.NoArgs,
+	RunE: runPatterns,
+}
+
+func init() {
+	patternsCmd.Flags().BoolVar(&patternsLive, "live", false, "Tail logs and refresh the pattern summary periodically")
+	patternsCmd.Flags().DurationVar(&patternsInterval, "interval", 30*time.Second, "How often the summary is refreshed with --live")
+	patternsCmd.Flags().IntVar(&patternsTop, "top", 20, "Number of most frequent patterns shown (0 = all)")
+	rootCmd.AddCommand(patternsCmd)
+}
+
+func runPatterns(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+	if patternsLive && cfg.GetTimestamp() != "" {
+		return fmt.Errorf("--live cannot be combined with --since or --timestamp")
+	}
+	if !patternsLive && cfg.GetTimestamp() == "" {
+		return fmt.Errorf("a time range is required: use --since or --timestamp, or --live to follow new logs")
+	}
+	if patternsInterval <= 0 {
+		return fmt.Errorf("invalid interval: %v (must be positive)", patternsInterval)
+	}
+
+	client, err := datadog.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create Datadog client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+	if err := setupArchive(client, cfg, false); err != nil {
+		return err
+	}
+
+	summary := &patternSummary{miner: patterns.NewMiner(patterns.DefaultOptions())}
+	if patternsLive {
+		return runLivePatterns(client, summary, cfg.GetOutputFormat())
+	}
+
+	from, to, err := client.TimeRange()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	logs, err := client.FetchLogs(ctx, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to fetch logs: %w", err)
+	}
+	for _, log := range logs {
+		if err := summary.Write(log); err != nil {
+			return err
+		}
+	}
+	return summary.print(cfg.GetOutputFormat(), false)
+}
+
+// runLivePatterns tails logs into the summary, printing it every interval
+// and once more when the tail is stopped
+func runLivePatterns(client *datadog.Client, summary *patternSummary, format string) error {
+	client.DisableStdout()
+	client.AddSink(summary)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(patternsInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := summary.print(format, stdoutIsTerminal()); err != nil {
+					fmt.Fprintf(os.Stderr, "failed to print patterns: %v\n", err)
+				}
+			}
+		}
+	}()
+
+	err := client.TailLogs()
+	close(done)
+	wg.Wait()
+	if err != nil {
+		return fmt.Errorf("failed to tail logs: %w", err)
+	}
+	return summary.print(format, false)
+}
+
+// patternSummary clusters the messages of the logs written to it. It is a
+// sink so that live mode can feed it from the tail.
+type patternSummary struct {
+	mu    sync.Mutex
+	miner *patterns.Miner
+	logs  int
+}
+
+func (s *patternSummary) Write(log output.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.miner.Add(log.GetID(), log.GetMessage(), time.Unix(log.GetTimestamp(), 0))
+	s.logs++
+	return nil
+}
+
+func (s *patternSummary) Close() error { return nil }
+
+// print writes the most frequent patterns to stdout, clearing the screen
+// first if requested
+func (s *patternSummary) print(format string, clear bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clusters := s.miner.Clusters()
+	shown := clusters
+	if patternsTop > 0 && len(shown) > patternsTop {
+		shown = shown[:patternsTop]
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		for _, c := range shown {
+			if err := enc.Encode(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if clear {
+		fmt.Print("\033[H\033[2J")
+	}
+	rows := make([]output.PatternRow, 0, len(shown))
+	for _, c := range shown {
+		rows = append(rows, output.PatternRow{
+			Template:  c.Template,
+			Count:     c.Count,
+			FirstSeen: c.FirstSeen,
+			LastSeen:  c.LastSeen,
+			ExampleID: c.ExampleID,
+		})
+	}
+	return output.WritePatterns(os.Stdout, rows, s.logs, len(clusters))
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Manage saved queries",
+	Long: `Save named combinations of --query, --level, --format and --since in the config
+file and run them with -Q. Other flags given with -Q override the saved values.
+
+Examples:
+  dlt query save payments-errors -q "service:payments" -l error --format json
+  dlt query list
+  dlt -Q payments-errors --since 2h
+  dlt query run payments-errors`,
+}
+
+var querySaveCmd = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save the given -q, -l, --format and --since flags as a named query",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runQuerySave,
+}
+
+var queryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved queries",
+	Args:  cobra.NoArgs,
+	RunE:  runQueryList,
+}
+
+var queryRunCmd = &cobra.Command{
+	Use:   "run <name>",
+	Short: "Run a saved query (same as dlt -Q <name>)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		savedQuery = args[0]
+		return runTail(cmd, nil)
+	},
+}
+
+func init() {
+	queryCmd.AddCommand(querySaveCmd, queryListCmd, queryRunCmd)
+	rootCmd.AddCommand(queryCmd)
+}
+
+func runQuerySave(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	var q config.Query
+	if flags.Changed("query") {
+		q.Query = query
+	}
+	if flags.Changed("level") {
+		q.Level = level
+	}
+	if flags.Changed("format") {
+		q.Format = format
+	}
+	if flags.Changed("since") {
+		q.Since = shortDuration(since)
+	}
+	if q == (config.Query{}) {
+		return fmt.Errorf("nothing to save: specify at least one of -q, -l, --format or --since")
+	}
+
+	path, _ := configFilePath()
+	if err := config.SaveQuery(path, args[0], q); err != nil {
+		return fmt.Errorf("failed to save query: %w", err)
+	}
+	fmt.Printf("Saved query %s to %s: %s\n", args[0], path, strings.Join(q.Args(), " "))
+	return nil
+}
+
+func runQueryList(cmd *cobra.Command, args []string) error {
+	path, optional := configFilePath()
+	file, err := config.LoadFile(path, optional)
+	if err != nil {
+		return err
+	}
+	if len(file.Queries) == 0 {
+		fmt.Println("No saved queries (see dlt query save)")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, name := range file.QueryNames() {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", name, strings.Join(file.Queries[name].Args(), " "))
+	}
+	return w.Flush()
+}
+
+// shortDuration formats a duration without trailing zero units (2h, not 2h0m0s)
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/datadog"
+
+	"github.com/spf13/cobra"
+)
+
+var replaySpeed string
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <file>...",
+	Short: "Replay exported logs through the output pipeline",
+	Long: `Replay logs exported with --format json, or saved responses of the Logs API v2,
+through the same filters, formatting, redaction, sinks and alerting rules as
+tailed logs, without contacting Datadog. Files can be gzip-compressed; "-" reads
+from stdin.
+
+By default logs are replayed as fast as possible. --speed keeps the original
+time between logs, sped up by the given factor, e.g. to demo alerting rules.
+
+Examples:
+  dlt -q service:api -f json > api.ndjson
+  dlt replay api.ndjson -l error
+  dlt replay api.ndjson.gz --speed 10x --alerts alerts.yaml
+  dlt replay api.ndjson -f json --sink file:replayed.ndjson`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runReplay,
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&replaySpeed, "speed", "max", "Replay speed relative to the original timing (e.g. 1x, 10x), or max")
+	rootCmd.AddCommand(replayCmd)
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	speed, err := parseSpeed(replaySpeed)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.ValidateOffline(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+	if cfg.GetTimestamp() != "" {
+		return fmt.Errorf("--timestamp and --since are not supported by replay")
+	}
+
+	client, err := datadog.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create Datadog client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if err := setupOutputs(client, cfg); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var total datadog.ReplayStats
+	for _, path := range args {
+		stats, err := replayFile(ctx, client, path, datadog.ReplayOptions{Speed: speed})
+		total.Read += stats.Read
+		total.Emitted += stats.Emitted
+		if err != nil {
+			return fmt.Errorf("failed to replay %s: %w", path, err)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	slog.Info("Replay finished", "read", total.Read, "replayed", total.Emitted)
+	return nil
+}
+
+// replayFile replays one file, decompressing it if it is gzip-compressed
+func replayFile(ctx context.Context, client *datadog.Client, path string, opts datadog.ReplayOptions) (datadog.ReplayStats, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return datadog.ReplayStats{}, err
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return datadog.ReplayStats{}, err
+		}
+		defer func() { _ = gz.Close() }()
+		return client.Replay(ctx, gz, opts)
+	}
+	return client.Replay(ctx, br, opts)
+}
+
+// parseSpeed parses a replay speed such as "10x", "0.5" or "max". Max
+// replays as fast as possible and is returned as 0.
+func parseSpeed(s string) (float64, error) {
+	if s == "" || s == "max" {
+		return 0, nil
+	}
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(s, "x"), 64)
+	if err != nil || speed <= 0 {
+		return 0, fmt.Errorf("invalid speed: %q (must be a positive factor such as 10x, or max)", s)
+	}
+	return speed, nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/datadog"
+
+	"github.com/spf13/cobra"
+)
+
+var searchLocal bool
+
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Search the logs of a time range",
+	Long: `Search the logs of a time range (--since or --timestamp) matching -q and -l,
+like dlt in batch mode.
+
+With --local, the parts of the range that the local archive covers for the query
+(see dlt archive) are answered from it, and only the rest is fetched from the API
+and added to the archive. Logs archived for a query without filters cover every
+query. The last 5 minutes are never covered, as logs may still arrive for them.
+
+Examples:
+  dlt search -q service:api --since 1h --archive
+  dlt search --local -q service:api -l error --since 1h
+  dlt search --local --timestamp "2024-01-15T10:00:00Z,2024-01-15T11:00:00Z"`,
+	Args: cobra.NoArgs,
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().BoolVar(&searchLocal, "local", false, "Answer from the local archive where it covers the time range")
+	rootCmd.AddCommand(searchCmd)
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+	if cfg.GetTimestamp() == "" {
+		return fmt.Errorf("a time range is required: use --since or --timestamp")
+	}
+
+	client, err := datadog.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create Datadog client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	if err := setupOutputs(client, cfg); err != nil {
+		return err
+	}
+	if err := setupArchive(client, cfg, searchLocal); err != nil {
+		return err
+	}
+	if searchLocal {
+		client.PreferArchive()
+	}
+
+	if err := client.GetLogsFromTimestamp(); err != nil {
+		return fmt.Errorf("failed to get logs from timestamp: %w", err)
+	}
+	return nil
+}
@@ -2,20 +2,42 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/jedipunkz/datadog-log-tail/internal/alert"
 	"github.com/jedipunkz/datadog-log-tail/internal/config"
 	"github.com/jedipunkz/datadog-log-tail/internal/datadog"
+	"github.com/jedipunkz/datadog-log-tail/internal/metrics"
+	"github.com/jedipunkz/datadog-log-tail/internal/sink"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	query      string
-	level      string
-	format     string
-	timestamp  string
-	timeout    int
-	retryCount int
+	query       string
+	level       string
+	format      string
+	multiline   string
+	colorMode   string
+	parseJSON   bool
+	messageKeys []string
+	fieldMap    []string
+	redactLogs  bool
+	savedQuery  string
+	since       time.Duration
+	timestamp   string
+	timeout     int
+	retryCount  int
+	alertsFile  string
+	sinks       []string
+	stateFile   string
+	maxCatchup  time.Duration
+	maxPerPoll  int
+	metricsAddr string
+
+	contextLines  int
+	afterContext  int
+	beforeContext int
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -24,42 +46,71 @@ var rootCmd = &cobra.Command{
 	Short: "Datadog Logs Tail - Real-time log tailing tool",
 	Long: `dlt is a command-line tool for tailing Datadog Logs in real-time.
 
-Authentication is configured via environment variables, and log filtering is available via tags.
+Authentication is configured via environment variables, a keys file, a credential command
+or per-profile credential references in the config file. Log filtering is available via tags.
 
 Examples:
   dlt                                    # Basic usage (real-time tailing)
   dlt --query "service:web,env:prod"     # Filter by tags
   dlt --level error --format json       # Filter by log level and output format
   dlt --level error,warn --query "env:prod" # Filter by multiple log levels and tags
-  dlt --timestamp "2024-01-15T10:00:00Z,2024-01-15T11:00:00Z" # Get logs from time range (batch mode)`,
-	RunE: runTail,
+  dlt --timestamp "2024-01-15T10:00:00Z,2024-01-15T11:00:00Z" # Get logs from time range (batch mode)
+  dlt --level error --since 2h           # Get the errors of the last 2 hours (batch mode)
+  dlt -Q payments-errors --since 2h      # Run a saved query (see dlt query save)
+  dlt --level error -B 5 -A 2            # Show logs before and after each error
+  dlt --multiline indent                 # Indent stack traces under each log
+  dlt --parse-json                       # Show the msg field of JSON messages
+  dlt --field-map status=@severity,status # Take the status from the severity attribute
+  dlt --redact                           # Mask sensitive data before sharing output
+  dlt --profile prod --api-key-cmd "pass show dd/api"  # Use a profile and a credential command
+  dlt --verbose --log-file /tmp/dlt.log  # Log API requests and rate limits to a file`,
+	PersistentPreRunE: setupLogging,
+	RunE:              runTail,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() error {
-	return rootCmd.Execute()
+	registerCompletions()
+	err := rootCmd.Execute()
+	if closeErr := closeLog(); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&query, "query", "q", "", "Tag filter (comma-separated)")
+	rootCmd.PersistentFlags().StringVarP(&savedQuery, "saved-query", "Q", "", "Run a saved query (see dlt query list); other flags override it")
 	rootCmd.PersistentFlags().StringVarP(&level, "level", "l", "", "Log level (debug, info, warn, error) - supports comma-separated values")
 	rootCmd.PersistentFlags().StringVarP(&format, "format", "f", "text", "Output format (json, text)")
+	rootCmd.PersistentFlags().StringVar(&multiline, "multiline", "raw", "How text output renders messages with newlines (raw, indent, escape, first-line)")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", "auto", "Color text output (auto, always, never); auto colors a terminal unless NO_COLOR is set")
+	rootCmd.PersistentFlags().BoolVar(&parseJSON, "parse-json", false, "Parse JSON messages into attributes and promote their message, level and severity fields")
+	rootCmd.PersistentFlags().StringSliceVar(&messageKeys, "message-keys", []string{"msg", "message", "error"}, "Keys of a parsed JSON message promoted to the message, in order of preference")
+	rootCmd.PersistentFlags().StringArrayVar(&fieldMap, "field-map", nil, "Source paths of a log field, in order (repeatable): message=@msg,message; fields: message, service, status, host, source, trace_id, span_id")
+	rootCmd.PersistentFlags().BoolVar(&redactLogs, "redact", false, "Redact emails, tokens, AWS keys and card numbers before output; disables the response cache and the local archive (default true for production profiles)")
 	rootCmd.PersistentFlags().StringVarP(&timestamp, "timestamp", "s", "", "Time range for log search in RFC3339 format (from,to): 2024-01-15T10:00:00Z,2024-01-15T11:00:00Z")
+	rootCmd.PersistentFlags().DurationVar(&since, "since", 0, "Get logs from this long ago until now (batch mode), e.g. 2h")
 	rootCmd.PersistentFlags().IntVar(&timeout, "timeout", 30, "Connection timeout in seconds")
 	rootCmd.PersistentFlags().IntVar(&retryCount, "retry-count", 3, "Number of retries for failed requests")
+	rootCmd.PersistentFlags().StringVar(&alertsFile, "alerts", "", "Path to alerting rules file (YAML) evaluated in tail mode")
+	rootCmd.PersistentFlags().StringVar(&stateFile, "state-file", "", "Persist the tail cursor to this file and resume from it on restart")
+	rootCmd.PersistentFlags().DurationVar(&maxCatchup, "max-catchup", time.Hour, "Maximum duration to catch up on when resuming from --state-file")
+	rootCmd.PersistentFlags().IntVar(&maxPerPoll, "max-logs-per-poll", 1000, "Maximum logs read per tail poll before the rest of the window is dropped (0 = unlimited)")
+	rootCmd.PersistentFlags().IntVarP(&contextLines, "context", "C", 0, "Show N logs from the same host and service before and after each match (in tail mode, after-context only covers logs already indexed)")
+	rootCmd.PersistentFlags().IntVarP(&afterContext, "after-context", "A", 0, "Show N logs from the same host and service after each match (in tail mode, only logs already indexed)")
+	rootCmd.PersistentFlags().IntVarP(&beforeContext, "before-context", "B", 0, "Show N logs from the same host and service before each match")
+	rootCmd.PersistentFlags().StringVar(&metricsAddr, "metrics-addr", "", "Expose Prometheus metrics on this address at /metrics (e.g. :9108)")
+	rootCmd.PersistentFlags().StringArrayVar(&sinks, "sink", nil, "Forward logs to a sink (repeatable): syslog://, syslog+tcp://, http(s)://, otlp://, unix://, file://")
 }
 
-
 func runTail(cmd *cobra.Command, args []string) error {
-	// Create configuration from command line flags
-	cfg := config.New()
-	cfg.Tags = query
-	cfg.LogLevel = level
-	cfg.OutputFormat = format
-	cfg.Timestamp = timestamp
-	cfg.Timeout = timeout
-	cfg.RetryCount = retryCount
+	// Create configuration from config file and command line flags
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
 
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
@@ -71,6 +122,23 @@ func runTail(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create Datadog client: %w", err)
 	}
+	defer func() { _ = client.Close() }()
+
+	if err := setupOutputs(client, cfg); err != nil {
+		return err
+	}
+	if err := setupArchive(client, cfg, false); err != nil {
+		return err
+	}
+
+	// Expose metrics for long-running tails
+	if cfg.GetMetricsAddr() != "" {
+		srv, err := metrics.Serve(cfg.GetMetricsAddr(), client.Metrics())
+		if err != nil {
+			return err
+		}
+		defer func() { _ = srv.Close() }()
+	}
 
 	// Start tailing logs or batch retrieval based on timestamp
 	if cfg.Timestamp != "" {
@@ -87,3 +155,25 @@ func runTail(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// setupOutputs adds the configured output sinks and alerting rules to the client
+func setupOutputs(client *datadog.Client, cfg *config.Config) error {
+	// Create output sinks
+	for _, spec := range cfg.GetSinks() {
+		s, err := sink.Parse(spec)
+		if err != nil {
+			return fmt.Errorf("failed to create sink: %w", err)
+		}
+		client.AddSink(s)
+	}
+
+	// Load alerting rules
+	if cfg.GetAlertsFile() != "" {
+		rules, err := alert.LoadRules(cfg.GetAlertsFile())
+		if err != nil {
+			return fmt.Errorf("failed to load alerting rules: %w", err)
+		}
+		client.SetAlertEngine(alert.NewEngine(rules))
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/datadog"
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+var traceLookback time.Duration
+
+var traceCmd = &cobra.Command{
+	Use:   "trace <trace-id>",
+	Short: "Show the logs of an APM trace as a timeline",
+	Long: `Search the logs carrying an APM trace ID (@dd.trace_id) across all services
+and print them in time order as a timeline, indented per service, with the time
+elapsed between steps.
+
+The search starts with the last 15 minutes and is widened until the trace is
+found, up to --lookback. Tag and level filters are ignored so that every
+service of the trace is shown. Multiline messages are rendered according to
+--multiline, with continuation lines aligned to the message column. With
+--format json, the logs are printed as JSON lines instead.
+
+Examples:
+  dlt trace 1234567890123456789
+  dlt trace 1234567890123456789 --lookback 24h -f json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTrace,
+}
+
+func init() {
+	traceCmd.Flags().DurationVar(&traceLookback, "lookback", 7*24*time.Hour, "How far back the search window is widened")
+	rootCmd.AddCommand(traceCmd)
+}
+
+func runTrace(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	client, err := datadog.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create Datadog client: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	trace, err := client.GetTrace(ctx, args[0], datadog.TraceOptions{MaxLookback: traceLookback})
+	if err != nil {
+		return fmt.Errorf("failed to get trace: %w", err)
+	}
+	if trace.Truncated {
+		slog.Warn("Trace has more logs than can be fetched, the timeline is incomplete", "trace_id", trace.ID, "logs", len(trace.Logs))
+	}
+
+	if cfg.GetOutputFormat() == "json" {
+		formatter := output.NewFormatter("json")
+		for _, l := range trace.Logs {
+			line, err := formatter.Format(l.LogEntry)
+			if err != nil {
+				return err
+			}
+			fmt.Println(line)
+		}
+		return nil
+	}
+
+	steps := make([]output.TimelineStep, 0, len(trace.Logs))
+	for _, l := range trace.Logs {
+		steps = append(steps, output.TimelineStep{Time: l.Time, Log: l.LogEntry})
+	}
+	return output.WriteTimeline(os.Stdout, trace.ID, steps, output.TextOptions{
+		Multiline: cfg.GetMultiline(),
+		Color:     cfg.GetColor(),
+	})
+}
@@ -0,0 +1,216 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+const (
+	// actionTimeout bounds how long a single exec or webhook action may run
+	actionTimeout = 30 * time.Second
+	// seenRetention is the minimum time log IDs are remembered for deduplication
+	seenRetention = 5 * time.Minute
+)
+
+// Payload is the JSON document passed to actions on stdin (exec) or as the
+// request body (webhook)
+type Payload struct {
+	Rule      string            `json:"rule"`
+	Match     string            `json:"match"`
+	Threshold string            `json:"threshold,omitempty"`
+	Count     int               `json:"count"`
+	FiredAt   time.Time         `json:"fired_at"`
+	Entries   []output.LogEntry `json:"entries"`
+}
+
+// Engine evaluates alerting rules against a stream of log entries
+type Engine struct {
+	rules      []*Rule
+	state      map[string]*ruleState
+	httpClient *http.Client
+	now        func() time.Time
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+}
+
+// ruleState tracks matched entries for a single rule
+type ruleState struct {
+	matched   []output.LogEntry
+	seen      map[string]int64
+	lastFired time.Time
+}
+
+// NewEngine creates a new alerting engine for the given rules
+func NewEngine(rules []*Rule) *Engine {
+	state := make(map[string]*ruleState, len(rules))
+	for _, r := range rules {
+		state[r.Name] = &ruleState{seen: make(map[string]int64)}
+	}
+	return &Engine{
+		rules:      rules,
+		state:      state,
+		httpClient: &http.Client{Timeout: actionTimeout},
+		now:        time.Now,
+	}
+}
+
+// Evaluate checks a log entry against every rule and fires the actions of
+// rules whose threshold is reached. Actions run asynchronously.
+func (e *Engine) Evaluate(log output.LogEntry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, rule := range e.rules {
+		if !rule.expr.Match(log) {
+			continue
+		}
+		st := e.state[rule.Name]
+
+		// Deduplicate entries that were already counted for this rule
+		if id := log.GetID(); id != "" {
+			if _, ok := st.seen[id]; ok {
+				continue
+			}
+			st.seen[id] = log.GetTimestamp()
+		}
+
+		st.matched = append(st.matched, log)
+		st.prune(rule.window, log.GetTimestamp())
+
+		if len(st.matched) < rule.count {
+			continue
+		}
+
+		now := e.now()
+		if !st.lastFired.IsZero() && now.Sub(st.lastFired) < rule.cooldown {
+			continue
+		}
+		st.lastFired = now
+
+		payload := Payload{
+			Rule:      rule.Name,
+			Match:     rule.Match,
+			Threshold: rule.Threshold,
+			Count:     len(st.matched),
+			FiredAt:   now,
+			Entries:   st.matched,
+		}
+		st.reset()
+		e.fire(rule, payload)
+	}
+}
+
+// Wait blocks until all running actions have completed
+func (e *Engine) Wait() {
+	e.wg.Wait()
+}
+
+// prune drops matched entries that fall outside the threshold window,
+// measured back from the newest entry's timestamp
+func (s *ruleState) prune(window time.Duration, newest int64) {
+	if window <= 0 {
+		// Without a window only the newest entry is relevant
+		s.matched = s.matched[len(s.matched)-1:]
+	} else {
+		cutoff := newest - int64(window/time.Second)
+		kept := s.matched[:0]
+		for _, m := range s.matched {
+			if m.GetTimestamp() > cutoff {
+				kept = append(kept, m)
+			}
+		}
+		s.matched = kept
+	}
+
+	retention := max(window, seenRetention)
+	seenCutoff := newest - int64(retention/time.Second)
+	for id, ts := range s.seen {
+		if ts <= seenCutoff {
+			delete(s.seen, id)
+		}
+	}
+}
+
+// reset clears the matched entries after a rule fired. Seen IDs are kept
+// so that re-delivered entries don't count towards the next alert.
+func (s *ruleState) reset() {
+	s.matched = nil
+}
+
+// fire runs all actions of a rule in the background
+func (e *Engine) fire(rule *Rule, payload Payload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("Failed to encode alert payload", "rule", rule.Name, "error", err)
+		return
+	}
+
+	slog.Warn("Alert fired", "rule", rule.Name, "count", payload.Count)
+
+	for _, action := range rule.Actions {
+		e.wg.Add(1)
+		go func(a Action) {
+			defer e.wg.Done()
+			var err error
+			if a.Exec != "" {
+				err = e.runExec(a.Exec, payload, body)
+			} else {
+				err = e.postWebhook(a.Webhook, body)
+			}
+			if err != nil {
+				slog.Error("Alert action failed", "rule", rule.Name, "error", err)
+			}
+		}(action)
+	}
+}
+
+// runExec runs a shell command with the payload on stdin
+func (e *Engine) runExec(command string, payload Payload, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"DLT_ALERT_RULE="+payload.Rule,
+		"DLT_ALERT_COUNT="+strconv.Itoa(payload.Count),
+	)
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("exec %q: %w", command, err)
+	}
+	return nil
+}
+
+// postWebhook posts the payload as JSON to the given URL
+func (e *Engine) postWebhook(url string, body []byte) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook %s: failed to create request: %w", url, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s: unexpected status %s", url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package alert
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/filter"
+)
+
+// Mock LogEntry for testing
+type mockLogEntry struct {
+	ID        string `json:"id"`
+	Timestamp int64  `json:"timestamp"`
+	Message   string `json:"message"`
+	Service   string `json:"service"`
+	Status    string `json:"status"`
+}
+
+func (m *mockLogEntry) GetID() string                         { return m.ID }
+func (m *mockLogEntry) GetTimestamp() int64                   { return m.Timestamp }
+func (m *mockLogEntry) GetMessage() string                    { return m.Message }
+func (m *mockLogEntry) GetService() string                    { return m.Service }
+func (m *mockLogEntry) GetStatus() string                     { return m.Status }
+func (m *mockLogEntry) GetTags() []string                     { return nil }
+func (m *mockLogEntry) GetAttributes() map[string]interface{} { return nil }
+func (m *mockLogEntry) GetHost() string                       { return "" }
+func (m *mockLogEntry) GetSource() string                     { return "" }
+func (m *mockLogEntry) GetTraceID() string                    { return "" }
+func (m *mockLogEntry) GetSpanID() string                     { return "" }
+
+func newTestRule(t *testing.T, match, threshold, cooldown string, actions ...Action) *Rule {
+	t.Helper()
+	rule := &Rule{Name: "test", Match: match, Threshold: threshold, Cooldown: cooldown, Actions: actions}
+	if err := rule.compile(); err != nil {
+		t.Fatalf("compile() error = %v", err)
+	}
+	return rule
+}
+
+// webhookRecorder collects payloads posted to a test server
+type webhookRecorder struct {
+	mu       sync.Mutex
+	payloads []Payload
+}
+
+func (w *webhookRecorder) handler(rw http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var p Payload
+	_ = json.Unmarshal(body, &p)
+	w.mu.Lock()
+	w.payloads = append(w.payloads, p)
+	w.mu.Unlock()
+	rw.WriteHeader(http.StatusOK)
+}
+
+func (w *webhookRecorder) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.payloads)
+}
+
+func TestEngine_ThresholdAndCooldown(t *testing.T) {
+	rec := &webhookRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(rec.handler))
+	defer server.Close()
+
+	rule := newTestRule(t, "status:error AND service:payments", "3 in 1m", "10m", Action{Webhook: server.URL})
+	engine := NewEngine([]*Rule{rule})
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	engine.now = func() time.Time { return now }
+
+	base := now.Unix()
+	logs := []*mockLogEntry{
+		{ID: "1", Timestamp: base, Service: "payments", Status: "error"},
+		{ID: "2", Timestamp: base + 1, Service: "web", Status: "error"},      // no match
+		{ID: "1", Timestamp: base, Service: "payments", Status: "error"},     // duplicate
+		{ID: "3", Timestamp: base + 2, Service: "payments", Status: "error"}, // 2 matches
+		{ID: "4", Timestamp: base + 3, Service: "payments", Status: "error"}, // 3 matches: fire
+		{ID: "5", Timestamp: base + 4, Service: "payments", Status: "error"},
+		{ID: "6", Timestamp: base + 5, Service: "payments", Status: "error"},
+		{ID: "7", Timestamp: base + 6, Service: "payments", Status: "error"}, // in cooldown
+	}
+	for _, l := range logs {
+		engine.Evaluate(l)
+	}
+	engine.Wait()
+
+	if rec.count() != 1 {
+		t.Fatalf("webhook called %d times, want 1", rec.count())
+	}
+	p := rec.payloads[0]
+	if p.Rule != "test" || p.Count != 3 {
+		t.Errorf("payload = {Rule: %s, Count: %d}, want {Rule: test, Count: 3}", p.Rule, p.Count)
+	}
+	if len(p.Entries) != 3 {
+		t.Errorf("payload has %d entries, want 3", len(p.Entries))
+	}
+
+	// After the cooldown the rule can fire again
+	now = now.Add(11 * time.Minute)
+	engine.Evaluate(&mockLogEntry{ID: "8", Timestamp: base + 7, Service: "payments", Status: "error"})
+	engine.Wait()
+
+	if rec.count() != 2 {
+		t.Errorf("webhook called %d times after cooldown, want 2", rec.count())
+	}
+}
+
+func TestEngine_WindowExpiry(t *testing.T) {
+	rec := &webhookRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(rec.handler))
+	defer server.Close()
+
+	rule := newTestRule(t, "status:error", "2 in 10s", "", Action{Webhook: server.URL})
+	engine := NewEngine([]*Rule{rule})
+
+	engine.Evaluate(&mockLogEntry{ID: "1", Timestamp: 1000, Status: "error"})
+	engine.Evaluate(&mockLogEntry{ID: "2", Timestamp: 1030, Status: "error"})
+	engine.Wait()
+
+	if rec.count() != 0 {
+		t.Errorf("webhook called %d times, want 0 (matches outside window)", rec.count())
+	}
+}
+
+func TestEngine_ExecReceivesPayloadOnStdin(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "payload.json")
+	rule := newTestRule(t, "panic", "", "", Action{Exec: "cat > " + out})
+	engine := NewEngine([]*Rule{rule})
+
+	engine.Evaluate(&mockLogEntry{ID: "1", Timestamp: 1000, Message: "panic: nil map"})
+	engine.Wait()
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("failed to read exec output: %v", err)
+	}
+	var p struct {
+		Rule    string         `json:"rule"`
+		Entries []mockLogEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		t.Fatalf("exec stdin is not valid JSON: %v", err)
+	}
+	if p.Rule != "test" || len(p.Entries) != 1 || p.Entries[0].Message != "panic: nil map" {
+		t.Errorf("unexpected payload: %s", data)
+	}
+}
+
+func TestEngine_NoMatch(t *testing.T) {
+	expr, _ := filter.Parse("status:error")
+	rule := &Rule{Name: "r", expr: expr, count: 1}
+	engine := NewEngine([]*Rule{rule})
+
+	engine.Evaluate(&mockLogEntry{ID: "1", Status: "info"})
+
+	if len(engine.state["r"].matched) != 0 {
+		t.Errorf("matched = %d, want 0", len(engine.state["r"].matched))
+	}
+}
@@ -0,0 +1,144 @@
+package alert
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/filter"
+)
+
+// RulesFile represents the contents of an alerts.yaml file
+type RulesFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule represents a single alerting rule
+//
+// Example:
+//
+//	rules:
+//	  - name: payments-errors
+//	    match: status:error AND @service:payments
+//	    threshold: 10 in 1m
+//	    cooldown: 5m
+//	    actions:
+//	      - exec: ./page.sh
+//	      - webhook: http://localhost:9000
+type Rule struct {
+	Name      string   `yaml:"name"`
+	Match     string   `yaml:"match"`
+	Threshold string   `yaml:"threshold"`
+	Cooldown  string   `yaml:"cooldown"`
+	Actions   []Action `yaml:"actions"`
+
+	expr     *filter.Expr
+	count    int
+	window   time.Duration
+	cooldown time.Duration
+}
+
+// Action represents what to do when a rule fires. Exactly one of the
+// fields must be set.
+type Action struct {
+	Exec    string `yaml:"exec"`
+	Webhook string `yaml:"webhook"`
+}
+
+// LoadRules loads and validates alerting rules from a YAML file
+func LoadRules(path string) ([]*Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var file RulesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file %s: %w", path, err)
+	}
+	if len(file.Rules) == 0 {
+		return nil, fmt.Errorf("no rules defined in %s", path)
+	}
+
+	rules := make([]*Rule, 0, len(file.Rules))
+	names := make(map[string]bool)
+	for i := range file.Rules {
+		rule := file.Rules[i]
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("rule %d (%s): %w", i+1, rule.Name, err)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("duplicate rule name: %s", rule.Name)
+		}
+		names[rule.Name] = true
+		rules = append(rules, &rule)
+	}
+	return rules, nil
+}
+
+// compile validates the rule and parses its match expression, threshold and cooldown
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	expr, err := filter.Parse(r.Match)
+	if err != nil {
+		return fmt.Errorf("invalid match expression: %w", err)
+	}
+	r.expr = expr
+
+	r.count, r.window, err = ParseThreshold(r.Threshold)
+	if err != nil {
+		return err
+	}
+
+	// Default cooldown is the threshold window so that a sustained burst
+	// fires at most once per window
+	r.cooldown = r.window
+	if r.Cooldown != "" {
+		r.cooldown, err = time.ParseDuration(r.Cooldown)
+		if err != nil {
+			return fmt.Errorf("invalid cooldown %q: %w", r.Cooldown, err)
+		}
+	}
+
+	if len(r.Actions) == 0 {
+		return fmt.Errorf("at least one action is required")
+	}
+	for _, a := range r.Actions {
+		if (a.Exec == "") == (a.Webhook == "") {
+			return fmt.Errorf("each action must set exactly one of exec or webhook")
+		}
+	}
+	return nil
+}
+
+// ParseThreshold parses a threshold such as "10 in 1m". An empty threshold
+// fires on every matching entry.
+func ParseThreshold(s string) (int, time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 1, 0, nil
+	}
+
+	countStr, windowStr, found := strings.Cut(s, " in ")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid threshold %q (use: <count> in <duration>, e.g. 10 in 1m)", s)
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(countStr))
+	if err != nil || count < 1 {
+		return 0, 0, fmt.Errorf("invalid threshold count %q (must be a positive integer)", countStr)
+	}
+
+	window, err := time.ParseDuration(strings.TrimSpace(windowStr))
+	if err != nil || window <= 0 {
+		return 0, 0, fmt.Errorf("invalid threshold window %q (must be a positive duration)", windowStr)
+	}
+	return count, window, nil
+}
@@ -0,0 +1,170 @@
+package alert
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantCount  int
+		wantWindow time.Duration
+		wantErr    bool
+	}{
+		{"Empty threshold", "", 1, 0, false},
+		{"Count in minutes", "10 in 1m", 10, time.Minute, false},
+		{"Extra spaces", "  5 in 30s ", 5, 30 * time.Second, false},
+		{"Missing window", "10", 0, 0, true},
+		{"Invalid count", "ten in 1m", 0, 0, true},
+		{"Zero count", "0 in 1m", 0, 0, true},
+		{"Invalid window", "10 in soon", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, window, err := ParseThreshold(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseThreshold(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if count != tt.wantCount || window != tt.wantWindow {
+				t.Errorf("ParseThreshold(%q) = (%d, %v), want (%d, %v)", tt.input, count, window, tt.wantCount, tt.wantWindow)
+			}
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		wantErr       bool
+		errorContains string
+		wantRules     int
+	}{
+		{
+			name: "Valid rules",
+			content: `
+rules:
+  - name: payments-errors
+    match: status:error AND @service:payments
+    threshold: 10 in 1m
+    cooldown: 5m
+    actions:
+      - exec: ./page.sh
+      - webhook: http://localhost:9000
+  - name: any-panic
+    match: panic
+    actions:
+      - exec: cat
+`,
+			wantRules: 2,
+		},
+		{
+			name:          "No rules",
+			content:       "rules: []\n",
+			wantErr:       true,
+			errorContains: "no rules defined",
+		},
+		{
+			name: "Missing name",
+			content: `
+rules:
+  - match: status:error
+    actions:
+      - exec: cat
+`,
+			wantErr:       true,
+			errorContains: "name is required",
+		},
+		{
+			name: "Invalid match",
+			content: `
+rules:
+  - name: broken
+    match: (status:error
+    actions:
+      - exec: cat
+`,
+			wantErr:       true,
+			errorContains: "invalid match expression",
+		},
+		{
+			name: "Action with both exec and webhook",
+			content: `
+rules:
+  - name: both
+    match: status:error
+    actions:
+      - exec: cat
+        webhook: http://localhost:9000
+`,
+			wantErr:       true,
+			errorContains: "exactly one of exec or webhook",
+		},
+		{
+			name: "Duplicate names",
+			content: `
+rules:
+  - name: dup
+    actions: [{exec: cat}]
+  - name: dup
+    actions: [{exec: cat}]
+`,
+			wantErr:       true,
+			errorContains: "duplicate rule name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "alerts.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("failed to write rules file: %v", err)
+			}
+
+			rules, err := LoadRules(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadRules() expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("LoadRules() error = %v, want to contain %v", err, tt.errorContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadRules() error = %v", err)
+			}
+			if len(rules) != tt.wantRules {
+				t.Errorf("LoadRules() returned %d rules, want %d", len(rules), tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestLoadRules_CooldownDefaultsToWindow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.yaml")
+	content := `
+rules:
+  - name: r
+    match: status:error
+    threshold: 3 in 2m
+    actions: [{exec: cat}]
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write rules file: %v", err)
+	}
+
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	if rules[0].cooldown != 2*time.Minute {
+		t.Errorf("cooldown = %v, want 2m", rules[0].cooldown)
+	}
+}
@@ -0,0 +1,598 @@
+// Package archive stores fetched logs on disk so that searches over time
+// ranges that were already fetched can be answered without the API.
+//
+// An archive is a directory of append-only segment files, each holding one
+// JSON document per line. Next to each segment, an append-only index file
+// holds one entry per record with its ID, offset, timestamp and terms (e.g.
+// service:api, env:prod), from which the time index and the inverted index
+// are built when the archive is opened. index.json holds the coverage: the
+// time spans fully fetched per query.
+//
+// Index entries are appended after their records were written, and entries
+// torn or pointing past the end of their segment are dropped when the
+// archive is opened, so a crash leaves unreferenced lines but no broken
+// references. An archive is meant to be written by one process at a time.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// currentVersion is the archive format version
+const currentVersion = 1
+
+// DefaultSegmentSize is the number of records after which a new segment
+// is started
+const DefaultSegmentSize = 10000
+
+const (
+	indexFile   = "index.json"
+	segmentsDir = "segments"
+	segmentExt  = ".ndjson"
+	entriesExt  = ".idx"
+)
+
+// Record is a log to archive
+type Record struct {
+	ID        string
+	Timestamp time.Time
+	// Terms are the index terms of the log, e.g. service:api or env:prod
+	Terms []string
+	// Data is the log as a JSON document
+	Data json.RawMessage
+}
+
+// Span is a time span [From, To)
+type Span struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Stats describes the contents of an archive
+type Stats struct {
+	Segments int               `json:"segments"`
+	Bytes    int64             `json:"bytes"`
+	Records  int               `json:"records"`
+	Oldest   time.Time         `json:"oldest"`
+	Newest   time.Time         `json:"newest"`
+	Coverage map[string][]Span `json:"coverage"`
+}
+
+// ref locates a record in the segments
+type ref struct {
+	ID  string
+	Seg int
+	Off int64
+	Len int
+	TS  int64 // Unix nanoseconds
+}
+
+// entry is a line of a segment's index file
+type entry struct {
+	ID    string   `json:"id"`
+	Off   int64    `json:"off"`
+	Len   int      `json:"len"`
+	TS    int64    `json:"ts"` // Unix nanoseconds
+	Terms []string `json:"terms,omitempty"`
+}
+
+// segment describes a segment file
+type segment struct {
+	name  string
+	count int
+	size  int64
+}
+
+// index is the contents of the index file
+type index struct {
+	Version  int               `json:"version"`
+	Coverage map[string][]Span `json:"coverage"`
+}
+
+// Archive is a local log archive
+type Archive struct {
+	dir         string
+	segmentSize int
+	idx         *index
+	segments    []segment
+	records     []ref
+	terms       map[string][]int
+	ids         map[string]bool
+}
+
+// DefaultDir returns the default location of archives,
+// $XDG_DATA_HOME/dlt/archive or ~/.local/share/dlt/archive
+func DefaultDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "dlt", "archive")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "dlt", "archive")
+}
+
+// Open opens the archive in dir, creating the directory if needed
+func Open(dir string) (*Archive, error) {
+	if err := os.MkdirAll(filepath.Join(dir, segmentsDir), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	a := &Archive{
+		dir:         dir,
+		segmentSize: DefaultSegmentSize,
+		idx:         &index{Version: currentVersion, Coverage: map[string][]Span{}},
+		terms:       map[string][]int{},
+		ids:         map[string]bool{},
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read archive index: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, a.idx); err != nil {
+			return nil, fmt.Errorf("failed to parse archive index in %s: %w", dir, err)
+		}
+		if a.idx.Version != currentVersion {
+			return nil, fmt.Errorf("archive %s has unsupported version %d (remove it with dlt archive clear)", dir, a.idx.Version)
+		}
+		if a.idx.Coverage == nil {
+			a.idx.Coverage = map[string][]Span{}
+		}
+	}
+
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// load reads the segments and their index entries
+func (a *Archive) load() error {
+	paths, err := filepath.Glob(filepath.Join(a.dir, segmentsDir, "*"+segmentExt))
+	if err != nil {
+		return fmt.Errorf("failed to list archive segments: %w", err)
+	}
+	sort.Strings(paths)
+
+	for n, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to read archive segment: %w", err)
+		}
+		a.segments = append(a.segments, segment{name: filepath.Base(path), size: info.Size()})
+		entries, err := readEntries(a.entriesPath(n), info.Size())
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if a.ids[e.ID] {
+				continue
+			}
+			a.addRef(ref{ID: e.ID, Seg: n, Off: e.Off, Len: e.Len, TS: e.TS}, e.Terms)
+			a.segments[n].count++
+		}
+	}
+	return nil
+}
+
+// readEntries reads the index entries of a segment of the given size. A
+// torn last entry, and entries of records missing from the segment, are
+// left by a crash; they are removed so that appending continues after the
+// last valid entry.
+func readEntries(path string, size int64) ([]entry, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive index: %w", err)
+	}
+
+	var entries []entry
+	valid := 0
+	for valid < len(data) {
+		end := bytes.IndexByte(data[valid:], '\n')
+		if end < 0 {
+			break
+		}
+		var e entry
+		if err := json.Unmarshal(data[valid:valid+end], &e); err != nil || e.Off+int64(e.Len) > size {
+			break
+		}
+		entries = append(entries, e)
+		valid += end + 1
+	}
+	if valid < len(data) {
+		if err := os.Truncate(path, int64(valid)); err != nil {
+			return nil, fmt.Errorf("failed to repair archive index: %w", err)
+		}
+	}
+	return entries, nil
+}
+
+// Remove deletes the archive in dir. Only the files of the archive are
+// removed, and the directory itself if that leaves it empty. It reports
+// whether there was an archive.
+func Remove(dir string) (bool, error) {
+	found := false
+	for _, name := range []string{indexFile, segmentsDir} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		found = true
+		if err := os.RemoveAll(path); err != nil {
+			return found, fmt.Errorf("failed to remove archive: %w", err)
+		}
+	}
+	// Leftovers of interrupted index writes
+	tmps, _ := filepath.Glob(filepath.Join(dir, indexFile+".tmp-*"))
+	for _, tmp := range tmps {
+		_ = os.Remove(tmp)
+	}
+	_ = os.Remove(dir)
+	return found, nil
+}
+
+// Dir returns the archive directory
+func (a *Archive) Dir() string {
+	return a.dir
+}
+
+// Add appends the records that are not archived yet, by ID, and returns
+// how many were added. Only the segments and their index entries are
+// appended to; index.json is not rewritten.
+func (a *Archive) Add(records []Record) (int, error) {
+	var w *segmentWriter
+	added := 0
+	for _, rec := range records {
+		if a.ids[rec.ID] {
+			continue
+		}
+		if w == nil || w.seg.count >= a.segmentSize {
+			if err := w.close(); err != nil {
+				return added, err
+			}
+			var err error
+			if w, err = a.openSegment(); err != nil {
+				return added, err
+			}
+		}
+
+		line := append(compact(rec.Data), '\n')
+		terms := uniqueTerms(rec.Terms)
+		e := entry{ID: rec.ID, Len: len(line) - 1, TS: rec.Timestamp.UnixNano(), Terms: terms}
+		off, err := w.write(line, e)
+		if err != nil {
+			_ = w.close()
+			return added, err
+		}
+
+		a.addRef(ref{ID: rec.ID, Seg: w.num, Off: off, Len: e.Len, TS: e.TS}, terms)
+		added++
+	}
+	return added, w.close()
+}
+
+// addRef adds a record to the in-memory indexes
+func (a *Archive) addRef(r ref, terms []string) {
+	n := len(a.records)
+	a.records = append(a.records, r)
+	for _, term := range terms {
+		a.terms[term] = append(a.terms[term], n)
+	}
+	a.ids[r.ID] = true
+}
+
+// Cover records that all logs matching query between from and to have
+// been added
+func (a *Archive) Cover(query string, from, to time.Time) error {
+	if !to.After(from) {
+		return nil
+	}
+	a.idx.Coverage[query] = mergeSpans(append(a.idx.Coverage[query], Span{From: from.UTC(), To: to.UTC()}))
+	return a.save()
+}
+
+// Gaps returns the parts of [from, to) that are not covered for query.
+// Spans covered for the empty query, i.e. all logs, count for every query.
+func (a *Archive) Gaps(query string, from, to time.Time) []Span {
+	spans := append([]Span(nil), a.idx.Coverage[query]...)
+	if query != "" {
+		spans = append(spans, a.idx.Coverage[""]...)
+	}
+	spans = mergeSpans(spans)
+
+	var gaps []Span
+	cur := from
+	for _, s := range spans {
+		if !s.To.After(cur) {
+			continue
+		}
+		if !s.From.Before(to) {
+			break
+		}
+		if s.From.After(cur) {
+			gaps = append(gaps, Span{From: cur, To: s.From})
+		}
+		cur = s.To
+	}
+	if cur.Before(to) {
+		gaps = append(gaps, Span{From: cur, To: to})
+	}
+	return gaps
+}
+
+// Search returns the archived logs between from and to in time order. Each
+// group of terms narrows the results down to the records that have at
+// least one of its terms; without groups all records in the range are
+// returned.
+func (a *Archive) Search(groups [][]string, from, to time.Time) ([]json.RawMessage, error) {
+	candidates := a.candidates(groups)
+
+	lo, hi := from.UnixNano(), to.UnixNano()
+	var matches []int
+	for _, n := range candidates {
+		if ts := a.records[n].TS; ts >= lo && ts < hi {
+			matches = append(matches, n)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return a.records[matches[i]].TS < a.records[matches[j]].TS
+	})
+
+	files := map[int]*os.File{}
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+
+	docs := make([]json.RawMessage, 0, len(matches))
+	for _, n := range matches {
+		r := a.records[n]
+		f, ok := files[r.Seg]
+		if !ok {
+			var err error
+			if f, err = os.Open(a.segmentPath(r.Seg)); err != nil {
+				return nil, fmt.Errorf("failed to open archive segment: %w", err)
+			}
+			files[r.Seg] = f
+		}
+		buf := make([]byte, r.Len)
+		if _, err := f.ReadAt(buf, r.Off); err != nil {
+			return nil, fmt.Errorf("failed to read archived log %s: %w", r.ID, err)
+		}
+		docs = append(docs, buf)
+	}
+	return docs, nil
+}
+
+// candidates returns the records that have a term of every group
+func (a *Archive) candidates(groups [][]string) []int {
+	if len(groups) == 0 {
+		all := make([]int, len(a.records))
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+
+	var result map[int]bool
+	for _, group := range groups {
+		union := map[int]bool{}
+		for _, term := range group {
+			for _, n := range a.terms[strings.ToLower(term)] {
+				if result == nil || result[n] {
+					union[n] = true
+				}
+			}
+		}
+		result = union
+	}
+
+	list := make([]int, 0, len(result))
+	for n := range result {
+		list = append(list, n)
+	}
+	sort.Ints(list)
+	return list
+}
+
+// Stats returns the size and coverage of the archive
+func (a *Archive) Stats() Stats {
+	s := Stats{
+		Segments: len(a.segments),
+		Records:  len(a.records),
+		Coverage: a.idx.Coverage,
+	}
+	for _, seg := range a.segments {
+		s.Bytes += seg.size
+	}
+	for i, r := range a.records {
+		ts := time.Unix(0, r.TS).UTC()
+		if i == 0 || ts.Before(s.Oldest) {
+			s.Oldest = ts
+		}
+		if ts.After(s.Newest) {
+			s.Newest = ts
+		}
+	}
+	return s
+}
+
+// save writes the index file, which only holds the coverage, atomically
+func (a *Archive) save() error {
+	data, err := json.Marshal(a.idx)
+	if err != nil {
+		return fmt.Errorf("failed to encode archive index: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(a.dir, indexFile+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary archive index: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write archive index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close archive index: %w", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(a.dir, indexFile)); err != nil {
+		return fmt.Errorf("failed to replace archive index: %w", err)
+	}
+	return nil
+}
+
+// segmentPath returns the path of segment n
+func (a *Archive) segmentPath(n int) string {
+	return filepath.Join(a.dir, segmentsDir, a.segments[n].name)
+}
+
+// entriesPath returns the path of the index file of segment n
+func (a *Archive) entriesPath(n int) string {
+	return strings.TrimSuffix(a.segmentPath(n), segmentExt) + entriesExt
+}
+
+// segmentWriter appends records to a segment. Their index entries are
+// appended when the segment is closed, after the records were written.
+type segmentWriter struct {
+	num         int
+	seg         *segment
+	f           *os.File
+	w           *bufio.Writer
+	off         int64
+	entriesPath string
+	entries     []entry
+}
+
+// openSegment opens the last segment for appending, or starts a new one
+// when it is full
+func (a *Archive) openSegment() (*segmentWriter, error) {
+	n := len(a.segments) - 1
+	if n < 0 || a.segments[n].count >= a.segmentSize {
+		a.segments = append(a.segments, segment{name: fmt.Sprintf("%06d%s", n+1, segmentExt)})
+		n++
+	}
+
+	f, err := os.OpenFile(a.segmentPath(n), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive segment: %w", err)
+	}
+	// Offsets follow the file rather than the index entries, which may lag
+	// behind after a crash
+	off, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to open archive segment: %w", err)
+	}
+	return &segmentWriter{num: n, seg: &a.segments[n], f: f, w: bufio.NewWriter(f), off: off, entriesPath: a.entriesPath(n)}, nil
+}
+
+// write appends a line with its index entry and returns its offset
+func (w *segmentWriter) write(line []byte, e entry) (int64, error) {
+	off := w.off
+	if _, err := w.w.Write(line); err != nil {
+		return 0, fmt.Errorf("failed to write archive segment: %w", err)
+	}
+	e.Off = off
+	w.entries = append(w.entries, e)
+	w.off += int64(len(line))
+	w.seg.count++
+	w.seg.size = w.off
+	return off, nil
+}
+
+// close flushes and closes the segment, then appends the index entries of
+// the written records; a nil writer is a no-op
+func (w *segmentWriter) close() error {
+	if w == nil {
+		return nil
+	}
+	if err := w.w.Flush(); err != nil {
+		_ = w.f.Close()
+		return fmt.Errorf("failed to write archive segment: %w", err)
+	}
+	if err := w.f.Close(); err != nil {
+		return fmt.Errorf("failed to close archive segment: %w", err)
+	}
+	if len(w.entries) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, e := range w.entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode archive index entry: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	f, err := os.OpenFile(w.entriesPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open archive index: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write archive index: %w", err)
+	}
+	return f.Close()
+}
+
+// compact removes insignificant whitespace, including newlines, from a
+// JSON document so that it fits on one line
+func compact(data json.RawMessage) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return bytes.ReplaceAll(data, []byte("\n"), []byte(" "))
+	}
+	return buf.Bytes()
+}
+
+// uniqueTerms returns the lowercased terms without duplicates
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	list := make([]string, 0, len(terms))
+	for _, t := range terms {
+		t = strings.ToLower(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		list = append(list, t)
+	}
+	return list
+}
+
+// mergeSpans sorts spans and merges the ones that overlap or touch
+func mergeSpans(spans []Span) []Span {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].From.Before(spans[j].From) })
+	var merged []Span
+	for _, s := range spans {
+		if last := len(merged) - 1; last >= 0 && !s.From.After(merged[last].To) {
+			if s.To.After(merged[last].To) {
+				merged[last].To = s.To
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
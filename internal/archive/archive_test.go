@@ -0,0 +1,325 @@
+package archive
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var base = time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+// record returns a record with a JSON document holding its ID
+func record(id string, offset time.Duration, terms ...string) Record {
+	return Record{
+		ID:        id,
+		Timestamp: base.Add(offset),
+		Terms:     terms,
+		Data:      json.RawMessage(`{"id": "` + id + `"}`),
+	}
+}
+
+// ids returns the IDs of JSON documents written by record
+func ids(t *testing.T, docs []json.RawMessage) []string {
+	t.Helper()
+	var list []string
+	for _, d := range docs {
+		var v struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(d, &v); err != nil {
+			t.Fatalf("archived document %q: %v", d, err)
+		}
+		list = append(list, v.ID)
+	}
+	return list
+}
+
+func TestArchive_AddSearch(t *testing.T) {
+	dir := t.TempDir()
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	a.segmentSize = 2
+
+	added, err := a.Add([]Record{
+		record("3", 3*time.Minute, "service:api", "status:error", "env:prod"),
+		record("1", time.Minute, "service:api", "status:info", "env:prod"),
+		record("2", 2*time.Minute, "Service:Web", "status:error", "env:prod"),
+	})
+	if err != nil || added != 3 {
+		t.Fatalf("Add() = %d, %v, want 3 added", added, err)
+	}
+	// Already archived logs are skipped
+	added, err = a.Add([]Record{record("1", time.Minute), record("4", 4*time.Minute, "service:api", "env:dev")})
+	if err != nil || added != 1 {
+		t.Fatalf("Add() = %d, %v, want 1 added", added, err)
+	}
+
+	// Reopen to read the index and segments back from disk
+	a, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got := a.Stats().Segments; got != 2 {
+		t.Errorf("Segments = %d, want 2 with a segment size of 2", got)
+	}
+
+	tests := []struct {
+		name   string
+		groups [][]string
+		from   time.Time
+		to     time.Time
+		want   []string
+	}{
+		{name: "all in time order", from: base, to: base.Add(time.Hour), want: []string{"1", "2", "3", "4"}},
+		{name: "time range excludes end", from: base.Add(2 * time.Minute), to: base.Add(4 * time.Minute), want: []string{"2", "3"}},
+		{name: "term", groups: [][]string{{"service:api"}}, from: base, to: base.Add(time.Hour), want: []string{"1", "3", "4"}},
+		{name: "terms are case-insensitive", groups: [][]string{{"SERVICE:WEB"}}, from: base, to: base.Add(time.Hour), want: []string{"2"}},
+		{name: "groups", groups: [][]string{{"env:prod"}, {"status:error", "status:warn"}}, from: base, to: base.Add(time.Hour), want: []string{"2", "3"}},
+		{name: "unknown term", groups: [][]string{{"env:staging"}}, from: base, to: base.Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, err := a.Search(tt.groups, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if got := ids(t, docs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArchive_AddCompactsDocuments(t *testing.T) {
+	a, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	rec := record("1", 0)
+	rec.Data = json.RawMessage("{\n  \"id\": \"1\",\n  \"message\": \"a\"\n}")
+	if _, err := a.Add([]Record{rec}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(a.Dir(), segmentsDir, "000000.ndjson"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if want := `{"id":"1","message":"a"}` + "\n"; string(data) != want {
+		t.Errorf("segment = %q, want %q", data, want)
+	}
+}
+
+func TestArchive_AddAppendsIndexEntries(t *testing.T) {
+	dir := t.TempDir()
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	for _, id := range []string{"1", "2"} {
+		if _, err := a.Add([]Record{record(id, time.Minute, "service:api")}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	// Adding records only appends to the segment and its index entries
+	if _, err := os.Stat(filepath.Join(dir, indexFile)); !os.IsNotExist(err) {
+		t.Errorf("index file was written by Add(): %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, segmentsDir, "000000"+entriesExt))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("index entries = %d, want 2", lines)
+	}
+
+	if err := a.Cover("", base, base.Add(time.Hour)); err != nil {
+		t.Fatalf("Cover() error = %v", err)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "service:api") {
+		t.Errorf("index file = %s, want only the coverage", data)
+	}
+}
+
+func TestOpen_RepairsIndexEntries(t *testing.T) {
+	dir := t.TempDir()
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := a.Add([]Record{record("1", time.Minute), record("2", 2*time.Minute)}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	// A crash can leave an entry of a record that was not written, and a
+	// torn entry
+	path := filepath.Join(dir, segmentsDir, "000000"+entriesExt)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"id":"lost","off":9999,"len":10,"ts":0}` + "\n" + `{"id":"torn"`); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	a, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := a.Add([]Record{record("3", 3*time.Minute)}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	a, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	docs, err := a.Search(nil, base, base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got := ids(t, docs); !reflect.DeepEqual(got, []string{"1", "2", "3"}) {
+		t.Errorf("Search() = %v, want [1 2 3]", got)
+	}
+}
+
+func TestArchive_Gaps(t *testing.T) {
+	dir := t.TempDir()
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	at := func(m int) time.Time { return base.Add(time.Duration(m) * time.Minute) }
+
+	for _, s := range []Span{{at(10), at(20)}, {at(15), at(30)}, {at(40), at(50)}} {
+		if err := a.Cover("service:api", s.From, s.To); err != nil {
+			t.Fatalf("Cover() error = %v", err)
+		}
+	}
+	if err := a.Cover("", at(50), at(60)); err != nil {
+		t.Fatalf("Cover() error = %v", err)
+	}
+	a, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got := a.Stats().Coverage["service:api"]; !reflect.DeepEqual(got, []Span{{at(10), at(30)}, {at(40), at(50)}}) {
+		t.Errorf("Coverage = %v, want merged spans", got)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		from  time.Time
+		to    time.Time
+		want  []Span
+	}{
+		{name: "covered", query: "service:api", from: at(12), to: at(28)},
+		{name: "covered by all logs", query: "service:api", from: at(40), to: at(60)},
+		{name: "gaps", query: "service:api", from: at(0), to: at(70), want: []Span{{at(0), at(10)}, {at(30), at(40)}, {at(60), at(70)}}},
+		{name: "other query", query: "service:web", from: at(10), to: at(55), want: []Span{{at(10), at(50)}}},
+		{name: "nothing covered", query: "env:dev", from: at(0), to: at(5), want: []Span{{at(0), at(5)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := a.Gaps(tt.query, tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Gaps() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArchive_Stats(t *testing.T) {
+	a, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := a.Add([]Record{record("2", 2*time.Minute), record("1", time.Minute)}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	s := a.Stats()
+	if s.Records != 2 || s.Segments != 1 || s.Bytes != int64(2*len(`{"id":"1"}`+"\n")) {
+		t.Errorf("Stats() = %+v, want 2 records in 1 segment", s)
+	}
+	if !s.Oldest.Equal(base.Add(time.Minute)) || !s.Newest.Equal(base.Add(2*time.Minute)) {
+		t.Errorf("Stats() range = %v - %v, want 10:01 - 10:02", s.Oldest, s.Newest)
+	}
+}
+
+func TestOpen_Invalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		index         string
+		errorContains string
+	}{
+		{name: "corrupt index", index: "{", errorContains: "failed to parse archive index"},
+		{name: "other version", index: `{"version":99}`, errorContains: "unsupported version 99"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, indexFile), []byte(tt.index), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Open(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Open() error = %v, want error containing %q", err, tt.errorContains)
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "prod")
+	a, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, err := a.Add([]Record{record("1", 0)}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	found, err := Remove(dir)
+	if err != nil || !found {
+		t.Fatalf("Remove() = %v, %v, want an archive removed", found, err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("archive directory still exists: %v", err)
+	}
+
+	found, err = Remove(dir)
+	if err != nil || found {
+		t.Errorf("Remove() = %v, %v, want no archive", found, err)
+	}
+}
+
+func TestRemove_KeepsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Open(dir); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Remove(dir); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("unrelated file was removed: %v", err)
+	}
+}
@@ -2,68 +2,225 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/credentials"
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+	"github.com/jedipunkz/datadog-log-tail/internal/redact"
 )
 
+// Sites lists the known Datadog sites
+var Sites = []string{
+	"datadoghq.com",
+	"us3.datadoghq.com",
+	"us5.datadoghq.com",
+	"datadoghq.eu",
+	"ap1.datadoghq.com",
+	"ap2.datadoghq.com",
+	"ddog-gov.com",
+}
+
 // Config represents the application configuration
 type Config struct {
-	APIKey       string
-	AppKey       string
-	Site         string
-	Tags         string
-	LogLevel     string
-	LogLevels    []string
-	OutputFormat string
-	Timestamp    string
-	Timeout      int
-	RetryCount   int
+	APIKey         string
+	AppKey         string
+	Site           string
+	APIURL         string
+	Tags           string
+	LogLevel       string
+	LogLevels      []string
+	OutputFormat   string
+	Multiline      string
+	Color          bool
+	ParseJSON      bool
+	MessageKeys    []string
+	FieldMap       FieldMap
+	Redact         bool
+	RedactRules    []redact.Rule
+	Timestamp      string
+	Since          time.Duration
+	Timeout        int
+	RetryCount     int
+	AlertsFile     string
+	Sinks          []string
+	StateFile      string
+	MaxCatchup     time.Duration
+	MaxLogsPerPoll int
+	MetricsAddr    string
+	Archive        bool
+	ArchiveDir     string
+	NoCache        bool
+	CacheDir       string
+	CacheHorizon   time.Duration
+	CacheMaxSize   int
+	ContextBefore  int
+	ContextAfter   int
+
+	// Credential sources
+	Profile         string
+	APIKeyCmd       string
+	AppKeyCmd       string
+	APIKeyRef       string
+	AppKeyRef       string
+	CredentialsFile string
+	// NonInteractive skips credential commands and keyrings, which may
+	// prompt or block (e.g. during shell completion)
+	NonInteractive bool
+
+	// Network
+	Proxy              string
+	CACert             string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
 }
 
 // New creates a new configuration with default values
 func New() *Config {
 	return &Config{
-		OutputFormat: "text",
-		Timeout:      30,
-		RetryCount:   3,
-		Site:         "datadoghq.com",
+		OutputFormat:    "text",
+		Multiline:       output.MultilineRaw,
+		MessageKeys:     []string{"msg", "message", "error"},
+		Timeout:         30,
+		RetryCount:      3,
+		CredentialsFile: credentials.DefaultFile(),
+		MaxCatchup:      time.Hour,
+		MaxLogsPerPoll:  1000,
+		CacheHorizon:    time.Hour,
+		CacheMaxSize:    256,
 	}
 }
 
-
-// Validate validates the configuration
+// Validate resolves the credentials and validates the configuration
 func (c *Config) Validate() error {
-	// API key must be set via environment variable
+	// API key: command, profile reference, environment variable or keys file
 	if c.APIKey == "" {
-		apiKey := os.Getenv("DD_API_KEY")
-		if apiKey == "" {
-			return fmt.Errorf("API key not set (DD_API_KEY)")
+		apiKey, err := c.resolveKey("API key", c.APIKeyCmd, c.APIKeyRef, "DD_API_KEY", "api_key")
+		if err != nil {
+			return err
 		}
 		c.APIKey = apiKey
 	}
 
-	// App key must be set via environment variable
+	// App key: command, profile reference, environment variable or keys file
 	if c.AppKey == "" {
-		appKey := os.Getenv("DD_APP_KEY")
-		if appKey == "" {
-			return fmt.Errorf("application key not set (DD_APP_KEY)")
+		appKey, err := c.resolveKey("application key", c.AppKeyCmd, c.AppKeyRef, "DD_APP_KEY", "app_key")
+		if err != nil {
+			return err
 		}
 		c.AppKey = appKey
 	}
 
-	// Site must be set via environment variable
-	site := os.Getenv("DD_SITE")
-	if site != "" {
-		c.Site = site
-	} else {
+	if err := c.ValidateSite(); err != nil {
+		return err
+	}
+	return c.ValidateOffline()
+}
+
+// ValidateArchive checks that fetched logs may be stored in the local
+// archive. The archive stores logs as returned by the API, so it is refused
+// with redaction.
+func (c *Config) ValidateArchive() error {
+	if c.Redact {
+		return fmt.Errorf("the local archive stores logs unredacted and cannot be used with redaction (use --redact=false to turn it off)")
+	}
+	return nil
+}
+
+// ValidateSite resolves and validates the site and API URL, without
+// credentials
+func (c *Config) ValidateSite() error {
+	// Site from the config file or profile takes precedence over DD_SITE
+	if c.Site == "" {
+		c.Site = os.Getenv("DD_SITE")
+	}
+	if c.Site == "" {
 		// Fallback to default if not set
 		c.Site = "datadoghq.com"
 	}
 
+	// An explicit API URL (e.g. a relay or a local stand-in) replaces the
+	// site's endpoint, so the site is only validated without one
+	if c.APIURL == "" {
+		c.APIURL = os.Getenv("DD_API_URL")
+	}
+	if c.APIURL != "" {
+		u, err := url.Parse(c.APIURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid API URL: %s (must be an http:// or https:// URL)", c.APIURL)
+		}
+		c.APIURL = strings.TrimSuffix(c.APIURL, "/")
+	} else if !isKnownSite(c.Site) {
+		return fmt.Errorf("unknown Datadog site: %s (must be one of %s, or set --api-url)", c.Site, strings.Join(Sites, ", "))
+	}
+	return nil
+}
+
+// ValidateOffline validates the settings that do not need credentials or
+// an API endpoint, for commands that only process local files
+func (c *Config) ValidateOffline() error {
 	if c.OutputFormat != "json" && c.OutputFormat != "text" {
 		return fmt.Errorf("invalid output format: %s (json or text must be specified)", c.OutputFormat)
 	}
 
+	// --since is a shortcut for a time range ending now
+	if c.Since < 0 {
+		return fmt.Errorf("invalid since: %v (must be positive)", c.Since)
+	}
+	if c.Since > 0 {
+		if c.Timestamp != "" {
+			return fmt.Errorf("since and timestamp cannot be specified together")
+		}
+		now := time.Now().UTC()
+		c.Timestamp = now.Add(-c.Since).Format(time.RFC3339) + "," + now.Format(time.RFC3339)
+	}
+
+	if !output.IsMultilineMode(c.Multiline) {
+		return fmt.Errorf("invalid multiline mode: %s (must be one of %s)", c.Multiline, strings.Join(output.MultilineModes, ", "))
+	}
+
+	if _, err := redact.New(c.RedactRules); err != nil {
+		return fmt.Errorf("invalid redaction rules: %w", err)
+	}
+
+	if c.StateFile != "" && c.MaxCatchup <= 0 {
+		return fmt.Errorf("invalid max catch-up: %v (must be positive)", c.MaxCatchup)
+	}
+
+	if (c.ClientCert == "") != (c.ClientKey == "") {
+		return fmt.Errorf("client certificate and client key must be specified together")
+	}
+
+	if c.Proxy != "" {
+		u, err := url.Parse(c.Proxy)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid proxy URL: %s", c.Proxy)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("invalid proxy URL: %s (http, https or socks5 scheme must be specified)", c.Proxy)
+		}
+	}
+
+	if c.ContextBefore < 0 || c.ContextAfter < 0 {
+		return fmt.Errorf("invalid context: %d before, %d after (must be 0 or positive)", c.ContextBefore, c.ContextAfter)
+	}
+
+	if c.MaxLogsPerPoll < 0 {
+		return fmt.Errorf("invalid max logs per poll: %d (must be 0 or positive)", c.MaxLogsPerPoll)
+	}
+
+	if c.CacheHorizon < 0 {
+		return fmt.Errorf("invalid cache horizon: %v (must be 0 or positive)", c.CacheHorizon)
+	}
+	if c.CacheDir != "" && c.CacheMaxSize <= 0 {
+		return fmt.Errorf("invalid cache max size: %d MiB (must be positive)", c.CacheMaxSize)
+	}
+
 	if c.LogLevel != "" {
 		// Parse comma-separated log levels
 		levels := strings.Split(c.LogLevel, ",")
@@ -92,6 +249,60 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// isKnownSite reports whether site is a known Datadog site
+func isKnownSite(site string) bool {
+	for _, s := range Sites {
+		if site == s {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveKey resolves a key from, in order of precedence: a command, the
+// profile's credential reference, an environment variable and the keys
+// file. Errors never include the key itself.
+func (c *Config) resolveKey(label, command, ref, envVar, name string) (string, error) {
+	if c.NonInteractive {
+		scheme, _, _ := strings.Cut(ref, ":")
+		if command != "" || scheme == "cmd" || scheme == "keyring" {
+			return "", fmt.Errorf("%s needs a credential command or keyring, which are not used non-interactively", label)
+		}
+	}
+
+	if command != "" {
+		key, err := credentials.FromCommand(command)
+		if err != nil {
+			return "", fmt.Errorf("failed to get %s: %w", label, err)
+		}
+		return key, nil
+	}
+
+	if ref != "" {
+		key, err := credentials.Resolve(ref, c.Profile, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to get %s for profile %s: %w", label, c.Profile, err)
+		}
+		return key, nil
+	}
+
+	if key := os.Getenv(envVar); key != "" {
+		return credentials.Check(key)
+	}
+
+	if c.CredentialsFile != "" {
+		key, found, err := credentials.Lookup(c.CredentialsFile, c.Profile, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to get %s: %w", label, err)
+		}
+		if found {
+			return key, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s not set (%s)", label, envVar)
+}
+
 // GetAPIKey returns the API key
 func (c *Config) GetAPIKey() string {
 	return c.APIKey
@@ -107,6 +318,11 @@ func (c *Config) GetSite() string {
 	return c.Site
 }
 
+// GetAPIURL returns the API base URL override
+func (c *Config) GetAPIURL() string {
+	return c.APIURL
+}
+
 // GetTags returns the tag filter
 func (c *Config) GetTags() string {
 	return c.Tags
@@ -127,6 +343,45 @@ func (c *Config) GetOutputFormat() string {
 	return c.OutputFormat
 }
 
+// GetMultiline returns how messages with embedded newlines are rendered
+func (c *Config) GetMultiline() string {
+	return c.Multiline
+}
+
+// GetColor returns whether text output is colored
+func (c *Config) GetColor() bool {
+	return c.Color
+}
+
+// GetParseJSON returns whether JSON messages are parsed into attributes
+func (c *Config) GetParseJSON() bool {
+	return c.ParseJSON
+}
+
+// GetMessageKeys returns the keys of a JSON message promoted to the message,
+// in order of preference
+func (c *Config) GetMessageKeys() []string {
+	return c.MessageKeys
+}
+
+// GetFieldMap returns the source paths of each log entry field, using the
+// defaults for fields that are not mapped
+func (c *Config) GetFieldMap() FieldMap {
+	m := DefaultFieldMap()
+	m.merge(c.FieldMap)
+	return m
+}
+
+// GetRedact returns whether sensitive data is redacted before output
+func (c *Config) GetRedact() bool {
+	return c.Redact
+}
+
+// GetRedactRules returns the custom redaction rules
+func (c *Config) GetRedactRules() []redact.Rule {
+	return c.RedactRules
+}
+
 // GetTimeout returns the connection timeout
 func (c *Config) GetTimeout() int {
 	return c.Timeout
@@ -142,3 +397,108 @@ func (c *Config) GetTimestamp() string {
 	return c.Timestamp
 }
 
+// GetSince returns how far back a batch search ending now starts
+func (c *Config) GetSince() time.Duration {
+	return c.Since
+}
+
+// GetAlertsFile returns the path to the alerting rules file
+func (c *Config) GetAlertsFile() string {
+	return c.AlertsFile
+}
+
+// GetSinks returns the configured output sink specifications
+func (c *Config) GetSinks() []string {
+	return c.Sinks
+}
+
+// GetStateFile returns the path of the persistent tail cursor file
+func (c *Config) GetStateFile() string {
+	return c.StateFile
+}
+
+// GetMaxCatchup returns how far back a resumed tail may catch up
+func (c *Config) GetMaxCatchup() time.Duration {
+	return c.MaxCatchup
+}
+
+// GetMaxLogsPerPoll returns the maximum number of logs read per tail poll (0 = unlimited)
+func (c *Config) GetMaxLogsPerPoll() int {
+	return c.MaxLogsPerPoll
+}
+
+// GetMetricsAddr returns the address the metrics endpoint listens on
+func (c *Config) GetMetricsAddr() string {
+	return c.MetricsAddr
+}
+
+// GetArchive returns whether fetched logs are persisted to the local archive
+func (c *Config) GetArchive() bool {
+	return c.Archive
+}
+
+// GetArchiveDir returns the local archive directory, empty for the default
+func (c *Config) GetArchiveDir() string {
+	return c.ArchiveDir
+}
+
+// GetCacheDir returns the response cache directory, empty if responses
+// are not cached. Responses are not cached with redaction, as they are
+// stored before logs are redacted.
+func (c *Config) GetCacheDir() string {
+	if c.NoCache || c.Redact {
+		return ""
+	}
+	return c.CacheDir
+}
+
+// GetCacheHorizon returns how long ago a time window must have ended for
+// its responses to be cached
+func (c *Config) GetCacheHorizon() time.Duration {
+	return c.CacheHorizon
+}
+
+// GetCacheMaxSize returns the maximum response cache size in bytes
+func (c *Config) GetCacheMaxSize() int64 {
+	return int64(c.CacheMaxSize) << 20
+}
+
+// GetContextBefore returns the number of context logs shown before a match
+func (c *Config) GetContextBefore() int {
+	return c.ContextBefore
+}
+
+// GetContextAfter returns the number of context logs shown after a match
+func (c *Config) GetContextAfter() int {
+	return c.ContextAfter
+}
+
+// GetProfile returns the selected configuration profile
+func (c *Config) GetProfile() string {
+	return c.Profile
+}
+
+// GetProxy returns the proxy URL used for API requests
+func (c *Config) GetProxy() string {
+	return c.Proxy
+}
+
+// GetCACert returns the path to additional trusted CA certificates
+func (c *Config) GetCACert() string {
+	return c.CACert
+}
+
+// GetClientCert returns the path to the TLS client certificate
+func (c *Config) GetClientCert() string {
+	return c.ClientCert
+}
+
+// GetClientKey returns the path to the TLS client key
+func (c *Config) GetClientKey() string {
+	return c.ClientKey
+}
+
+// GetInsecureSkipVerify returns whether TLS certificate verification is disabled
+func (c *Config) GetInsecureSkipVerify() bool {
+	return c.InsecureSkipVerify
+}
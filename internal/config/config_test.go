@@ -2,8 +2,12 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/redact"
 )
 
 func TestConfig_Validate(t *testing.T) {
@@ -62,6 +66,32 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr:       true,
 			errorContains: "invalid output format",
 		},
+		{
+			name: "Invalid multiline mode",
+			config: &Config{
+				OutputFormat: "text",
+				Multiline:    "fold",
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+			},
+			wantErr:       true,
+			errorContains: "invalid multiline mode",
+		},
+		{
+			name: "Invalid redaction rule",
+			config: &Config{
+				OutputFormat: "text",
+				RedactRules:  []redact.Rule{{Name: "broken", Pattern: "("}},
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+			},
+			wantErr:       true,
+			errorContains: "invalid redaction rules",
+		},
 		{
 			name: "Invalid log level",
 			config: &Config{
@@ -75,6 +105,162 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr:       true,
 			errorContains: "invalid log level",
 		},
+		{
+			name: "Negative max logs per poll",
+			config: &Config{
+				OutputFormat:   "text",
+				MaxLogsPerPoll: -1,
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+			},
+			wantErr:       true,
+			errorContains: "invalid max logs per poll",
+		},
+		{
+			name: "Negative cache horizon",
+			config: &Config{
+				OutputFormat: "text",
+				CacheHorizon: -time.Hour,
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+			},
+			wantErr:       true,
+			errorContains: "invalid cache horizon",
+		},
+		{
+			name: "Zero cache max size",
+			config: &Config{
+				OutputFormat: "text",
+				CacheDir:     "/tmp/dlt",
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+			},
+			wantErr:       true,
+			errorContains: "invalid cache max size",
+		},
+		{
+			name: "Unknown site",
+			config: &Config{
+				OutputFormat: "text",
+				Site:         "datadoghq.co",
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+			},
+			wantErr:       true,
+			errorContains: "unknown Datadog site",
+		},
+		{
+			name: "Unknown site from environment variable",
+			config: &Config{
+				OutputFormat: "text",
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+				"DD_SITE":    "us4.datadoghq.com",
+			},
+			wantErr:       true,
+			errorContains: "unknown Datadog site",
+		},
+		{
+			name: "API URL overrides site validation",
+			config: &Config{
+				OutputFormat: "text",
+				APIURL:       "http://localhost:8080/",
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+				"DD_SITE":    "relay.internal",
+			},
+			wantErr: false,
+		},
+		{
+			name: "API URL from environment variable",
+			config: &Config{
+				OutputFormat: "text",
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+				"DD_API_URL": "https://dd-relay.internal",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid API URL",
+			config: &Config{
+				OutputFormat: "text",
+				APIURL:       "localhost:8080",
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+			},
+			wantErr:       true,
+			errorContains: "invalid API URL",
+		},
+		{
+			name: "Negative context",
+			config: &Config{
+				OutputFormat:  "text",
+				ContextBefore: -1,
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+			},
+			wantErr:       true,
+			errorContains: "invalid context",
+		},
+		{
+			name: "Client certificate without key",
+			config: &Config{
+				OutputFormat: "text",
+				ClientCert:   "/etc/dlt/client.pem",
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+			},
+			wantErr:       true,
+			errorContains: "must be specified together",
+		},
+		{
+			name: "Invalid proxy scheme",
+			config: &Config{
+				OutputFormat: "text",
+				Proxy:        "ftp://proxy.example.com",
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+			},
+			wantErr:       true,
+			errorContains: "invalid proxy URL",
+		},
+		{
+			name: "Since with timestamp",
+			config: &Config{
+				OutputFormat: "text",
+				Since:        time.Hour,
+				Timestamp:    "2026-01-01T00:00:00Z,2026-01-01T01:00:00Z",
+			},
+			envVars: map[string]string{
+				"DD_API_KEY": "test-api-key",
+				"DD_APP_KEY": "test-app-key",
+			},
+			wantErr:       true,
+			errorContains: "cannot be specified together",
+		},
 		{
 			name: "Valid log levels",
 			config: &Config{
@@ -106,6 +292,7 @@ func TestConfig_Validate(t *testing.T) {
 			_ = os.Unsetenv("DD_API_KEY")
 			_ = os.Unsetenv("DD_APP_KEY")
 			_ = os.Unsetenv("DD_SITE")
+			_ = os.Unsetenv("DD_API_URL")
 
 			// Set test environment variables
 			for key, value := range tt.envVars {
@@ -141,18 +328,40 @@ func TestConfig_Validate(t *testing.T) {
 	_ = os.Unsetenv("DD_API_KEY")
 	_ = os.Unsetenv("DD_APP_KEY")
 	_ = os.Unsetenv("DD_SITE")
+	_ = os.Unsetenv("DD_API_URL")
 }
 
 func TestConfig_Getters(t *testing.T) {
 	config := &Config{
-		APIKey:       "test-api-key",
-		AppKey:       "test-app-key",
-		Site:         "us3.datadoghq.com",
-		Tags:         "service:web,env:prod",
-		LogLevel:     "info",
-		OutputFormat: "json",
-		Timeout:      60,
-		RetryCount:   5,
+		APIKey:             "test-api-key",
+		AppKey:             "test-app-key",
+		Site:               "us3.datadoghq.com",
+		APIURL:             "http://localhost:8080",
+		Tags:               "service:web,env:prod",
+		LogLevel:           "info",
+		OutputFormat:       "json",
+		Multiline:          "indent",
+		Color:              true,
+		ParseJSON:          true,
+		MessageKeys:        []string{"msg"},
+		Redact:             true,
+		RedactRules:        []redact.Rule{{Name: "password", Pattern: "password=(\\S+)"}},
+		Since:              2 * time.Hour,
+		Timeout:            60,
+		RetryCount:         5,
+		AlertsFile:         "alerts.yaml",
+		Sinks:              []string{"file:///tmp/dlt.log"},
+		StateFile:          "/var/lib/dlt/state.json",
+		MaxCatchup:         2 * time.Hour,
+		MaxLogsPerPoll:     500,
+		MetricsAddr:        ":9108",
+		ContextBefore:      2,
+		ContextAfter:       3,
+		Proxy:              "http://proxy.example.com:3128",
+		CACert:             "/etc/dlt/ca.pem",
+		ClientCert:         "/etc/dlt/client.pem",
+		ClientKey:          "/etc/dlt/client-key.pem",
+		InsecureSkipVerify: true,
 	}
 
 	tests := []struct {
@@ -163,11 +372,32 @@ func TestConfig_Getters(t *testing.T) {
 		{"GetAPIKey", func() interface{} { return config.GetAPIKey() }, "test-api-key"},
 		{"GetAppKey", func() interface{} { return config.GetAppKey() }, "test-app-key"},
 		{"GetSite", func() interface{} { return config.GetSite() }, "us3.datadoghq.com"},
+		{"GetAPIURL", func() interface{} { return config.GetAPIURL() }, "http://localhost:8080"},
 		{"GetTags", func() interface{} { return config.GetTags() }, "service:web,env:prod"},
 		{"GetLogLevel", func() interface{} { return config.GetLogLevel() }, "info"},
 		{"GetOutputFormat", func() interface{} { return config.GetOutputFormat() }, "json"},
+		{"GetMultiline", func() interface{} { return config.GetMultiline() }, "indent"},
+		{"GetColor", func() interface{} { return config.GetColor() }, true},
+		{"GetParseJSON", func() interface{} { return config.GetParseJSON() }, true},
+		{"GetMessageKeys", func() interface{} { return strings.Join(config.GetMessageKeys(), ",") }, "msg"},
+		{"GetRedact", func() interface{} { return config.GetRedact() }, true},
+		{"GetRedactRules", func() interface{} { return config.GetRedactRules()[0].Name }, "password"},
 		{"GetTimeout", func() interface{} { return config.GetTimeout() }, 60},
 		{"GetRetryCount", func() interface{} { return config.GetRetryCount() }, 5},
+		{"GetSince", func() interface{} { return config.GetSince() }, 2 * time.Hour},
+		{"GetAlertsFile", func() interface{} { return config.GetAlertsFile() }, "alerts.yaml"},
+		{"GetSinks", func() interface{} { return len(config.GetSinks()) }, 1},
+		{"GetStateFile", func() interface{} { return config.GetStateFile() }, "/var/lib/dlt/state.json"},
+		{"GetMaxCatchup", func() interface{} { return config.GetMaxCatchup() }, 2 * time.Hour},
+		{"GetMaxLogsPerPoll", func() interface{} { return config.GetMaxLogsPerPoll() }, 500},
+		{"GetMetricsAddr", func() interface{} { return config.GetMetricsAddr() }, ":9108"},
+		{"GetContextBefore", func() interface{} { return config.GetContextBefore() }, 2},
+		{"GetContextAfter", func() interface{} { return config.GetContextAfter() }, 3},
+		{"GetProxy", func() interface{} { return config.GetProxy() }, "http://proxy.example.com:3128"},
+		{"GetCACert", func() interface{} { return config.GetCACert() }, "/etc/dlt/ca.pem"},
+		{"GetClientCert", func() interface{} { return config.GetClientCert() }, "/etc/dlt/client.pem"},
+		{"GetClientKey", func() interface{} { return config.GetClientKey() }, "/etc/dlt/client-key.pem"},
+		{"GetInsecureSkipVerify", func() interface{} { return config.GetInsecureSkipVerify() }, true},
 	}
 
 	for _, tt := range tests {
@@ -179,3 +409,201 @@ func TestConfig_Getters(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_Validate_CredentialSources(t *testing.T) {
+	keysFile := filepath.Join(t.TempDir(), "credentials")
+	if err := os.WriteFile(keysFile, []byte("[default]\napi_key = file-api\napp_key = file-app\n\n[prod]\napi_key = prod-file-api\n"), 0600); err != nil {
+		t.Fatalf("failed to write keys file: %v", err)
+	}
+	t.Setenv("DLT_TEST_REF_KEY", "ref-api")
+
+	tests := []struct {
+		name       string
+		config     *Config
+		envAPIKey  string
+		wantAPIKey string
+		wantAppKey string
+	}{
+		{
+			name:       "Keys file",
+			config:     &Config{OutputFormat: "text", CredentialsFile: keysFile},
+			wantAPIKey: "file-api",
+			wantAppKey: "file-app",
+		},
+		{
+			name:       "Keys file profile section",
+			config:     &Config{OutputFormat: "text", CredentialsFile: keysFile, Profile: "prod"},
+			wantAPIKey: "prod-file-api",
+			wantAppKey: "file-app",
+		},
+		{
+			name:       "Environment variable over keys file",
+			config:     &Config{OutputFormat: "text", CredentialsFile: keysFile},
+			envAPIKey:  "env-api",
+			wantAPIKey: "env-api",
+			wantAppKey: "file-app",
+		},
+		{
+			name:       "Profile reference over environment variable",
+			config:     &Config{OutputFormat: "text", CredentialsFile: keysFile, APIKeyRef: "env:DLT_TEST_REF_KEY"},
+			envAPIKey:  "env-api",
+			wantAPIKey: "ref-api",
+			wantAppKey: "file-app",
+		},
+		{
+			name:       "Command over profile reference",
+			config:     &Config{OutputFormat: "text", CredentialsFile: keysFile, APIKeyRef: "env:DLT_TEST_REF_KEY", APIKeyCmd: "echo cmd-api"},
+			envAPIKey:  "env-api",
+			wantAPIKey: "cmd-api",
+			wantAppKey: "file-app",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DD_API_KEY", tt.envAPIKey)
+			t.Setenv("DD_APP_KEY", "")
+			t.Setenv("DD_SITE", "")
+
+			if err := tt.config.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if tt.config.GetAPIKey() != tt.wantAPIKey {
+				t.Errorf("APIKey = %v, want %v", tt.config.GetAPIKey(), tt.wantAPIKey)
+			}
+			if tt.config.GetAppKey() != tt.wantAppKey {
+				t.Errorf("AppKey = %v, want %v", tt.config.GetAppKey(), tt.wantAppKey)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_NonInteractive(t *testing.T) {
+	t.Setenv("DD_API_KEY", "")
+	t.Setenv("DD_APP_KEY", "env-app")
+	marker := filepath.Join(t.TempDir(), "ran")
+
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr bool
+	}{
+		{"Command flag", &Config{OutputFormat: "text", APIKeyCmd: "touch " + marker}, true},
+		{"Command reference", &Config{OutputFormat: "text", APIKeyRef: "cmd:touch " + marker}, true},
+		{"Keyring reference", &Config{OutputFormat: "text", APIKeyRef: "keyring:dlt/api"}, true},
+		{"Environment reference", &Config{OutputFormat: "text", APIKeyRef: "env:DD_APP_KEY"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.NonInteractive = true
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, err := os.Stat(marker); err == nil {
+				t.Error("credential command was run")
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateSite(t *testing.T) {
+	t.Setenv("DD_API_KEY", "")
+	t.Setenv("DD_SITE", "datadoghq.eu")
+	t.Setenv("DD_API_URL", "")
+
+	cfg := &Config{}
+	if err := cfg.ValidateSite(); err != nil {
+		t.Fatalf("ValidateSite() error = %v, want no error without credentials", err)
+	}
+	if cfg.GetSite() != "datadoghq.eu" {
+		t.Errorf("Site = %v, want datadoghq.eu from DD_SITE", cfg.GetSite())
+	}
+
+	cfg = &Config{APIURL: "http://localhost:8080/"}
+	if err := cfg.ValidateSite(); err != nil || cfg.GetAPIURL() != "http://localhost:8080" {
+		t.Errorf("ValidateSite() = %v, APIURL = %v, want http://localhost:8080", err, cfg.GetAPIURL())
+	}
+}
+
+func TestConfig_ValidateArchive(t *testing.T) {
+	if err := (&Config{}).ValidateArchive(); err != nil {
+		t.Errorf("ValidateArchive() error = %v, want no error without redaction", err)
+	}
+
+	err := (&Config{Redact: true}).ValidateArchive()
+	if err == nil {
+		t.Fatal("ValidateArchive() expected error with redaction but got none")
+	}
+	if !strings.Contains(err.Error(), "--redact=false") {
+		t.Errorf("ValidateArchive() error = %v, want a hint to --redact=false", err)
+	}
+}
+
+func TestConfig_Validate_KeyNotEchoed(t *testing.T) {
+	t.Setenv("DD_API_KEY", "")
+	t.Setenv("DD_APP_KEY", "")
+
+	cfg := &Config{OutputFormat: "text", APIKeyCmd: "echo 'secret with spaces'"}
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() expected error but got none")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("Validate() error leaks the key: %v", err)
+	}
+}
+
+func TestConfig_Validate_SiteFromProfile(t *testing.T) {
+	t.Setenv("DD_API_KEY", "test-api-key")
+	t.Setenv("DD_APP_KEY", "test-app-key")
+	t.Setenv("DD_SITE", "us5.datadoghq.com")
+
+	cfg := &Config{OutputFormat: "text", Site: "datadoghq.eu"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if cfg.GetSite() != "datadoghq.eu" {
+		t.Errorf("Site = %v, want datadoghq.eu from the profile", cfg.GetSite())
+	}
+}
+
+func TestConfig_Validate_SinceSetsTimestamp(t *testing.T) {
+	t.Setenv("DD_API_KEY", "test-api-key")
+	t.Setenv("DD_APP_KEY", "test-app-key")
+
+	cfg := &Config{OutputFormat: "text", Since: 2 * time.Hour}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	parts := strings.Split(cfg.GetTimestamp(), ",")
+	if len(parts) != 2 {
+		t.Fatalf("Timestamp = %q, want a from,to range", cfg.GetTimestamp())
+	}
+	from, err1 := time.Parse(time.RFC3339, parts[0])
+	to, err2 := time.Parse(time.RFC3339, parts[1])
+	if err1 != nil || err2 != nil {
+		t.Fatalf("Timestamp = %q, want RFC3339 times", cfg.GetTimestamp())
+	}
+	if to.Sub(from) != 2*time.Hour {
+		t.Errorf("range = %v, want 2h", to.Sub(from))
+	}
+}
+
+func TestConfig_ValidateOffline(t *testing.T) {
+	t.Setenv("DD_API_KEY", "")
+	t.Setenv("DD_APP_KEY", "")
+
+	cfg := &Config{OutputFormat: "json", LogLevel: "error,warn"}
+	if err := cfg.ValidateOffline(); err != nil {
+		t.Fatalf("ValidateOffline() error = %v, want no error without credentials", err)
+	}
+	if len(cfg.LogLevels) != 2 {
+		t.Errorf("LogLevels = %v, want [error warn]", cfg.LogLevels)
+	}
+
+	cfg = &Config{OutputFormat: "yaml"}
+	if err := cfg.ValidateOffline(); err == nil || !strings.Contains(err.Error(), "invalid output format") {
+		t.Errorf("ValidateOffline() error = %v, want invalid output format", err)
+	}
+}
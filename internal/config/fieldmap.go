@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldMap defines, for each log entry field, the ordered source paths the
+// value is taken from. The first path with a non-empty value wins.
+//
+// A path prefixed with @ is a (dotted) custom attribute path such as
+// @http.status_code. A bare name is a reserved log field (message, service,
+// status, host, source, or the alternative content, text, log and level
+// fields), falling back to the custom attribute with that name.
+//
+// Example:
+//
+//	field_map:
+//	  message: ["@msg", "message"]
+//	  status: ["@severity", "status"]
+type FieldMap struct {
+	Message []string `yaml:"message"`
+	Service []string `yaml:"service"`
+	Status  []string `yaml:"status"`
+	Host    []string `yaml:"host"`
+	Source  []string `yaml:"source"`
+	TraceID []string `yaml:"trace_id"`
+	SpanID  []string `yaml:"span_id"`
+}
+
+// DefaultFieldMap returns the field map used for fields that are not mapped
+func DefaultFieldMap() FieldMap {
+	return FieldMap{
+		Message: []string{"message", "content", "text", "log"},
+		Service: []string{"service", "host"},
+		Status:  []string{"status", "level"},
+		Host:    []string{"host"},
+		Source:  []string{"source"},
+		TraceID: []string{"@dd.trace_id"},
+		SpanID:  []string{"@dd.span_id"},
+	}
+}
+
+// fields returns pointers to the paths of each field by name
+func (m *FieldMap) fields() map[string]*[]string {
+	return map[string]*[]string{
+		"message":  &m.Message,
+		"service":  &m.Service,
+		"status":   &m.Status,
+		"host":     &m.Host,
+		"source":   &m.Source,
+		"trace_id": &m.TraceID,
+		"span_id":  &m.SpanID,
+	}
+}
+
+// Set parses a field=path,path... mapping (e.g. message=@msg,message) and
+// replaces the paths of that field
+func (m *FieldMap) Set(spec string) error {
+	name, value, ok := strings.Cut(spec, "=")
+	if !ok {
+		return fmt.Errorf("invalid field mapping: %s (field=path,... must be specified)", spec)
+	}
+	paths, ok := m.fields()[strings.TrimSpace(name)]
+	if !ok {
+		return fmt.Errorf("invalid field mapping: %s (unknown field %q)", spec, name)
+	}
+	var list []string
+	for _, p := range strings.Split(value, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			list = append(list, p)
+		}
+	}
+	if len(list) == 0 {
+		return fmt.Errorf("invalid field mapping: %s (no source paths)", spec)
+	}
+	*paths = list
+	return nil
+}
+
+// merge overrides the fields that are mapped in o
+func (m *FieldMap) merge(o FieldMap) {
+	src := o.fields()
+	for name, paths := range m.fields() {
+		if p := *src[name]; len(p) > 0 {
+			*paths = p
+		}
+	}
+}
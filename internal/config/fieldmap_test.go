@@ -0,0 +1,110 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFieldMap_Set(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          string
+		want          FieldMap
+		wantErr       bool
+		errorContains string
+	}{
+		{
+			name: "Message paths",
+			spec: "message=@msg, message",
+			want: FieldMap{Message: []string{"@msg", "message"}},
+		},
+		{
+			name: "Trace ID",
+			spec: "trace_id=@otel.trace_id",
+			want: FieldMap{TraceID: []string{"@otel.trace_id"}},
+		},
+		{
+			name:          "Missing separator",
+			spec:          "message",
+			wantErr:       true,
+			errorContains: "field=path",
+		},
+		{
+			name:          "Unknown field",
+			spec:          "timestamp=@ts",
+			wantErr:       true,
+			errorContains: "unknown field",
+		},
+		{
+			name:          "No paths",
+			spec:          "status= , ",
+			wantErr:       true,
+			errorContains: "no source paths",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m FieldMap
+			err := m.Set(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Set() expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Set() error = %v, want error containing %q", err, tt.errorContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if !reflect.DeepEqual(m, tt.want) {
+				t.Errorf("Set() = %+v, want %+v", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_GetFieldMap(t *testing.T) {
+	cfg := &Config{FieldMap: FieldMap{Status: []string{"@severity", "status"}}}
+
+	got := cfg.GetFieldMap()
+	want := DefaultFieldMap()
+	want.Status = []string{"@severity", "status"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetFieldMap() = %+v, want %+v", got, want)
+	}
+}
+
+func TestConfig_Apply_FieldMap(t *testing.T) {
+	path := writeConfigFile(t, `
+field_map:
+  message: ["@msg", "message"]
+  status: ["@level"]
+profiles:
+  app:
+    field_map:
+      status: ["@severity"]
+`)
+	f, err := LoadFile(path, false)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	cfg := New()
+	if err := cfg.Apply(f, "app"); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	fm := cfg.GetFieldMap()
+	if got := strings.Join(fm.Message, ","); got != "@msg,message" {
+		t.Errorf("message paths = %s, want @msg,message from the top level", got)
+	}
+	if got := strings.Join(fm.Status, ","); got != "@severity" {
+		t.Errorf("status paths = %s, want @severity from the profile", got)
+	}
+	if got := strings.Join(fm.Service, ","); got != "service,host" {
+		t.Errorf("service paths = %s, want the default", got)
+	}
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/redact"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File represents the contents of a dlt configuration file
+//
+// Example:
+//
+//	tags: "service:web,env:dev"
+//	log_level: "info"
+//	default_profile: prod
+//	profiles:
+//	  prod:
+//	    site: datadoghq.eu
+//	    proxy: http://proxy.corp.example:3128
+//	    ca_cert: ~/.config/dlt/corp-ca.pem
+//	    production: true
+//	    api_key: "keyring:dlt/prod-api"
+//	    app_key: "cmd:pass show dd/prod-app"
+type File struct {
+	Settings       `yaml:",inline"`
+	DefaultProfile string             `yaml:"default_profile"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+	Queries        map[string]Query   `yaml:"queries"`
+}
+
+// Settings are options that can be set at the top level of the
+// configuration file and overridden per profile
+type Settings struct {
+	Site         string `yaml:"site"`
+	APIURL       string `yaml:"api_url"`
+	Tags         string `yaml:"tags"`
+	LogLevel     string `yaml:"log_level"`
+	OutputFormat string `yaml:"output_format"`
+	Multiline    string `yaml:"multiline"`
+	Timeout      int    `yaml:"timeout"`
+	RetryCount   int    `yaml:"retry_count"`
+
+	ParseJSON   bool     `yaml:"parse_json"`
+	MessageKeys []string `yaml:"message_keys"`
+	FieldMap    FieldMap `yaml:"field_map"`
+
+	Redact      bool          `yaml:"redact"`
+	RedactRules []redact.Rule `yaml:"redact_rules"`
+
+	Archive    bool   `yaml:"archive"`
+	ArchiveDir string `yaml:"archive_dir"`
+
+	NoCache      bool          `yaml:"no_cache"`
+	CacheHorizon time.Duration `yaml:"cache_horizon"`
+	CacheMaxSize int           `yaml:"cache_max_size"`
+
+	Proxy              string `yaml:"proxy"`
+	CACert             string `yaml:"ca_cert"`
+	ClientCert         string `yaml:"client_cert"`
+	ClientKey          string `yaml:"client_key"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// Profile is a named set of settings and credential references
+type Profile struct {
+	Settings `yaml:",inline"`
+	// Production enables redaction by default
+	Production bool `yaml:"production"`
+	// APIKey and AppKey are credential references (env:, file:, cmd: or
+	// keyring:), never the keys themselves
+	APIKey string `yaml:"api_key"`
+	AppKey string `yaml:"app_key"`
+}
+
+// DefaultFilePath returns the default configuration file location
+func DefaultFilePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "dlt", "config.yaml")
+}
+
+// LoadFile loads a configuration file. If the file does not exist and
+// optional is true, an empty configuration is returned.
+func LoadFile(path string, optional bool) (*File, error) {
+	f := &File{}
+	if path == "" {
+		return f, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && optional {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// ProfileNames returns the names of all profiles in sorted order
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Apply applies the file's settings and the selected profile to the
+// configuration. An empty profile name selects the file's default profile.
+func (c *Config) Apply(f *File, profile string) error {
+	c.applySettings(f.Settings)
+
+	if profile == "" {
+		profile = f.DefaultProfile
+	}
+	if profile == "" {
+		return nil
+	}
+
+	p, ok := f.Profiles[profile]
+	if !ok {
+		return fmt.Errorf("profile not found: %s", profile)
+	}
+	c.Profile = profile
+	c.applySettings(p.Settings)
+	if p.Production {
+		c.Redact = true
+	}
+	c.APIKeyRef = p.APIKey
+	c.AppKeyRef = p.AppKey
+	return nil
+}
+
+// applySettings overrides configuration values with non-empty settings
+func (c *Config) applySettings(s Settings) {
+	if s.Site != "" {
+		c.Site = s.Site
+	}
+	if s.APIURL != "" {
+		c.APIURL = s.APIURL
+	}
+	if s.Tags != "" {
+		c.Tags = s.Tags
+	}
+	if s.LogLevel != "" {
+		c.LogLevel = s.LogLevel
+	}
+	if s.OutputFormat != "" {
+		c.OutputFormat = s.OutputFormat
+	}
+	if s.Multiline != "" {
+		c.Multiline = s.Multiline
+	}
+	if s.Timeout != 0 {
+		c.Timeout = s.Timeout
+	}
+	if s.RetryCount != 0 {
+		c.RetryCount = s.RetryCount
+	}
+	if s.ParseJSON {
+		c.ParseJSON = true
+	}
+	if len(s.MessageKeys) > 0 {
+		c.MessageKeys = s.MessageKeys
+	}
+	c.FieldMap.merge(s.FieldMap)
+	if s.Redact {
+		c.Redact = true
+	}
+	// Custom redaction rules of a profile add to the top-level rules
+	c.RedactRules = append(c.RedactRules, s.RedactRules...)
+	if s.Archive {
+		c.Archive = true
+	}
+	if s.ArchiveDir != "" {
+		c.ArchiveDir = s.ArchiveDir
+	}
+	if s.NoCache {
+		c.NoCache = true
+	}
+	if s.CacheHorizon != 0 {
+		c.CacheHorizon = s.CacheHorizon
+	}
+	if s.CacheMaxSize != 0 {
+		c.CacheMaxSize = s.CacheMaxSize
+	}
+	if s.Proxy != "" {
+		c.Proxy = s.Proxy
+	}
+	if s.CACert != "" {
+		c.CACert = s.CACert
+	}
+	if s.ClientCert != "" {
+		c.ClientCert = s.ClientCert
+	}
+	if s.ClientKey != "" {
+		c.ClientKey = s.ClientKey
+	}
+	if s.InsecureSkipVerify {
+		c.InsecureSkipVerify = true
+	}
+}
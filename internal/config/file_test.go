@@ -0,0 +1,235 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfigFile = `
+tags: "service:web,env:dev"
+log_level: "info"
+output_format: "text"
+timeout: 30
+retry_count: 3
+default_profile: dev
+profiles:
+  dev:
+    tags: "service:web,env:dev"
+  prod:
+    site: datadoghq.eu
+    tags: "service:web,env:prod"
+    output_format: json
+    api_key: "env:DLT_TEST_PROD_API_KEY"
+    app_key: "cmd:echo prod-app-key"
+`
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	f, err := LoadFile(writeConfigFile(t, testConfigFile), false)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if f.Tags != "service:web,env:dev" || f.Timeout != 30 {
+		t.Errorf("top-level settings = %+v, want tags and timeout from file", f.Settings)
+	}
+	if got := strings.Join(f.ProfileNames(), ","); got != "dev,prod" {
+		t.Errorf("ProfileNames() = %v, want dev,prod", got)
+	}
+	if f.Profiles["prod"].APIKey != "env:DLT_TEST_PROD_API_KEY" {
+		t.Errorf("prod api_key = %v, want credential reference", f.Profiles["prod"].APIKey)
+	}
+}
+
+func TestLoadFile_Missing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+
+	if _, err := LoadFile(path, true); err != nil {
+		t.Errorf("LoadFile(optional) error = %v, want nil", err)
+	}
+	if _, err := LoadFile(path, false); err == nil {
+		t.Error("LoadFile(required) expected error but got none")
+	}
+}
+
+func TestConfig_Apply(t *testing.T) {
+	f, err := LoadFile(writeConfigFile(t, testConfigFile), false)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		profile     string
+		wantProfile string
+		wantTags    string
+		wantFormat  string
+		wantSite    string
+		wantErr     bool
+	}{
+		{"Default profile", "", "dev", "service:web,env:dev", "text", "", false},
+		{"Explicit profile", "prod", "prod", "service:web,env:prod", "json", "datadoghq.eu", false},
+		{"Unknown profile", "staging", "", "", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := New()
+			err := cfg.Apply(f, tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.GetProfile() != tt.wantProfile {
+				t.Errorf("Profile = %v, want %v", cfg.GetProfile(), tt.wantProfile)
+			}
+			if cfg.GetTags() != tt.wantTags {
+				t.Errorf("Tags = %v, want %v", cfg.GetTags(), tt.wantTags)
+			}
+			if cfg.GetOutputFormat() != tt.wantFormat {
+				t.Errorf("OutputFormat = %v, want %v", cfg.GetOutputFormat(), tt.wantFormat)
+			}
+			if cfg.GetSite() != tt.wantSite {
+				t.Errorf("Site = %v, want %v", cfg.GetSite(), tt.wantSite)
+			}
+		})
+	}
+}
+
+func TestConfig_Apply_ProductionRedacts(t *testing.T) {
+	path := writeConfigFile(t, `
+redact_rules:
+  - name: password
+    pattern: 'password=(\S+)'
+profiles:
+  dev: {}
+  prod:
+    production: true
+    redact_rules:
+      - pattern: 'internal-[0-9]+'
+`)
+	f, err := LoadFile(path, false)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	tests := []struct {
+		profile    string
+		wantRedact bool
+		wantRules  int
+	}{
+		{"dev", false, 1},
+		{"prod", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			cfg := New()
+			if err := cfg.Apply(f, tt.profile); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if cfg.GetRedact() != tt.wantRedact {
+				t.Errorf("Redact = %v, want %v", cfg.GetRedact(), tt.wantRedact)
+			}
+			if len(cfg.GetRedactRules()) != tt.wantRules {
+				t.Errorf("RedactRules = %v, want %d rules", cfg.GetRedactRules(), tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestConfig_Apply_Archive(t *testing.T) {
+	path := writeConfigFile(t, `
+archive: true
+profiles:
+  dev: {}
+  prod:
+    archive_dir: /var/lib/dlt/prod
+`)
+	f, err := LoadFile(path, false)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	tests := []struct {
+		profile string
+		wantDir string
+	}{
+		{"dev", ""},
+		{"prod", "/var/lib/dlt/prod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			cfg := New()
+			if err := cfg.Apply(f, tt.profile); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if !cfg.GetArchive() {
+				t.Error("Archive = false, want true from the top level")
+			}
+			if cfg.GetArchiveDir() != tt.wantDir {
+				t.Errorf("ArchiveDir = %q, want %q", cfg.GetArchiveDir(), tt.wantDir)
+			}
+		})
+	}
+}
+
+func TestConfig_Apply_Cache(t *testing.T) {
+	path := writeConfigFile(t, `
+cache_horizon: 90m
+profiles:
+  dev:
+    cache_max_size: 64
+  prod:
+    no_cache: true
+  staging:
+    # Redacted logs are never cached unredacted
+    production: true
+`)
+	f, err := LoadFile(path, false)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	tests := []struct {
+		profile     string
+		wantDir     string
+		wantMaxSize int64
+	}{
+		{"dev", "/tmp/dlt", 64 << 20},
+		{"prod", "", 256 << 20},
+		{"staging", "", 256 << 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			cfg := New()
+			cfg.CacheDir = "/tmp/dlt"
+			if err := cfg.Apply(f, tt.profile); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if cfg.GetCacheHorizon() != 90*time.Minute {
+				t.Errorf("CacheHorizon = %v, want 1h30m from the top level", cfg.GetCacheHorizon())
+			}
+			if cfg.GetCacheDir() != tt.wantDir {
+				t.Errorf("GetCacheDir() = %q, want %q", cfg.GetCacheDir(), tt.wantDir)
+			}
+			if cfg.GetCacheMaxSize() != tt.wantMaxSize {
+				t.Errorf("GetCacheMaxSize() = %d, want %d", cfg.GetCacheMaxSize(), tt.wantMaxSize)
+			}
+		})
+	}
+}
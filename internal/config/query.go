@@ -0,0 +1,228 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Query is a saved query preset. Empty fields are not set by the preset.
+//
+// Example:
+//
+//	queries:
+//	  payments-errors:
+//	    query: "service:payments"
+//	    level: error
+//	    format: json
+type Query struct {
+	Query  string `yaml:"query,omitempty"`
+	Level  string `yaml:"level,omitempty"`
+	Format string `yaml:"format,omitempty"`
+	Since  string `yaml:"since,omitempty"`
+}
+
+// queryName matches valid saved query names
+var queryName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Validate checks the name and fields of a saved query
+func (q Query) Validate(name string) error {
+	if !queryName.MatchString(name) {
+		return fmt.Errorf("invalid query name: %q (letters, digits, '.', '_' and '-' are allowed)", name)
+	}
+	if q.Format != "" && q.Format != "json" && q.Format != "text" {
+		return fmt.Errorf("invalid output format: %s (json or text must be specified)", q.Format)
+	}
+	if q.Since != "" {
+		if d, err := time.ParseDuration(q.Since); err != nil || d <= 0 {
+			return fmt.Errorf("invalid since: %s (must be a positive duration such as 2h)", q.Since)
+		}
+	}
+	return nil
+}
+
+// Args returns the command line flags equivalent to the query
+func (q Query) Args() []string {
+	var args []string
+	if q.Query != "" {
+		args = append(args, "-q", quoteArg(q.Query))
+	}
+	if q.Level != "" {
+		args = append(args, "-l", quoteArg(q.Level))
+	}
+	if q.Format != "" {
+		args = append(args, "--format", q.Format)
+	}
+	if q.Since != "" {
+		args = append(args, "--since", q.Since)
+	}
+	return args
+}
+
+// quoteArg quotes a flag value for display if it contains spaces or quotes
+func quoteArg(s string) string {
+	if strings.ContainsAny(s, " \t\"'") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+// QueryNames returns the names of all saved queries in sorted order
+func (f *File) QueryNames() []string {
+	names := make([]string, 0, len(f.Queries))
+	for name := range f.Queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyQuery applies a saved query from the file to the configuration
+func (c *Config) ApplyQuery(f *File, name string) error {
+	q, ok := f.Queries[name]
+	if !ok {
+		return fmt.Errorf("saved query not found: %s", name)
+	}
+	if q.Query != "" {
+		c.Tags = q.Query
+	}
+	if q.Level != "" {
+		c.LogLevel = q.Level
+	}
+	if q.Format != "" {
+		c.OutputFormat = q.Format
+	}
+	if q.Since != "" {
+		d, err := time.ParseDuration(q.Since)
+		if err != nil {
+			return fmt.Errorf("saved query %s: invalid since: %w", name, err)
+		}
+		c.Since = d
+	}
+	return nil
+}
+
+// SaveQuery stores a query preset in the configuration file, replacing a
+// preset with the same name. The file is edited as a YAML node tree so that
+// comments and the order of other settings are kept. A missing file is
+// created.
+func SaveQuery(path, name string, q Query) error {
+	if err := q.Validate(name); err != nil {
+		return err
+	}
+	if path == "" {
+		return fmt.Errorf("no config file path")
+	}
+
+	var doc yaml.Node
+	mode := os.FileMode(0600)
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("failed to read config file: %w", err)
+	default:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if fi, err := os.Stat(path); err == nil {
+			mode = fi.Mode().Perm()
+		}
+	}
+
+	// An empty (or comment-only) file has no top-level mapping yet
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to update config file %s: top level is not a mapping", path)
+	}
+
+	var value yaml.Node
+	if err := value.Encode(q); err != nil {
+		return fmt.Errorf("failed to encode query: %w", err)
+	}
+	queries := ensureMapping(root, "queries")
+	if existing := mappingValue(queries, name); existing != nil {
+		// Keep comments attached to the existing preset
+		value.HeadComment, value.LineComment, value.FootComment = existing.HeadComment, existing.LineComment, existing.FootComment
+		*existing = value
+	} else {
+		queries.Content = append(queries.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, &value)
+	}
+
+	var buf strings.Builder
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	return writeFileAtomic(path, []byte(buf.String()), mode)
+}
+
+// mappingValue returns the value node of key in a mapping node, or nil
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// ensureMapping returns the mapping stored under key in a mapping node,
+// creating it if it is missing or empty (e.g. "queries:" without entries)
+func ensureMapping(m *yaml.Node, key string) *yaml.Node {
+	v := mappingValue(m, key)
+	if v == nil {
+		v = &yaml.Node{Kind: yaml.MappingNode}
+		m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+	} else if v.Kind != yaml.MappingNode {
+		*v = yaml.Node{Kind: yaml.MappingNode, HeadComment: v.HeadComment, LineComment: v.LineComment}
+	}
+	return v
+}
+
+// writeFileAtomic writes a file through a temporary file in the same
+// directory so that a failed write never leaves a truncated file
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveQuery(t *testing.T) {
+	path := writeConfigFile(t, `# dlt configuration
+log_level: info # default level
+
+profiles:
+  dev:
+    tags: "env:dev"
+`)
+
+	if err := SaveQuery(path, "payments-errors", Query{Query: "service:payments", Level: "error", Format: "json"}); err != nil {
+		t.Fatalf("SaveQuery() error = %v", err)
+	}
+	if err := SaveQuery(path, "web", Query{Since: "2h"}); err != nil {
+		t.Fatalf("SaveQuery() error = %v", err)
+	}
+	// Saving under an existing name replaces the preset
+	if err := SaveQuery(path, "web", Query{Level: "warn"}); err != nil {
+		t.Fatalf("SaveQuery() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config file: %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{"# dlt configuration", "log_level: info # default level", "dev:"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("config file lost %q:\n%s", want, content)
+		}
+	}
+
+	f, err := LoadFile(path, false)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if got := strings.Join(f.QueryNames(), ","); got != "payments-errors,web" {
+		t.Errorf("QueryNames() = %s, want payments-errors,web", got)
+	}
+	if q := f.Queries["payments-errors"]; q.Query != "service:payments" || q.Level != "error" || q.Format != "json" {
+		t.Errorf("payments-errors = %+v", q)
+	}
+	if q := f.Queries["web"]; q != (Query{Level: "warn"}) {
+		t.Errorf("web = %+v, want only the level of the replacement", q)
+	}
+	if f.Profiles["dev"].Tags != "env:dev" {
+		t.Errorf("profiles were not kept: %+v", f.Profiles)
+	}
+}
+
+func TestSaveQuery_NewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dlt", "config.yaml")
+
+	if err := SaveQuery(path, "errors", Query{Level: "error"}); err != nil {
+		t.Fatalf("SaveQuery() error = %v", err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("config file not created: %v", err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("config file mode = %v, want 0600", fi.Mode().Perm())
+	}
+	f, err := LoadFile(path, false)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if f.Queries["errors"].Level != "error" {
+		t.Errorf("Queries = %+v", f.Queries)
+	}
+}
+
+func TestSaveQuery_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	tests := []struct {
+		name          string
+		queryName     string
+		query         Query
+		errorContains string
+	}{
+		{"Invalid name", "has space", Query{Level: "error"}, "invalid query name"},
+		{"Invalid format", "q", Query{Format: "yaml"}, "invalid output format"},
+		{"Invalid since", "q", Query{Since: "yesterday"}, "invalid since"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SaveQuery(path, tt.queryName, tt.query)
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("SaveQuery() error = %v, want error containing %q", err, tt.errorContains)
+			}
+		})
+	}
+}
+
+func TestConfig_ApplyQuery(t *testing.T) {
+	f := &File{Queries: map[string]Query{
+		"payments-errors": {Query: "service:payments", Level: "error", Format: "json", Since: "30m"},
+	}}
+
+	cfg := New()
+	if err := cfg.ApplyQuery(f, "payments-errors"); err != nil {
+		t.Fatalf("ApplyQuery() error = %v", err)
+	}
+	if cfg.GetTags() != "service:payments" || cfg.GetLogLevel() != "error" || cfg.GetOutputFormat() != "json" || cfg.GetSince() != 30*time.Minute {
+		t.Errorf("config = %+v, want the saved query applied", cfg)
+	}
+
+	if err := cfg.ApplyQuery(f, "missing"); err == nil || !strings.Contains(err.Error(), "saved query not found") {
+		t.Errorf("ApplyQuery(missing) error = %v, want not found", err)
+	}
+}
+
+func TestQuery_Args(t *testing.T) {
+	q := Query{Query: "service:payments env:prod", Level: "error", Format: "json", Since: "2h"}
+	want := `-q "service:payments env:prod" -l error --format json --since 2h`
+	if got := strings.Join(q.Args(), " "); got != want {
+		t.Errorf("Args() = %s, want %s", got, want)
+	}
+}
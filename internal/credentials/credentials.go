@@ -0,0 +1,256 @@
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// commandTimeout bounds how long a credential command may run
+const commandTimeout = 30 * time.Second
+
+// maxStderrLog bounds how much of a failed credential command's stderr is
+// logged
+const maxStderrLog = 200
+
+// ErrNotFound is returned when a keys file does not contain the requested key
+var ErrNotFound = errors.New("credential not found")
+
+// Resolve resolves a credential reference. Supported references:
+//
+//	env:DD_API_KEY                  environment variable
+//	file:~/.config/dlt/keys         keys file (0600), key looked up by name
+//	file:~/.config/dlt/keys#my_key  keys file (0600), explicit key
+//	cmd:pass show dd/api            first line of a command's output
+//	keyring:dlt/prod-api            OS keyring entry (service/account)
+//
+// section selects the [section] of a keys file (usually the profile name)
+// and name is the key looked up in it (api_key or app_key). Errors never
+// include the resolved secret.
+func Resolve(ref, section, name string) (string, error) {
+	scheme, value, found := strings.Cut(ref, ":")
+	if !found || value == "" {
+		// Never echo the reference: it may be a secret pasted in by mistake
+		return "", fmt.Errorf("invalid credential reference (use env:, file:, cmd: or keyring:)")
+	}
+
+	switch scheme {
+	case "env":
+		v := os.Getenv(value)
+		if v == "" {
+			return "", fmt.Errorf("environment variable %s is not set", value)
+		}
+		return Check(v)
+	case "file":
+		path, key, hasKey := strings.Cut(value, "#")
+		if !hasKey {
+			key = name
+		}
+		return FromFile(path, section, key)
+	case "cmd":
+		return FromCommand(value)
+	case "keyring":
+		service, account, ok := strings.Cut(value, "/")
+		if !ok || service == "" || account == "" {
+			return "", fmt.Errorf("invalid keyring reference %q (use keyring:<service>/<account>)", ref)
+		}
+		return FromKeyring(service, account)
+	default:
+		return "", fmt.Errorf("unsupported credential source %q (use env:, file:, cmd: or keyring:)", scheme)
+	}
+}
+
+// FromCommand runs a shell command and returns the first line of its output,
+// e.g. "pass show dd/api"
+func FromCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Stdin = os.Stdin // allow interactive unlock prompts (e.g. gpg-agent)
+
+	if err := cmd.Run(); err != nil {
+		// stderr may contain parts of the secret or vault paths: it is
+		// never part of the error and only logged, truncated, for debugging
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			if len(msg) > maxStderrLog {
+				msg = msg[:maxStderrLog] + "..."
+			}
+			slog.Debug("Credential command output", "stderr", msg)
+		}
+		return "", fmt.Errorf("credential command %q failed: %w (run with -v for its output)", command, err)
+	}
+
+	line, _, _ := strings.Cut(stdout.String(), "\n")
+	if strings.TrimSpace(line) == "" {
+		return "", fmt.Errorf("credential command %q returned no output", command)
+	}
+	return Check(line)
+}
+
+// FromFile reads a key from a keys file. The file must not be readable by
+// group or others. Keys files use an INI-like format:
+//
+//	[default]
+//	api_key = ...
+//	app_key = ...
+//
+//	[prod]
+//	api_key = ...
+//
+// Keys outside any section and DD_API_KEY/DD_APP_KEY style names are also
+// accepted. The requested section is searched first, then [default].
+func FromFile(path, section, key string) (string, error) {
+	path = ExpandHome(path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read keys file: %w", err)
+	}
+	if err := checkPermissions(path, info); err != nil {
+		return "", err
+	}
+
+	values, err := parseKeysFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	sections := []string{section, "default", ""}
+	for _, s := range sections {
+		kv, ok := values[s]
+		if !ok {
+			continue
+		}
+		for _, k := range keyAliases(key) {
+			if v, ok := kv[k]; ok && v != "" {
+				return Check(v)
+			}
+		}
+	}
+	if section != "" && section != "default" {
+		return "", fmt.Errorf("%w: %s not in keys file %s (section [%s] or [default])", ErrNotFound, key, path, section)
+	}
+	return "", fmt.Errorf("%w: %s not in keys file %s", ErrNotFound, key, path)
+}
+
+// Lookup is like FromFile but reports found=false instead of an error when
+// the file does not exist or does not contain the key
+func Lookup(path, section, key string) (string, bool, error) {
+	if _, err := os.Stat(ExpandHome(path)); errors.Is(err, os.ErrNotExist) {
+		return "", false, nil
+	}
+	v, err := FromFile(path, section, key)
+	if errors.Is(err, ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return v, true, nil
+}
+
+// DefaultFile returns the default keys file location
+func DefaultFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "dlt", "credentials")
+}
+
+// Check trims a credential and rejects values that cannot be valid keys.
+// The value itself is never included in the error.
+func Check(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return "", fmt.Errorf("credential is empty")
+	}
+	for _, r := range v {
+		if r <= ' ' || r == 0x7f {
+			return "", fmt.Errorf("credential contains whitespace or control characters")
+		}
+	}
+	return v, nil
+}
+
+// ExpandHome expands a leading ~ to the user's home directory
+func ExpandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
+
+// checkPermissions ensures a secrets file is only accessible by its owner
+func checkPermissions(path string, info os.FileInfo) error {
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		return fmt.Errorf("keys file %s has permissions %04o; it must not be accessible by group or others (run: chmod 600 %s)", path, perm, path)
+	}
+	return nil
+}
+
+// parseKeysFile parses an INI-like keys file into section -> key -> value
+func parseKeysFile(path string) (map[string]map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	values := map[string]map[string]string{"": {}}
+	section := ""
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if values[section] == nil {
+				values[section] = map[string]string{}
+			}
+			continue
+		}
+		k, v, found := strings.Cut(line, "=")
+		if !found {
+			// Never echo the line: it may contain a secret
+			return nil, fmt.Errorf("invalid line %d in keys file %s (expected key = value)", lineNo, path)
+		}
+		k = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(k), "export ")))
+		v = strings.Trim(strings.TrimSpace(v), `"'`)
+		values[section][k] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read keys file: %w", err)
+	}
+	return values, nil
+}
+
+// keyAliases returns the accepted spellings of a key name
+func keyAliases(key string) []string {
+	key = strings.ToLower(key)
+	switch key {
+	case "api_key":
+		return []string{"api_key", "dd_api_key"}
+	case "app_key":
+		return []string{"app_key", "dd_app_key", "application_key", "dd_application_key"}
+	default:
+		return []string{key}
+	}
+}
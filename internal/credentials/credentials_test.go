@@ -0,0 +1,164 @@
+package credentials
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testKeysFile = `# dlt keys
+[default]
+api_key = default-api
+app_key = default-app
+
+[prod]
+api_key = "prod-api"
+export DD_APP_KEY=prod-app
+`
+
+func writeFile(t *testing.T, content string, perm os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.Chmod(path, perm); err != nil {
+		t.Fatalf("failed to chmod file: %v", err)
+	}
+	return path
+}
+
+func TestFromFile(t *testing.T) {
+	path := writeFile(t, testKeysFile, 0600)
+
+	tests := []struct {
+		name    string
+		section string
+		key     string
+		want    string
+	}{
+		{"Profile section", "prod", "api_key", "prod-api"},
+		{"Profile section with env-style key", "prod", "app_key", "prod-app"},
+		{"Default section", "", "api_key", "default-api"},
+		{"Unknown section falls back to default", "staging", "app_key", "default-app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromFile(path, tt.section, tt.key)
+			if err != nil {
+				t.Fatalf("FromFile() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FromFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromFile_Errors(t *testing.T) {
+	t.Run("Permissions too open", func(t *testing.T) {
+		path := writeFile(t, testKeysFile, 0644)
+		_, err := FromFile(path, "", "api_key")
+		if err == nil || !strings.Contains(err.Error(), "must not be accessible by group or others") {
+			t.Errorf("FromFile() error = %v, want permission error", err)
+		}
+	})
+
+	t.Run("Missing key", func(t *testing.T) {
+		path := writeFile(t, "[default]\napi_key = x\n", 0600)
+		_, err := FromFile(path, "", "app_key")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("FromFile() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Invalid line is not echoed", func(t *testing.T) {
+		path := writeFile(t, "super-secret-value\n", 0600)
+		_, err := FromFile(path, "", "api_key")
+		if err == nil {
+			t.Fatal("FromFile() expected error but got none")
+		}
+		if strings.Contains(err.Error(), "super-secret-value") {
+			t.Errorf("FromFile() error leaks file content: %v", err)
+		}
+	})
+}
+
+func TestLookup(t *testing.T) {
+	if _, found, err := Lookup(filepath.Join(t.TempDir(), "missing"), "", "api_key"); found || err != nil {
+		t.Errorf("Lookup() on missing file = (%v, %v), want (false, nil)", found, err)
+	}
+
+	path := writeFile(t, testKeysFile, 0600)
+	if v, found, err := Lookup(path, "prod", "api_key"); !found || err != nil || v != "prod-api" {
+		t.Errorf("Lookup() = (%v, %v, %v), want (prod-api, true, nil)", v, found, err)
+	}
+	if _, found, err := Lookup(path, "prod", "other_key"); found || err != nil {
+		t.Errorf("Lookup() for missing key = (%v, %v), want (false, nil)", found, err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv("DLT_TEST_KEY", "env-secret")
+	path := writeFile(t, testKeysFile, 0600)
+
+	tests := []struct {
+		name    string
+		ref     string
+		section string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"Environment variable", "env:DLT_TEST_KEY", "", "api_key", "env-secret", false},
+		{"Missing environment variable", "env:DLT_TEST_MISSING", "", "api_key", "", true},
+		{"File with implicit key", "file:" + path, "prod", "api_key", "prod-api", false},
+		{"File with explicit key", "file:" + path + "#app_key", "", "api_key", "default-app", false},
+		{"Command", "cmd:echo cmd-secret; echo second-line", "", "api_key", "cmd-secret", false},
+		{"Failing command", "cmd:exit 3", "", "api_key", "", true},
+		{"Unsupported source", "vault:secret/dd", "", "api_key", "", true},
+		{"Bare value", "0123456789abcdef", "", "api_key", "", true},
+		{"Invalid keyring reference", "keyring:no-account", "", "api_key", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.ref, tt.section, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %v, want %v", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve_BareValueNotEchoed(t *testing.T) {
+	_, err := Resolve("0123456789abcdef", "", "api_key")
+	if err == nil || strings.Contains(err.Error(), "0123456789abcdef") {
+		t.Errorf("Resolve() error = %v, want error without the value", err)
+	}
+}
+
+func TestFromCommand_StderrNotEchoed(t *testing.T) {
+	_, err := FromCommand("echo partial-$((6*7)) >&2; exit 1")
+	if err == nil || strings.Contains(err.Error(), "partial-42") {
+		t.Errorf("FromCommand() error = %v, want error without the command's stderr", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if v, err := Check("  abc123\n"); err != nil || v != "abc123" {
+		t.Errorf("Check() = (%v, %v), want (abc123, nil)", v, err)
+	}
+	if _, err := Check("abc 123"); err == nil {
+		t.Error("Check() expected error for embedded whitespace")
+	}
+	if _, err := Check("   "); err == nil {
+		t.Error("Check() expected error for empty credential")
+	}
+}
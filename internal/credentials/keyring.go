@@ -0,0 +1,99 @@
+package credentials
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keyringCommand returns the command that reads a secret from the OS
+// keyring, or nil if no supported backend exists on this platform
+var keyringCommand = func(service, account string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		// Secret Service API (GNOME Keyring, KeePassXC, KWallet) via libsecret
+		return exec.Command("secret-tool", "lookup", "service", service, "account", account)
+	default:
+		return nil
+	}
+}
+
+// FromKeyring looks up a secret in the OS keyring (Secret Service on
+// Linux, Keychain on macOS). When no keyring backend is available or the
+// lookup fails, the file fallback (see KeyringFile) is used if it exists.
+func FromKeyring(service, account string) (string, error) {
+	var keyringErr error
+	if cmd := keyringCommand(service, account); cmd != nil {
+		var stdout bytes.Buffer
+		cmd.Stdout = &stdout
+		err := cmd.Run()
+		switch {
+		case err == nil && strings.TrimSpace(stdout.String()) != "":
+			return Check(stdout.String())
+		case errors.Is(err, exec.ErrNotFound):
+			// No keyring backend installed; use the file fallback
+		case err == nil:
+			keyringErr = fmt.Errorf("keyring entry %s/%s not found", service, account)
+		default:
+			keyringErr = fmt.Errorf("keyring lookup for %s/%s failed: %w", service, account, err)
+		}
+	}
+
+	path := KeyringFile()
+	if _, err := os.Stat(path); path != "" && err == nil {
+		return fromKeyringFile(path, service, account)
+	}
+	if keyringErr != nil {
+		return "", keyringErr
+	}
+	return "", fmt.Errorf("no keyring backend available for %s/%s and fallback file %s does not exist", service, account, path)
+}
+
+// KeyringFile returns the location of the file-based keyring fallback. The
+// file maps "service/account" to secrets and must have 0600 permissions:
+//
+//	dlt/prod-api: "..."
+//	dlt/prod-app: "..."
+func KeyringFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "dlt", "keyring.yaml")
+}
+
+// fromKeyringFile reads a secret from the file-based keyring fallback
+func fromKeyringFile(path, service, account string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read keyring file: %w", err)
+	}
+	if err := checkPermissions(path, info); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read keyring file: %w", err)
+	}
+	var entries map[string]string
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		// The YAML error may quote file content, so it is not wrapped
+		return "", fmt.Errorf("failed to parse keyring file %s", path)
+	}
+
+	v, ok := entries[service+"/"+account]
+	if !ok {
+		return "", fmt.Errorf("keyring entry %s/%s not found in %s", service, account, path)
+	}
+	return Check(v)
+}
@@ -0,0 +1,84 @@
+package credentials
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func withKeyringCommand(t *testing.T, fn func(service, account string) *exec.Cmd) {
+	t.Helper()
+	orig := keyringCommand
+	keyringCommand = fn
+	t.Cleanup(func() { keyringCommand = orig })
+}
+
+func writeKeyringFile(t *testing.T, content string, perm os.FileMode) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	path := KeyringFile()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), perm); err != nil {
+		t.Fatalf("failed to write keyring file: %v", err)
+	}
+	if err := os.Chmod(path, perm); err != nil {
+		t.Fatalf("failed to chmod keyring file: %v", err)
+	}
+}
+
+func TestFromKeyring_Backend(t *testing.T) {
+	withKeyringCommand(t, func(service, account string) *exec.Cmd {
+		return exec.Command("echo", service+"-"+account+"-secret")
+	})
+
+	got, err := FromKeyring("dlt", "prod")
+	if err != nil {
+		t.Fatalf("FromKeyring() error = %v", err)
+	}
+	if got != "dlt-prod-secret" {
+		t.Errorf("FromKeyring() = %v, want dlt-prod-secret", got)
+	}
+}
+
+func TestFromKeyring_FileFallback(t *testing.T) {
+	withKeyringCommand(t, func(service, account string) *exec.Cmd {
+		return exec.Command("dlt-test-no-such-keyring-binary")
+	})
+	writeKeyringFile(t, "dlt/prod: file-secret\n", 0600)
+
+	got, err := FromKeyring("dlt", "prod")
+	if err != nil {
+		t.Fatalf("FromKeyring() error = %v", err)
+	}
+	if got != "file-secret" {
+		t.Errorf("FromKeyring() = %v, want file-secret", got)
+	}
+
+	if _, err := FromKeyring("dlt", "missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("FromKeyring() error = %v, want not found", err)
+	}
+}
+
+func TestFromKeyring_FileFallbackPermissions(t *testing.T) {
+	withKeyringCommand(t, func(service, account string) *exec.Cmd { return nil })
+	writeKeyringFile(t, "dlt/prod: file-secret\n", 0644)
+
+	if _, err := FromKeyring("dlt", "prod"); err == nil || !strings.Contains(err.Error(), "permissions") {
+		t.Errorf("FromKeyring() error = %v, want permission error", err)
+	}
+}
+
+func TestFromKeyring_NoBackend(t *testing.T) {
+	withKeyringCommand(t, func(service, account string) *exec.Cmd { return nil })
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if _, err := FromKeyring("dlt", "prod"); err == nil || !strings.Contains(err.Error(), "no keyring backend") {
+		t.Errorf("FromKeyring() error = %v, want no backend error", err)
+	}
+}
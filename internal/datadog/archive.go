@@ -0,0 +1,210 @@
+package datadog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/archive"
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+	"github.com/jedipunkz/datadog-log-tail/internal/filter"
+)
+
+// archiveSettleDelay is how long logs take to be searchable after their
+// timestamp. Windows are only recorded as covered up to this long ago, as
+// later logs may still arrive for more recent ones.
+const archiveSettleDelay = 5 * time.Minute
+
+// plainTag matches tag filters that match an index term exactly
+var plainTag = regexp.MustCompile(`^[a-z][a-z0-9_.\-/]*:[^*?\s()"]+$`)
+
+// SetArchive persists fetched logs to an archive
+func (c *Client) SetArchive(a *archive.Archive) {
+	c.archive = a
+}
+
+// PreferArchive answers searches from the archive where it covers the time
+// range, fetching only the rest from the API
+func (c *Client) PreferArchive() {
+	c.preferArchive = true
+}
+
+// searchLogs returns the logs between from and to, from the archive where
+// preferred, otherwise from the API
+func (c *Client) searchLogs(ctx context.Context, from, to time.Time) ([]LogEntry, error) {
+	if c.archive == nil || !c.preferArchive {
+		return c.fetchAllLogsV2(ctx, from, to)
+	}
+
+	var fetched []LogEntry
+	gaps := c.archive.Gaps(c.buildQueryV2(), from, to)
+	for _, gap := range gaps {
+		slog.Info("Fetching logs not covered by the archive", "from", gap.From.Format(time.RFC3339), "to", gap.To.Format(time.RFC3339))
+		logs, err := c.fetchAllLogsV2(ctx, gap.From, gap.To)
+		if err != nil {
+			return nil, err
+		}
+		fetched = append(fetched, logs...)
+	}
+	if len(gaps) == 0 {
+		slog.Info("Time range is covered by the archive", "archive", c.archive.Dir())
+	}
+
+	archived, err := c.archivedLogs(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetched logs are archived as well, unless archiving them failed
+	seen := make(map[string]bool, len(archived))
+	for _, log := range archived {
+		seen[log.ID] = true
+	}
+	logs := archived
+	for _, log := range fetched {
+		if !seen[log.ID] {
+			logs = append(logs, log)
+		}
+	}
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].Timestamp < logs[j].Timestamp })
+	return logs, nil
+}
+
+// archivedLogs returns the archived logs between from and to that match
+// the configured filters
+func (c *Client) archivedLogs(from, to time.Time) ([]LogEntry, error) {
+	expr, err := filter.Parse(c.buildQueryV2())
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	docs, err := c.archive.Search(c.archiveTerms(), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []LogEntry
+	for _, doc := range docs {
+		var d v2Log
+		if err := json.Unmarshal(doc, &d); err != nil {
+			return nil, fmt.Errorf("failed to parse archived log: %w", err)
+		}
+		if log := c.toLogEntry(d); expr.Match(log) {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+// archiveLogs adds fetched logs to the archive. Failures are logged, as
+// the logs themselves were fetched.
+func (c *Client) archiveLogs(data []v2Log) {
+	if c.archive == nil || len(data) == 0 {
+		return
+	}
+	records := make([]archive.Record, 0, len(data))
+	for _, d := range data {
+		doc, err := json.Marshal(d)
+		if err != nil {
+			continue
+		}
+		ts, _ := time.Parse(time.RFC3339Nano, d.Attrs.Timestamp)
+		records = append(records, archive.Record{ID: d.ID, Timestamp: ts, Terms: logTerms(d), Data: doc})
+	}
+	if _, err := c.archive.Add(records); err != nil {
+		c.archiveFailures++
+		slog.Warn("Failed to archive logs", "error", err)
+	}
+}
+
+// archiveCoverage records that all logs of the configured query between
+// from and to were fetched, except for the last archiveSettleDelay.
+// failures is the archive failure count when the fetch started; nothing is
+// recorded if it changed.
+func (c *Client) archiveCoverage(from, to time.Time, failures int) {
+	if c.archive == nil {
+		return
+	}
+	if c.archiveFailures != failures {
+		slog.Warn("Not recording the time range as archived, as archiving logs failed",
+			"from", from.Format(time.RFC3339), "to", to.Format(time.RFC3339))
+		return
+	}
+	if settled := time.Now().Add(-archiveSettleDelay); to.After(settled) {
+		to = settled
+	}
+	// The API is queried with second precision
+	if err := c.archive.Cover(c.buildQueryV2(), from.Truncate(time.Second), to.Truncate(time.Second)); err != nil {
+		slog.Warn("Failed to record archive coverage", "error", err)
+	}
+}
+
+// logTerms returns the index terms of a log: its service, status, host and
+// source with the default field map, and its tags
+func logTerms(d v2Log) []string {
+	f := mapFields(config.DefaultFieldMap(), d.Attrs.reserved(), d.Attrs.Attributes)
+	terms := make([]string, 0, 4+len(d.Attrs.Tags))
+	for _, kv := range [][2]string{{"service", f.Service}, {"status", f.Status}, {"host", f.Host}, {"source", f.Source}} {
+		if kv[1] != "" {
+			terms = append(terms, kv[0]+":"+kv[1])
+		}
+	}
+	return append(terms, d.Attrs.Tags...)
+}
+
+// archiveTerms returns the groups of index terms that logs matching the
+// configured filters have one of, to narrow down archive searches. Only
+// plain tag filters are used, and reserved fields only if they are mapped
+// as when the logs were indexed; the filters are evaluated on the results
+// anyway.
+func (c *Client) archiveTerms() [][]string {
+	var groups [][]string
+	for _, tag := range strings.Split(c.config.GetTags(), ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !plainTag.MatchString(tag) {
+			continue
+		}
+		key, _, _ := strings.Cut(tag, ":")
+		if c.unindexedKey(key) {
+			continue
+		}
+		groups = append(groups, []string{tag})
+	}
+
+	levels := c.config.GetLogLevels()
+	if len(levels) == 0 && c.config.GetLogLevel() != "" {
+		levels = []string{c.config.GetLogLevel()}
+	}
+	if len(levels) > 0 && !c.unindexedKey("status") {
+		group := make([]string, 0, len(levels))
+		for _, level := range levels {
+			group = append(group, "status:"+strings.ToLower(level))
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// unindexedKey reports whether filters on key cannot be answered from the
+// index terms: reserved fields without index terms, and those whose
+// mapping was changed
+func (c *Client) unindexedKey(key string) bool {
+	fm := c.config.FieldMap
+	switch key {
+	case "message", "id", "trace_id", "span_id":
+		return true
+	case "service":
+		return len(fm.Service) > 0
+	case "status":
+		return len(fm.Status) > 0
+	case "host":
+		return len(fm.Host) > 0
+	case "source":
+		return len(fm.Source) > 0
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package datadog
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/archive"
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+)
+
+// archiveLog returns a v2 API log of a service with a status and tags
+func archiveLog(id string, ts time.Time, service, status string, tags ...string) map[string]interface{} {
+	l := v2TestLog(id, ts, "log "+id)
+	attrs := l["attributes"].(map[string]interface{})
+	attrs["service"] = service
+	attrs["status"] = status
+	attrs["tags"] = tags
+	return l
+}
+
+func TestClient_searchLogs_Archive(t *testing.T) {
+	base := time.Now().UTC().Add(-2 * time.Hour).Truncate(time.Second)
+	srv := &rangeServer{logs: []map[string]interface{}{
+		archiveLog("1", base.Add(time.Minute), "api", "error", "env:prod"),
+		archiveLog("2", base.Add(2*time.Minute), "web", "error", "env:prod"),
+		archiveLog("3", base.Add(3*time.Minute), "api", "info", "env:prod"),
+	}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	a, err := archive.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("archive.Open() error = %v", err)
+	}
+	newClient := func(cfg *config.Config) *Client {
+		if err := cfg.ValidateOffline(); err != nil {
+			t.Fatalf("ValidateOffline() error = %v", err)
+		}
+		c := &Client{config: cfg, httpClient: server.Client(), baseURL: server.URL}
+		c.SetArchive(a)
+		c.PreferArchive()
+		return c
+	}
+	ids := func(logs []LogEntry) []string {
+		var list []string
+		for _, log := range logs {
+			list = append(list, log.ID)
+		}
+		return list
+	}
+
+	// Fetching all logs of the first 10 minutes archives and covers them
+	all := newClient(&config.Config{OutputFormat: "text"})
+	logs, err := all.FetchLogs(context.Background(), base, base.Add(10*time.Minute))
+	if err != nil {
+		t.Fatalf("FetchLogs() error = %v", err)
+	}
+	if got := ids(logs); !reflect.DeepEqual(got, []string{"1", "2", "3"}) {
+		t.Errorf("FetchLogs() = %v, want [1 2 3]", got)
+	}
+	if len(srv.ranges) != 1 {
+		t.Fatalf("requests = %v, want 1", srv.ranges)
+	}
+
+	// A covered range of a narrower query is answered from the archive
+	apiErrors := newClient(&config.Config{OutputFormat: "text", Tags: "service:api", LogLevel: "error"})
+	logs, err = apiErrors.FetchLogs(context.Background(), base, base.Add(5*time.Minute))
+	if err != nil {
+		t.Fatalf("FetchLogs() error = %v", err)
+	}
+	if got := ids(logs); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("FetchLogs() = %v, want [1] from the archive", got)
+	}
+	if len(srv.ranges) != 1 {
+		t.Errorf("requests = %v, want none for a covered range", srv.ranges[1:])
+	}
+
+	// Only the part that is not covered is fetched
+	srv.logs = append(srv.logs, archiveLog("4", base.Add(12*time.Minute), "api", "error"))
+	logs, err = all.FetchLogs(context.Background(), base.Add(5*time.Minute), base.Add(15*time.Minute))
+	if err != nil {
+		t.Fatalf("FetchLogs() error = %v", err)
+	}
+	if got := ids(logs); !reflect.DeepEqual(got, []string{"4"}) {
+		t.Errorf("FetchLogs() = %v, want [4]", got)
+	}
+	want := base.Add(10*time.Minute).Format(time.RFC3339) + "/" + base.Add(15*time.Minute).Format(time.RFC3339)
+	if len(srv.ranges) != 2 || srv.ranges[1] != want {
+		t.Errorf("requests = %v, want only the gap %v", srv.ranges, want)
+	}
+}
+
+func TestClient_archiveCoverage_Settle(t *testing.T) {
+	a, err := archive.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("archive.Open() error = %v", err)
+	}
+	c := &Client{config: &config.Config{}}
+	c.SetArchive(a)
+
+	// Recent logs may still arrive, so the last minutes are not covered
+	now := time.Now()
+	c.archiveCoverage(now.Add(-time.Hour), now, 0)
+	gaps := a.Gaps("", now.Add(-time.Hour), now)
+	if len(gaps) != 1 || gaps[0].To != now || now.Sub(gaps[0].From) < archiveSettleDelay {
+		t.Errorf("Gaps() = %v, want the last %v uncovered", gaps, archiveSettleDelay)
+	}
+}
+
+func TestClient_FetchLogs_ArchiveFailure(t *testing.T) {
+	base := time.Now().UTC().Add(-2 * time.Hour).Truncate(time.Second)
+	server := httptest.NewServer(&rangeServer{logs: []map[string]interface{}{
+		archiveLog("1", base.Add(time.Minute), "api", "error"),
+	}})
+	defer server.Close()
+
+	dir := t.TempDir()
+	a, err := archive.Open(dir)
+	if err != nil {
+		t.Fatalf("archive.Open() error = %v", err)
+	}
+	// Segments cannot be written when their directory is a file
+	segments := filepath.Join(dir, "segments")
+	if err := os.RemoveAll(segments); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(segments, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Client{config: &config.Config{OutputFormat: "text"}, httpClient: server.Client(), baseURL: server.URL}
+	c.SetArchive(a)
+	if _, err := c.FetchLogs(context.Background(), base, base.Add(10*time.Minute)); err != nil {
+		t.Fatalf("FetchLogs() error = %v", err)
+	}
+	if gaps := a.Gaps("", base, base.Add(10*time.Minute)); len(gaps) != 1 {
+		t.Errorf("Gaps() = %v, want the range uncovered after a failed archive write", gaps)
+	}
+}
+
+func TestClient_archiveTerms(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want [][]string
+	}{
+		{name: "no filters", cfg: &config.Config{}},
+		{
+			name: "tags and levels",
+			cfg:  &config.Config{Tags: "service:API, env:prod", LogLevels: []string{"error", "warn"}},
+			want: [][]string{{"service:api"}, {"env:prod"}, {"status:error", "status:warn"}},
+		},
+		{
+			name: "complex filters are skipped",
+			cfg:  &config.Config{Tags: "env:prod*,-env:dev,@http.status:500,message:timeout,version:1.2"},
+			want: [][]string{{"version:1.2"}},
+		},
+		{
+			name: "remapped fields are skipped",
+			cfg:  &config.Config{Tags: "service:api,env:prod", LogLevel: "error", FieldMap: config.FieldMap{Service: []string{"@app"}, Status: []string{"@severity"}}},
+			want: [][]string{{"env:prod"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{config: tt.cfg}
+			if got := c.archiveTerms(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("archiveTerms() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogTerms(t *testing.T) {
+	d := v2Log{ID: "1", Attrs: v2LogAttributes{Host: "web-1", LogLevel: "warn", Tags: []string{"env:prod"}}}
+	// The service falls back to the host and the status to the level
+	want := []string{"service:web-1", "status:warn", "host:web-1", "env:prod"}
+	if got := logTerms(d); !reflect.DeepEqual(got, want) {
+		t.Errorf("logTerms() = %v, want %v", got, want)
+	}
+}
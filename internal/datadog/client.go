@@ -1,13 +1,23 @@
 package datadog
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
-	"os"
+	"strings"
 	"time"
 
+	"github.com/jedipunkz/datadog-log-tail/internal/alert"
+	"github.com/jedipunkz/datadog-log-tail/internal/archive"
 	"github.com/jedipunkz/datadog-log-tail/internal/config"
+	"github.com/jedipunkz/datadog-log-tail/internal/logging"
+	"github.com/jedipunkz/datadog-log-tail/internal/metrics"
+	"github.com/jedipunkz/datadog-log-tail/internal/redact"
+	"github.com/jedipunkz/datadog-log-tail/internal/respcache"
+	"github.com/jedipunkz/datadog-log-tail/internal/sink"
 )
 
 // Client represents a Datadog API client
@@ -15,22 +25,68 @@ type Client struct {
 	config     *config.Config
 	httpClient *http.Client
 	baseURL    string
+	alerts     *alert.Engine
+	sinks      []sink.Sink
+	registry   *metrics.Registry
+	metrics    *clientMetrics
+	redactor   *redact.Redactor
+	noStdout   bool
+
+	archive       *archive.Archive
+	preferArchive bool
+	// archiveFailures counts failed archive writes, so that time ranges
+	// with logs missing from the archive are not recorded as covered
+	archiveFailures int
+	cache           *respcache.Cache
 }
 
 // NewClient creates a new Datadog client
 func NewClient(cfg *config.Config) (*Client, error) {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.GetInsecureSkipVerify() {
+		slog.Warn("TLS certificate verification is disabled (--insecure-skip-verify); API keys and logs can be intercepted, use --ca-cert instead")
+	}
+
 	// Create HTTP client with timeout
 	httpClient := &http.Client{
-		Timeout: time.Duration(cfg.GetTimeout()) * time.Second,
+		Transport: transport,
+		Timeout:   time.Duration(cfg.GetTimeout()) * time.Second,
+	}
+
+	// Determine base URL based on site, unless overridden
+	baseURL := cfg.GetAPIURL()
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://api.%s", cfg.GetSite())
+	}
+
+	// Redact sensitive data before any output
+	var redactor *redact.Redactor
+	if cfg.GetRedact() {
+		redactor, err = redact.New(cfg.GetRedactRules())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Cache responses of past time windows
+	var cache *respcache.Cache
+	if dir := cfg.GetCacheDir(); dir != "" {
+		cache = respcache.New(dir, cfg.GetCacheMaxSize())
 	}
 
-	// Determine base URL based on site
-	baseURL := fmt.Sprintf("https://api.%s", cfg.GetSite())
+	registry := metrics.NewRegistry()
 
 	return &Client{
 		config:     cfg,
 		httpClient: httpClient,
 		baseURL:    baseURL,
+		registry:   registry,
+		metrics:    newClientMetrics(registry),
+		redactor:   redactor,
+		cache:      cache,
 	}, nil
 }
 
@@ -50,29 +106,114 @@ func (c *Client) createRequest(ctx context.Context, method, endpoint string) (*h
 	return req, nil
 }
 
-// doRequest executes an HTTP request
+// rateLimitHeaders are the Datadog rate limit response headers logged at
+// debug level
+var rateLimitHeaders = []string{
+	"X-RateLimit-Limit",
+	"X-RateLimit-Period",
+	"X-RateLimit-Remaining",
+	"X-RateLimit-Reset",
+	"X-RateLimit-Name",
+}
+
+// maxErrorBody bounds how much of an error response is read
+const maxErrorBody = 64 << 10
+
+// setBody sets a request body that can be re-read for debug logging
+func setBody(req *http.Request, body []byte) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+}
+
+// doRequest executes an HTTP request. All API requests go through it so
+// that they are logged consistently: at debug level with the request body,
+// status, latency and rate limit headers, and at trace level (--debug-http)
+// with redacted headers and the response body.
 func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
-	// Output debug information
-	fmt.Fprintf(os.Stderr, "API request: %s %s\n", req.Method, req.URL.String())
+	ctx := req.Context()
+	attrs := []any{"method", req.Method, "url", req.URL.String()}
+	if logging.Enabled(slog.LevelDebug) && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			attrs = append(attrs, "body", c.redact(string(data)))
+		}
+	}
+	if logging.Enabled(logging.LevelTrace) {
+		attrs = append(attrs, "headers", c.redactHeaders(req.Header))
+	}
+	slog.DebugContext(ctx, "API request", attrs...)
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
+	latency := time.Since(start)
 	if err != nil {
+		c.metrics.apiRequest(0)
+		slog.DebugContext(ctx, "API request failed", "url", req.URL.String(), "latency", latency, "error", err)
 		return nil, fmt.Errorf("failed to execute HTTP request: %w", err)
 	}
 
+	c.metrics.apiRequest(resp.StatusCode)
+	attrs = []any{"status", resp.StatusCode, "latency", latency}
+	for _, name := range rateLimitHeaders {
+		if v := resp.Header.Get(name); v != "" {
+			attrs = append(attrs, strings.ToLower(name), v)
+		}
+	}
+	slog.DebugContext(ctx, "API response", attrs...)
+
 	// Check status code
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		// Read response body and get error details
-		body := make([]byte, 1024)
-		n, _ := resp.Body.Read(body)
-		bodyStr := string(body[:n])
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	}
 
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, bodyStr)
+	if logging.Enabled(logging.LevelTrace) {
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		slog.Log(ctx, logging.LevelTrace, "API response body", "headers", c.redactHeaders(resp.Header), "body", c.redact(string(body)))
+		resp.Body = io.NopCloser(bytes.NewReader(body))
 	}
 
 	return resp, nil
 }
 
+// redactHeaders returns the headers with credentials replaced
+func (c *Client) redactHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for name, values := range h {
+		switch strings.ToLower(name) {
+		case "dd-api-key", "dd-application-key", "authorization", "proxy-authorization", "cookie", "set-cookie":
+			headers[name] = "REDACTED"
+		default:
+			headers[name] = c.redact(strings.Join(values, ", "))
+		}
+	}
+	return headers
+}
+
+// minRedactLength is the shortest key that is redacted from logged bodies.
+// Real keys are 32 or 40 characters; replacing very short values would
+// mangle unrelated text.
+const minRedactLength = 8
+
+// redact replaces any occurrence of the configured keys in s
+func (c *Client) redact(s string) string {
+	for _, key := range []string{c.config.GetAPIKey(), c.config.GetAppKey()} {
+		if len(key) >= minRedactLength {
+			s = strings.ReplaceAll(s, key, "REDACTED")
+		}
+	}
+	return s
+}
+
 // GetBaseURL returns the base URL
 func (c *Client) GetBaseURL() string {
 	return c.baseURL
@@ -82,3 +223,38 @@ func (c *Client) GetBaseURL() string {
 func (c *Client) GetConfig() *config.Config {
 	return c.config
 }
+
+// Metrics returns the registry holding the client's metrics
+func (c *Client) Metrics() *metrics.Registry {
+	return c.registry
+}
+
+// SetAlertEngine sets the alerting engine evaluated on each tailed log entry
+func (c *Client) SetAlertEngine(engine *alert.Engine) {
+	c.alerts = engine
+}
+
+// AddSink adds a sink that every emitted log entry is forwarded to
+func (c *Client) AddSink(s sink.Sink) {
+	c.sinks = append(c.sinks, s)
+}
+
+// DisableStdout stops printing logs to stdout. Sinks and alerting rules
+// still receive them.
+func (c *Client) DisableStdout() {
+	c.noStdout = true
+}
+
+// Close flushes and closes all sinks and waits for running alert actions
+func (c *Client) Close() error {
+	var firstErr error
+	for _, s := range c.sinks {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close sink: %w", err)
+		}
+	}
+	if c.alerts != nil {
+		c.alerts.Wait()
+	}
+	return firstErr
+}
@@ -1,13 +1,18 @@
 package datadog
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/jedipunkz/datadog-log-tail/internal/config"
+	"github.com/jedipunkz/datadog-log-tail/internal/logging"
 )
 
 func TestNewClient(t *testing.T) {
@@ -50,6 +55,23 @@ func TestNewClient_CustomSite(t *testing.T) {
 	}
 }
 
+func TestNewClient_APIURL(t *testing.T) {
+	cfg := &config.Config{
+		Timeout: 30,
+		Site:    "datadoghq.com",
+		APIURL:  "http://localhost:8080",
+	}
+
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if client.GetBaseURL() != "http://localhost:8080" {
+		t.Errorf("BaseURL = %v, want http://localhost:8080", client.GetBaseURL())
+	}
+}
+
 func TestClient_createRequest(t *testing.T) {
 	cfg := &config.Config{
 		APIKey:  "test-api-key",
@@ -176,3 +198,50 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestClient_doRequest_Logging(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(logging.NewHandler(&buf, logging.LevelTrace, false)))
+	defer slog.SetDefault(previous)
+
+	client := &Client{
+		config:     &config.Config{APIKey: "secret-api-key", AppKey: "secret-app-key"},
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+	}
+
+	req, err := client.createRequest(context.Background(), "POST", "/api/v2/logs/events/search")
+	if err != nil {
+		t.Fatalf("createRequest() error = %v", err)
+	}
+	setBody(req, []byte(`{"filter":{"query":"service:web"}}`))
+
+	resp, err := client.doRequest(req)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if string(body) != `{"data":[]}` {
+		t.Errorf("response body = %q, want it to be readable after logging", body)
+	}
+
+	got := buf.String()
+	for _, want := range []string{`msg="API request"`, `service:web`, `status=200`, `latency=`, `x-ratelimit-remaining=42`, `msg="API response body"`, `REDACTED`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "secret-api-key") || strings.Contains(got, "secret-app-key") {
+		t.Errorf("log output leaks API keys:\n%s", got)
+	}
+}
@@ -0,0 +1,306 @@
+package datadog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+const (
+	// contextWindow bounds how far from a match context logs are searched
+	contextWindow = 5 * time.Minute
+	// maxContextPages bounds the pages read per context search
+	maxContextPages = 10
+	// maxContextRequests bounds the context searches per poll or batch
+	maxContextRequests = 20
+	// contextPageSize is the page size of context searches
+	contextPageSize = 1000
+	// contextSeparator separates groups of context lines, as in grep
+	contextSeparator = "--"
+)
+
+// Context line markers
+const (
+	markMatch  = "match"
+	markBefore = "before"
+	markAfter  = "after"
+)
+
+// markedLog is a log entry marked as a match or a context line in JSON output
+type markedLog struct {
+	LogEntry
+	Context string `json:"context"`
+}
+
+// contextPrinter tracks context output across matches so that a log is
+// printed at most once and groups are separated. It holds the context lines
+// loaded for the matches being emitted.
+type contextPrinter struct {
+	printed *recentIDs
+	groups  int
+	lines   map[string]contextLines
+}
+
+// contextLines are the logs before and after a match, oldest first
+type contextLines struct {
+	before []v2Log
+	after  []v2Log
+}
+
+// contextGroup is a time span around matches from the same host and service
+// whose context is loaded with one search
+type contextGroup struct {
+	host    string
+	service string
+	from    time.Time
+	to      time.Time
+	matches []LogEntry
+}
+
+func newContextPrinter() *contextPrinter {
+	return &contextPrinter{printed: newRecentIDs(maxRecentIDs, nil)}
+}
+
+// contextEnabled reports whether context lines were requested
+func (c *Client) contextEnabled() bool {
+	return c.config.GetContextBefore() > 0 || c.config.GetContextAfter() > 0
+}
+
+// emitMatch emits a matching log entry, surrounded by the context lines
+// loaded by loadContext if requested. matches holds the IDs of the other
+// matching logs being emitted, which are not repeated as context lines.
+func (c *Client) emitMatch(formatter output.Formatter, log LogEntry, matches map[string]bool, cp *contextPrinter, flush bool) {
+	if !c.contextEnabled() {
+		c.emit(formatter, log, flush)
+		return
+	}
+
+	lines := cp.lines[log.ID]
+	// JSON output marks context lines with the context field instead
+	if _, ok := formatter.(*output.TextFormatter); ok && !c.noStdout && cp.groups > 0 {
+		fmt.Println(contextSeparator)
+	}
+	cp.groups++
+
+	cp.printed.add(log.ID)
+	for _, d := range lines.before {
+		c.printContext(formatter, c.toLogEntry(d), markBefore, matches, cp, flush)
+	}
+	c.print(formatter, log, markMatch, flush)
+	c.forward(log)
+	for _, d := range lines.after {
+		c.printContext(formatter, c.toLogEntry(d), markAfter, matches, cp, flush)
+	}
+}
+
+// loadContext loads the context lines of the matches about to be emitted.
+// Matches from the same host and service whose context windows overlap
+// share one search, and the searches per call are bounded; matches beyond
+// the bound are emitted without context.
+func (c *Client) loadContext(ctx context.Context, logs []LogEntry, cp *contextPrinter) {
+	cp.lines = nil
+	if !c.contextEnabled() || len(logs) == 0 {
+		return
+	}
+	cp.lines = make(map[string]contextLines, len(logs))
+
+	budget := maxContextRequests
+	for _, g := range contextGroups(logs, contextWindow) {
+		if budget == 0 {
+			slog.Warn("Too many context searches; remaining matches are shown without context",
+				"max_requests", maxContextRequests)
+			return
+		}
+		data, requests, err := c.fetchContext(ctx, g, budget)
+		budget -= requests
+		if err != nil {
+			slog.Warn("Failed to fetch context logs", "host", g.host, "service", g.service, "error", err)
+		}
+		for _, m := range g.matches {
+			cp.lines[m.ID] = contextAround(data, m, c.config.GetContextBefore(), c.config.GetContextAfter(), contextWindow)
+		}
+	}
+}
+
+// contextGroups groups matches by host and service and merges the context
+// windows of matches within a group where they overlap
+func contextGroups(logs []LogEntry, window time.Duration) []*contextGroup {
+	sorted := append([]LogEntry(nil), logs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp < sorted[j].Timestamp })
+
+	var groups []*contextGroup
+	last := make(map[[2]string]*contextGroup)
+	for _, log := range sorted {
+		// The service falls back to the host when a log has none
+		service := log.Service
+		if service == log.Host {
+			service = ""
+		}
+		ts := time.Unix(log.Timestamp, 0)
+		from, to := ts.Add(-window), ts.Add(window+time.Second)
+
+		key := [2]string{log.Host, service}
+		if g := last[key]; g != nil && !from.After(g.to) {
+			g.to = to
+			g.matches = append(g.matches, log)
+			continue
+		}
+		g := &contextGroup{host: log.Host, service: service, from: from, to: to, matches: []LogEntry{log}}
+		last[key] = g
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// fetchContext returns the logs of a context group, oldest first, using at
+// most budget requests. It also returns the number of requests made.
+func (c *Client) fetchContext(ctx context.Context, g *contextGroup, budget int) ([]v2Log, int, error) {
+	sr := searchRequest{
+		Query: contextQuery(g.host, g.service),
+		From:  g.from,
+		To:    g.to,
+		Limit: contextPageSize,
+		Sort:  "timestamp",
+	}
+	var data []v2Log
+	for requests := 1; requests <= budget; requests++ {
+		resp, err := c.searchV2(ctx, sr)
+		if err != nil {
+			return data, requests, err
+		}
+		data = append(data, resp.Data...)
+		if resp.Meta.Page.After == "" {
+			return data, requests, nil
+		}
+		sr.Cursor = resp.Meta.Page.After
+	}
+	return data, budget, nil
+}
+
+// contextAround returns up to before and after logs around a match within
+// window from the time-ordered logs of its group. Logs are located by their
+// position next to the match, since timestamps in the same second cannot be
+// ordered by the search filter; if the match is not among the logs, the
+// nearest logs by timestamp are used.
+func contextAround(data []v2Log, match LogEntry, before, after int, window time.Duration) contextLines {
+	ts := time.Unix(match.Timestamp, 0)
+	times := make([]time.Time, len(data))
+	pos := -1
+	for i, d := range data {
+		times[i], _ = time.Parse(time.RFC3339Nano, d.Attrs.Timestamp)
+		if d.ID == match.ID {
+			pos = i
+		}
+	}
+
+	// first and next delimit the logs before and after the match
+	first, next := pos, pos+1
+	if pos < 0 {
+		first = sort.Search(len(data), func(i int) bool { return !times[i].Before(ts) })
+		next = sort.Search(len(data), func(i int) bool { return times[i].After(ts) })
+	}
+
+	var lines contextLines
+	for i := first - 1; i >= 0 && len(lines.before) < before && !times[i].Before(ts.Add(-window)); i-- {
+		lines.before = append([]v2Log{data[i]}, lines.before...)
+	}
+	for i := next; i < len(data) && len(lines.after) < after && !times[i].After(ts.Add(window)); i++ {
+		lines.after = append(lines.after, data[i])
+	}
+	return lines
+}
+
+// printContext prints a context line unless it was already printed or is a
+// match itself
+func (c *Client) printContext(formatter output.Formatter, log LogEntry, mark string, matches map[string]bool, cp *contextPrinter, flush bool) {
+	if matches[log.ID] || !cp.printed.add(log.ID) {
+		return
+	}
+	c.print(formatter, log, mark, flush)
+}
+
+// surroundingLogs returns up to before and after logs from the same host and
+// service around the log with the given ID, oldest first. Logs are located
+// by their position next to the log in the search results, since timestamps
+// in the same second cannot be ordered by the search filter.
+func (c *Client) surroundingLogs(ctx context.Context, id string, ts time.Time, host, service string, before, after int, window time.Duration) ([]v2Log, []v2Log, error) {
+	query := contextQuery(host, service)
+	second := ts.Truncate(time.Second)
+
+	var b, a []v2Log
+	if before > 0 {
+		var err error
+		b, err = c.collectAround(ctx, searchRequest{
+			Query: query,
+			From:  second.Add(-window),
+			To:    second.Add(time.Second),
+			Sort:  "-timestamp",
+		}, id, before, func(t time.Time) bool { return t.Before(ts) })
+		if err != nil {
+			return nil, nil, err
+		}
+		// Oldest first
+		for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+	}
+	if after > 0 {
+		var err error
+		a, err = c.collectAround(ctx, searchRequest{
+			Query: query,
+			From:  second,
+			To:    ts.Add(window),
+			Sort:  "timestamp",
+		}, id, after, func(t time.Time) bool { return t.After(ts) })
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return b, a, nil
+}
+
+// collectAround returns up to n logs that follow the log with the given ID
+// in the search results. If the log is not in the results, the first n logs
+// whose timestamp is accepted by fallback are returned instead.
+func (c *Client) collectAround(ctx context.Context, sr searchRequest, id string, n int, fallback func(time.Time) bool) ([]v2Log, error) {
+	var found bool
+	var following, nearest []v2Log
+	sr.Limit = tailPageSize
+
+	for page := 0; page < maxContextPages; page++ {
+		resp, err := c.searchV2(ctx, sr)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range resp.Data {
+			if d.ID == id {
+				found = true
+				continue
+			}
+			if found {
+				following = append(following, d)
+				if len(following) == n {
+					return following, nil
+				}
+				continue
+			}
+			ts, _ := time.Parse(time.RFC3339Nano, d.Attrs.Timestamp)
+			if len(nearest) < n && fallback(ts) {
+				nearest = append(nearest, d)
+			}
+		}
+		if resp.Meta.Page.After == "" {
+			break
+		}
+		sr.Cursor = resp.Meta.Page.After
+	}
+
+	if found {
+		return following, nil
+	}
+	return nearest, nil
+}
@@ -0,0 +1,332 @@
+package datadog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+// captureStdout returns what fn writes to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	fn()
+	_ = w.Close()
+	return <-done
+}
+
+// contextServer serves v2 searches from a fixed, time-ordered list of logs
+func contextServer(t *testing.T, logs []map[string]interface{}) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Sort string `json:"sort"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		data := append([]map[string]interface{}(nil), logs...)
+		if body.Sort == "-timestamp" {
+			for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+				data[i], data[j] = data[j], data[i]
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+}
+
+func TestClient_surroundingLogs(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	// b1..b3 and a1 share the second of the match
+	server := contextServer(t, []map[string]interface{}{
+		v2TestLog("old", base.Add(-2*time.Second), "old"),
+		v2TestLog("b1", base, "b1"),
+		v2TestLog("b2", base, "b2"),
+		v2TestLog("match", base, "match"),
+		v2TestLog("a1", base, "a1"),
+		v2TestLog("a2", base.Add(time.Second), "a2"),
+		v2TestLog("a3", base.Add(2*time.Second), "a3"),
+	})
+	defer server.Close()
+
+	client := &Client{config: &config.Config{}, httpClient: server.Client(), baseURL: server.URL}
+
+	ids := func(logs []v2Log) string {
+		var out []string
+		for _, l := range logs {
+			out = append(out, l.ID)
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		before     int
+		after      int
+		wantBefore string
+		wantAfter  string
+	}{
+		{"Position of the match", "match", 2, 2, "b1,b2", "a1,a2"},
+		{"More than available", "match", 5, 5, "old,b1,b2", "a1,a2,a3"},
+		{"After only", "match", 0, 1, "", "a1"},
+		{"Match not in results", "missing", 1, 1, "old", "a2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after, err := client.surroundingLogs(context.Background(), tt.id, base, "web-1", "api", tt.before, tt.after, time.Minute)
+			if err != nil {
+				t.Fatalf("surroundingLogs() error = %v", err)
+			}
+			if got := ids(before); got != tt.wantBefore {
+				t.Errorf("before = %v, want %v", got, tt.wantBefore)
+			}
+			if got := ids(after); got != tt.wantAfter {
+				t.Errorf("after = %v, want %v", got, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestClient_emitMatch(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	server := contextServer(t, []map[string]interface{}{
+		v2TestLog("c1", base.Add(-time.Second), "connecting"),
+		v2TestLog("e1", base, "first error"),
+		v2TestLog("c2", base.Add(time.Second), "retrying"),
+		v2TestLog("e2", base.Add(2*time.Second), "second error"),
+		v2TestLog("c3", base.Add(3*time.Second), "gave up"),
+	})
+	defer server.Close()
+
+	sink := &recordingSink{}
+	client := &Client{
+		config:     &config.Config{ContextBefore: 1, ContextAfter: 1},
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+	}
+	client.AddSink(sink)
+
+	matches := []LogEntry{
+		{ID: "e1", Timestamp: base.Unix(), Message: "first error", Service: "api", Status: "error", Host: "web-1"},
+		{ID: "e2", Timestamp: base.Add(2 * time.Second).Unix(), Message: "second error", Service: "api", Status: "error", Host: "web-1"},
+	}
+
+	got := captureStdout(t, func() {
+		client.deliver(context.Background(), output.NewFormatter("text"), matches, newRecentIDs(maxRecentIDs, nil), newContextPrinter())
+	})
+
+	lines := strings.Split(strings.TrimRight(got, "\n"), "\n")
+	want := []string{
+		"  [", // c1
+		"> [", // e1
+		"  [", // c2
+		"--",
+		"> [", // e2 (c2 is not repeated)
+		"  [", // c3
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("output has %d lines, want %d:\n%s", len(lines), len(want), got)
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("line %d = %q, want prefix %q", i, lines[i], prefix)
+		}
+	}
+	if !strings.Contains(lines[1], "first error") || !strings.Contains(lines[5], "gave up") {
+		t.Errorf("unexpected output:\n%s", got)
+	}
+
+	// Only matches are forwarded to sinks
+	if len(sink.logs) != 2 {
+		t.Errorf("sink received %d logs, want 2 matches", len(sink.logs))
+	}
+}
+
+func TestClient_print_JSONMarks(t *testing.T) {
+	client := &Client{config: &config.Config{}}
+	got := captureStdout(t, func() {
+		client.print(output.NewFormatter("json"), LogEntry{ID: "c1", Message: "context"}, markBefore, false)
+	})
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+		t.Fatalf("output is not JSON: %v (%s)", err, got)
+	}
+	if decoded["context"] != "before" || decoded["id"] != "c1" {
+		t.Errorf("decoded = %v, want id and context fields", decoded)
+	}
+}
+
+func TestClient_emitMatch_Separator(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	server := contextServer(t, []map[string]interface{}{
+		v2TestLog("e1", base, "first error"),
+		v2TestLog("e2", base.Add(time.Minute), "second error"),
+	})
+	defer server.Close()
+
+	matches := []LogEntry{
+		{ID: "e1", Timestamp: base.Unix(), Service: "api", Host: "web-1"},
+		{ID: "e2", Timestamp: base.Add(time.Minute).Unix(), Service: "api", Host: "web-1"},
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		noStdout bool
+		want     int
+	}{
+		{"JSON output is NDJSON", "json", false, 2},
+		{"Nothing without stdout", "text", true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{
+				config:     &config.Config{ContextAfter: 1},
+				httpClient: server.Client(),
+				baseURL:    server.URL,
+				noStdout:   tt.noStdout,
+			}
+			got := captureStdout(t, func() {
+				client.deliver(context.Background(), output.NewFormatter(tt.format), matches, newRecentIDs(maxRecentIDs, nil), newContextPrinter())
+			})
+
+			var lines []string
+			if got != "" {
+				lines = strings.Split(strings.TrimRight(got, "\n"), "\n")
+			}
+			if len(lines) != tt.want {
+				t.Fatalf("output has %d lines, want %d:\n%s", len(lines), tt.want, got)
+			}
+			for _, line := range lines {
+				if !json.Valid([]byte(line)) {
+					t.Errorf("line %q is not JSON", line)
+				}
+			}
+		})
+	}
+}
+
+func TestContextAround(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	var data []v2Log
+	for _, l := range []struct {
+		id string
+		ts time.Time
+	}{
+		{"far", base.Add(-2 * time.Minute)},
+		{"old", base.Add(-2 * time.Second)},
+		{"b1", base},
+		{"match", base},
+		{"a1", base},
+		{"a2", base.Add(time.Second)},
+		{"late", base.Add(2 * time.Minute)},
+	} {
+		var d v2Log
+		d.ID = l.id
+		d.Attrs.Timestamp = l.ts.Format(time.RFC3339Nano)
+		data = append(data, d)
+	}
+
+	ids := func(logs []v2Log) string {
+		var out []string
+		for _, l := range logs {
+			out = append(out, l.ID)
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		before     int
+		after      int
+		wantBefore string
+		wantAfter  string
+	}{
+		{"Position of the match", "match", 2, 2, "old,b1", "a1,a2"},
+		{"Bounded by the window", "match", 5, 5, "old,b1", "a1,a2"},
+		{"After only", "match", 0, 1, "", "a1"},
+		{"Match not in results", "missing", 1, 1, "old", "a2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := contextAround(data, LogEntry{ID: tt.id, Timestamp: base.Unix()}, tt.before, tt.after, time.Minute)
+			if ids(got.before) != tt.wantBefore {
+				t.Errorf("before = %v, want %v", ids(got.before), tt.wantBefore)
+			}
+			if ids(got.after) != tt.wantAfter {
+				t.Errorf("after = %v, want %v", ids(got.after), tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestClient_loadContext_Requests(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": []interface{}{}})
+	}))
+	defer server.Close()
+
+	client := &Client{
+		config:     &config.Config{ContextBefore: 2, ContextAfter: 2},
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+	}
+
+	// Close matches on one host share a search; an hour apart they do not
+	var sameHost []LogEntry
+	for i := 0; i < 100; i++ {
+		sameHost = append(sameHost, LogEntry{ID: fmt.Sprint(i), Timestamp: base.Add(time.Duration(i) * time.Second).Unix(), Service: "api", Host: "web-1"})
+	}
+	sameHost = append(sameHost, LogEntry{ID: "later", Timestamp: base.Add(time.Hour).Unix(), Service: "api", Host: "web-1"})
+
+	var manyHosts []LogEntry
+	for i := 0; i < 100; i++ {
+		manyHosts = append(manyHosts, LogEntry{ID: fmt.Sprint(i), Timestamp: base.Unix(), Service: "api", Host: fmt.Sprintf("web-%d", i)})
+	}
+
+	tests := []struct {
+		name string
+		logs []LogEntry
+		want int
+	}{
+		{"Matches on one host", sameHost, 2},
+		{"Bounded per batch", manyHosts, maxContextRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			client.loadContext(context.Background(), tt.logs, newContextPrinter())
+			if requests != tt.want {
+				t.Errorf("requests = %d, want %d", requests, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package datadog
+
+// maxRecentIDs is the number of log IDs remembered for deduplication
+const maxRecentIDs = 1000
+
+// recentIDs remembers the most recently seen log IDs up to a fixed capacity
+type recentIDs struct {
+	ids      map[string]struct{}
+	order    []string
+	capacity int
+}
+
+// newRecentIDs creates a new ID set seeded with previously seen IDs
+func newRecentIDs(capacity int, initial []string) *recentIDs {
+	r := &recentIDs{
+		ids:      make(map[string]struct{}, capacity),
+		capacity: capacity,
+	}
+	for _, id := range initial {
+		r.add(id)
+	}
+	return r
+}
+
+// add records an ID and reports whether it had not been seen before
+func (r *recentIDs) add(id string) bool {
+	if id == "" {
+		return true
+	}
+	if _, ok := r.ids[id]; ok {
+		return false
+	}
+	r.ids[id] = struct{}{}
+	r.order = append(r.order, id)
+	if len(r.order) > r.capacity {
+		delete(r.ids, r.order[0])
+		r.order = r.order[1:]
+	}
+	return true
+}
+
+// list returns the remembered IDs from oldest to newest
+func (r *recentIDs) list() []string {
+	return append([]string(nil), r.order...)
+}
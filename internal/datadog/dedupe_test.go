@@ -0,0 +1,42 @@
+package datadog
+
+import (
+	"testing"
+)
+
+func TestRecentIDs(t *testing.T) {
+	r := newRecentIDs(3, []string{"a"})
+
+	if r.add("a") {
+		t.Error("add(a) = true, want false for a seeded ID")
+	}
+	if !r.add("b") || !r.add("c") {
+		t.Error("add() = false, want true for new IDs")
+	}
+	if r.add("b") {
+		t.Error("add(b) = true, want false for a duplicate")
+	}
+	if !r.add("") {
+		t.Error("add(\"\") = false, want true for entries without ID")
+	}
+
+	// Adding a fourth ID evicts the oldest one
+	if !r.add("d") {
+		t.Error("add(d) = false, want true")
+	}
+	if !r.add("a") {
+		t.Error("add(a) = false, want true after eviction")
+	}
+
+	got := r.list()
+	want := []string{"c", "d", "a"}
+	if len(got) != len(want) {
+		t.Fatalf("list() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("list() = %v, want %v", got, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package datadog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// FacetValue is a value of a facet and the number of logs having it
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// aggregateResponse is a Logs API v2 aggregate response with a count
+// computed per value of one facet
+// https://docs.datadoghq.com/api/latest/logs/#aggregate-events
+type aggregateResponse struct {
+	Data struct {
+		Buckets []struct {
+			By       map[string]interface{} `json:"by"`
+			Computes map[string]interface{} `json:"computes"`
+		} `json:"buckets"`
+	} `json:"data"`
+}
+
+// FacetValues returns the most frequent values of a facet (e.g. service or
+// env) among the logs between from and to matching query, most frequent
+// first
+func (c *Client) FacetValues(ctx context.Context, facet, query string, from, to time.Time, limit int) ([]FacetValue, error) {
+	endpoint := "/api/v2/logs/analytics/aggregate"
+
+	if query == "" {
+		query = "*"
+	}
+	body := map[string]interface{}{
+		"compute": []map[string]interface{}{
+			{"aggregation": "count", "type": "total"},
+		},
+		"filter": map[string]interface{}{
+			"from":  from.UTC().Format(time.RFC3339),
+			"to":    to.UTC().Format(time.RFC3339),
+			"query": query,
+		},
+		"group_by": []map[string]interface{}{
+			{"facet": facet, "limit": limit, "sort": map[string]interface{}{"aggregation": "count", "order": "desc"}},
+		},
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := c.createRequest(ctx, "POST", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	setBody(req, jsonBody)
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var aggResp aggregateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&aggResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	values := make([]FacetValue, 0, len(aggResp.Data.Buckets))
+	for _, b := range aggResp.Data.Buckets {
+		v, ok := scalarString(b.By[facet])
+		if !ok || v == "" {
+			continue
+		}
+		var count int64
+		for _, compute := range b.Computes {
+			if n, ok := compute.(float64); ok {
+				count = int64(n)
+				break
+			}
+		}
+		values = append(values, FacetValue{Value: v, Count: count})
+	}
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Count > values[j].Count })
+
+	slog.DebugContext(ctx, "Fetched facet values", "facet", facet, "values", len(values))
+	return values, nil
+}
@@ -0,0 +1,56 @@
+package datadog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+)
+
+func TestClient_FacetValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/logs/analytics/aggregate" {
+			t.Errorf("path = %s, want the aggregate endpoint", r.URL.Path)
+		}
+		var body struct {
+			Filter struct {
+				Query string `json:"query"`
+			} `json:"filter"`
+			GroupBy []struct {
+				Facet string `json:"facet"`
+				Limit int    `json:"limit"`
+			} `json:"group_by"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Filter.Query != "*" {
+			t.Errorf("query = %q, want *", body.Filter.Query)
+		}
+		if len(body.GroupBy) != 1 || body.GroupBy[0].Facet != "service" || body.GroupBy[0].Limit != 50 {
+			t.Errorf("group_by = %+v, want service limited to 50", body.GroupBy)
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"buckets":[
+			{"by":{"service":"web"},"computes":{"c0":12}},
+			{"by":{"service":""},"computes":{"c0":99}},
+			{"by":{"service":"payments"},"computes":{"c0":40}}
+		]}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{config: &config.Config{}, httpClient: server.Client(), baseURL: server.URL}
+	now := time.Now()
+	values, err := client.FacetValues(context.Background(), "service", "", now.Add(-time.Hour), now, 50)
+	if err != nil {
+		t.Fatalf("FacetValues() error = %v", err)
+	}
+
+	want := []FacetValue{{Value: "payments", Count: 40}, {Value: "web", Count: 12}}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("FacetValues() = %+v, want %+v", values, want)
+	}
+}
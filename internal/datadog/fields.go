@@ -0,0 +1,87 @@
+package datadog
+
+import (
+	"strings"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+)
+
+// noMessage is shown when none of the message paths has a value
+const noMessage = "No message content"
+
+// mappedFields are the log entry fields extracted with a field map
+type mappedFields struct {
+	Message string
+	Service string
+	Status  string
+	Host    string
+	Source  string
+	TraceID string
+	SpanID  string
+}
+
+// mapFields extracts the log entry fields from the reserved fields and the
+// custom attributes of a log. This is the single place where the field map
+// is applied, for v1 and v2 API responses alike.
+func mapFields(fm config.FieldMap, reserved map[string]string, attrs map[string]interface{}) mappedFields {
+	f := mappedFields{
+		Message: resolveField(fm.Message, reserved, attrs),
+		Service: resolveField(fm.Service, reserved, attrs),
+		Status:  resolveField(fm.Status, reserved, attrs),
+		Host:    resolveField(fm.Host, reserved, attrs),
+		Source:  resolveField(fm.Source, reserved, attrs),
+		TraceID: resolveField(fm.TraceID, reserved, attrs),
+		SpanID:  resolveField(fm.SpanID, reserved, attrs),
+	}
+	if f.Message == "" {
+		f.Message = noMessage
+	}
+	return f
+}
+
+// resolveField returns the value of the first path with a non-empty value.
+// @-prefixed paths are custom attributes; bare names are reserved fields,
+// falling back to the custom attribute with that name.
+func resolveField(paths []string, reserved map[string]string, attrs map[string]interface{}) string {
+	for _, path := range paths {
+		if attr, ok := strings.CutPrefix(path, "@"); ok {
+			if v := attributeString(attrs, attr); v != "" {
+				return v
+			}
+			continue
+		}
+		if v := reserved[path]; v != "" {
+			return v
+		}
+		if v := attributeString(attrs, path); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Field returns the value of a field of the log entry by name: a log entry
+// field (message, service, status, host, source, trace_id, span_id), an
+// @-prefixed attribute path or the value of a key:value tag
+func (l LogEntry) Field(name string) string {
+	switch name {
+	case "message":
+		return l.Message
+	case "service":
+		return l.Service
+	case "status":
+		return l.Status
+	case "host":
+		return l.Host
+	case "source":
+		return l.Source
+	case "trace_id":
+		return l.TraceID
+	case "span_id":
+		return l.SpanID
+	}
+	if attr, ok := strings.CutPrefix(name, "@"); ok {
+		return attributeString(l.Attributes, attr)
+	}
+	return tagValue(l.Tags, name)
+}
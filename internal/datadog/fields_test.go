@@ -0,0 +1,141 @@
+package datadog
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+)
+
+func TestMapFields(t *testing.T) {
+	attrs := map[string]interface{}{
+		"msg":      "charge failed",
+		"severity": "error",
+		"http":     map[string]interface{}{"status_code": float64(502)},
+		"dd":       map[string]interface{}{"trace_id": json.Number("4242")},
+		"empty":    "",
+	}
+	reserved := map[string]string{"message": "raw message", "host": "web-1", "level": "warn"}
+
+	tests := []struct {
+		name    string
+		paths   []string
+		want    string
+		message bool
+	}{
+		{"Reserved field", []string{"message"}, "raw message", false},
+		{"Attribute first", []string{"@msg", "message"}, "charge failed", false},
+		{"Nested attribute path", []string{"@http.status_code"}, "502", false},
+		{"Skips empty values", []string{"@empty", "status", "level"}, "warn", false},
+		{"Bare name falls back to attribute", []string{"severity"}, "error", false},
+		{"json.Number attribute", []string{"@dd.trace_id"}, "4242", false},
+		{"No value", []string{"@missing"}, "", false},
+		{"Message placeholder", []string{"@missing"}, noMessage, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm := config.FieldMap{Status: tt.paths}
+			if tt.message {
+				fm = config.FieldMap{Message: tt.paths}
+			}
+			f := mapFields(fm, reserved, attrs)
+			got := f.Status
+			if tt.message {
+				got = f.Message
+			}
+			if got != tt.want {
+				t.Errorf("mapped value = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestV2Log_toLogEntry_DefaultFieldMap(t *testing.T) {
+	tests := []struct {
+		name        string
+		attrs       v2LogAttributes
+		wantMessage string
+		wantService string
+		wantStatus  string
+	}{
+		{
+			name:        "Reserved fields",
+			attrs:       v2LogAttributes{Message: "hello", Service: "api", Status: "info", Host: "web-1"},
+			wantMessage: "hello",
+			wantService: "api",
+			wantStatus:  "info",
+		},
+		{
+			name:        "Alternative fields",
+			attrs:       v2LogAttributes{Text: "from text", Host: "web-1", LogLevel: "warn"},
+			wantMessage: "from text",
+			wantService: "web-1",
+			wantStatus:  "warn",
+		},
+		{
+			name:        "No message",
+			attrs:       v2LogAttributes{Service: "api"},
+			wantMessage: noMessage,
+			wantService: "api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := v2Log{ID: "1", Attrs: tt.attrs}.toLogEntry(config.DefaultFieldMap())
+			if log.Message != tt.wantMessage || log.Service != tt.wantService || log.Status != tt.wantStatus {
+				t.Errorf("toLogEntry() = (%q, %q, %q), want (%q, %q, %q)",
+					log.Message, log.Service, log.Status, tt.wantMessage, tt.wantService, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestClient_toLogEntry_FieldMap(t *testing.T) {
+	d := v2Log{ID: "1", Attrs: v2LogAttributes{
+		Message:    "raw",
+		Status:     "info",
+		Attributes: map[string]interface{}{"msg": "human message", "severity": "error"},
+	}}
+	cfg := &config.Config{FieldMap: config.FieldMap{
+		Message: []string{"@msg", "message"},
+		Status:  []string{"@severity", "status"},
+	}}
+	client := &Client{config: cfg}
+
+	log := client.toLogEntry(d)
+	if log.Message != "human message" || log.Status != "error" {
+		t.Errorf("toLogEntry() = (%q, %q), want (human message, error)", log.Message, log.Status)
+	}
+}
+
+func TestLogEntry_Field(t *testing.T) {
+	log := LogEntry{
+		Service:    "api",
+		Status:     "error",
+		Host:       "web-1",
+		Tags:       []string{"env:prod", "version:1.2"},
+		Attributes: map[string]interface{}{"http": map[string]interface{}{"status_code": float64(502)}},
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"service", "api"},
+		{"status", "error"},
+		{"host", "web-1"},
+		{"@http.status_code", "502"},
+		{"env", "prod"},
+		{"version", "1.2"},
+		{"@missing", ""},
+		{"region", ""},
+	}
+
+	for _, tt := range tests {
+		if got := log.Field(tt.name); got != tt.want {
+			t.Errorf("Field(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
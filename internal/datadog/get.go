@@ -0,0 +1,266 @@
+package datadog
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrLogNotFound is returned when a log ID cannot be found
+var ErrLogNotFound = errors.New("log not found")
+
+// idWindow is the search window around the timestamp encoded in a log ID
+const idWindow = time.Minute
+
+// Event is the full detail of a single log event
+type Event struct {
+	ID         string                 `json:"id" yaml:"id"`
+	Timestamp  string                 `json:"timestamp" yaml:"timestamp"`
+	Host       string                 `json:"host,omitempty" yaml:"host,omitempty"`
+	Service    string                 `json:"service,omitempty" yaml:"service,omitempty"`
+	Source     string                 `json:"source,omitempty" yaml:"source,omitempty"`
+	Status     string                 `json:"status,omitempty" yaml:"status,omitempty"`
+	TraceID    string                 `json:"trace_id,omitempty" yaml:"trace_id,omitempty"`
+	SpanID     string                 `json:"span_id,omitempty" yaml:"span_id,omitempty"`
+	Message    string                 `json:"message" yaml:"message"`
+	Tags       []string               `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+
+	time time.Time
+}
+
+// EventDetail is a log event with the surrounding logs from the same
+// host and service
+type EventDetail struct {
+	Event   `yaml:",inline"`
+	Context *EventContext `json:"context,omitempty" yaml:"context,omitempty"`
+}
+
+// EventContext holds the logs before and after an event, oldest first
+type EventContext struct {
+	Before []Event `json:"before" yaml:"before"`
+	After  []Event `json:"after" yaml:"after"`
+}
+
+// GetOptions controls how a log event is retrieved
+type GetOptions struct {
+	// Lookback is searched when the log ID does not encode a timestamp
+	Lookback time.Duration
+	// Before and After are the numbers of logs to include around the event
+	Before int
+	After  int
+	// ContextWindow bounds how far from the event context logs are searched
+	ContextWindow time.Duration
+}
+
+// v1 list API request and response structure
+// https://docs.datadoghq.com/api/latest/logs/#search-logs-get
+
+type v1ListRequest struct {
+	Query   string `json:"query"`
+	StartAt string `json:"startAt"`
+	Limit   int    `json:"limit"`
+	Sort    string `json:"sort"`
+	Time    struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"time"`
+}
+
+type v1ListResponse struct {
+	Logs []struct {
+		ID      string `json:"id"`
+		Content struct {
+			Timestamp  string                 `json:"timestamp"`
+			Host       string                 `json:"host"`
+			Service    string                 `json:"service"`
+			Message    string                 `json:"message"`
+			Tags       []string               `json:"tags"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"content"`
+	} `json:"logs"`
+}
+
+// GetLog retrieves a single log event by ID. The v2 API cannot look up
+// events by ID, so the v1 list API is used with startAt, searching a narrow
+// window around the timestamp encoded in the ID when possible.
+func (c *Client) GetLog(ctx context.Context, id string, opts GetOptions) (*EventDetail, error) {
+	to := time.Now()
+	from := to.Add(-opts.Lookback)
+	if ts, ok := idTimestamp(id); ok {
+		from, to = ts.Add(-idWindow), ts.Add(idWindow)
+	}
+
+	ev, err := c.fetchEventV1(ctx, id, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &EventDetail{Event: *ev}
+	if opts.Before > 0 || opts.After > 0 {
+		detail.Context, err = c.eventContext(ctx, ev, opts.Before, opts.After, opts.ContextWindow)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch context logs: %w", err)
+		}
+	}
+	return detail, nil
+}
+
+// fetchEventV1 fetches the event with the given ID from the v1 list API
+func (c *Client) fetchEventV1(ctx context.Context, id string, from, to time.Time) (*Event, error) {
+	body := v1ListRequest{Query: "*", StartAt: id, Limit: 1, Sort: "desc"}
+	body.Time.From = from.UTC().Format(time.RFC3339)
+	body.Time.To = to.UTC().Format(time.RFC3339)
+	jsonBody, _ := json.Marshal(body)
+
+	req, err := c.createRequest(ctx, "POST", "/api/v1/logs-queries/list")
+	if err != nil {
+		return nil, err
+	}
+	setBody(req, jsonBody)
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var v1resp v1ListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&v1resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	// startAt is ignored when the log is outside the window, in which case
+	// other logs are returned
+	for _, l := range v1resp.Logs {
+		if l.ID != id {
+			continue
+		}
+		ts, _ := time.Parse(time.RFC3339Nano, l.Content.Timestamp)
+		f := mapFields(c.config.GetFieldMap(), map[string]string{
+			"message": l.Content.Message,
+			"host":    l.Content.Host,
+			"service": l.Content.Service,
+			"source":  tagValue(l.Content.Tags, "source"),
+		}, l.Content.Attributes)
+		ev := &Event{
+			ID:         l.ID,
+			Timestamp:  l.Content.Timestamp,
+			Host:       f.Host,
+			Service:    f.Service,
+			Source:     f.Source,
+			Status:     f.Status,
+			TraceID:    f.TraceID,
+			SpanID:     f.SpanID,
+			Message:    f.Message,
+			Tags:       l.Content.Tags,
+			Attributes: l.Content.Attributes,
+			time:       ts,
+		}
+		if c.redactor != nil {
+			ev.Message = c.redactor.String(ev.Message)
+			ev.Tags = c.redactor.Strings(ev.Tags)
+			ev.Attributes = c.redactor.Map(ev.Attributes)
+		}
+		return ev, nil
+	}
+	return nil, fmt.Errorf("%w: %s (searched %s to %s)", ErrLogNotFound, id, body.Time.From, body.Time.To)
+}
+
+// eventContext fetches the logs before and after an event from the same
+// host and service
+func (c *Client) eventContext(ctx context.Context, ev *Event, before, after int, window time.Duration) (*EventContext, error) {
+	// The service falls back to the host when a log has none
+	service := ev.Service
+	if service == ev.Host {
+		service = ""
+	}
+	b, a, err := c.surroundingLogs(ctx, ev.ID, ev.time, ev.Host, service, before, after, window)
+	if err != nil {
+		return nil, err
+	}
+	return &EventContext{Before: c.toEvents(b), After: c.toEvents(a)}, nil
+}
+
+// toEvents maps v2 logs to events
+func (c *Client) toEvents(logs []v2Log) []Event {
+	events := make([]Event, 0, len(logs))
+	for _, d := range logs {
+		events = append(events, c.toEvent(d))
+	}
+	return events
+}
+
+// toEvent maps a v2 log to an event
+func (c *Client) toEvent(d v2Log) Event {
+	ts, _ := time.Parse(time.RFC3339Nano, d.Attrs.Timestamp)
+	log := c.toLogEntry(d)
+	return Event{
+		ID:         d.ID,
+		Timestamp:  d.Attrs.Timestamp,
+		Host:       log.Host,
+		Service:    log.Service,
+		Source:     log.Source,
+		Status:     log.Status,
+		TraceID:    log.TraceID,
+		SpanID:     log.SpanID,
+		Message:    log.Message,
+		Tags:       log.Tags,
+		Attributes: log.Attributes,
+		time:       ts,
+	}
+}
+
+// contextQuery builds a search query matching the given host and service
+func contextQuery(host, service string) string {
+	var conditions []string
+	if host != "" {
+		conditions = append(conditions, "host:"+quoteQueryValue(host))
+	}
+	if service != "" {
+		conditions = append(conditions, "service:"+quoteQueryValue(service))
+	}
+	if len(conditions) == 0 {
+		return "*"
+	}
+	return strings.Join(conditions, " ")
+}
+
+// quoteQueryValue quotes a search query value if it contains special characters
+func quoteQueryValue(v string) string {
+	if strings.ContainsAny(v, " \t:\"()") {
+		return `"` + strings.ReplaceAll(v, `"`, `\"`) + `"`
+	}
+	return v
+}
+
+// tagValue returns the value of the first key:value tag with the given key
+func tagValue(tags []string, key string) string {
+	for _, tag := range tags {
+		if k, v, ok := strings.Cut(tag, ":"); ok && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+// idTimestamp extracts the timestamp that Datadog encodes in log IDs: the
+// base64-decoded ID starts with a version byte (1) followed by the event
+// time in milliseconds (big-endian). IDs in another format report false.
+func idTimestamp(id string) (time.Time, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.NewReplacer("+", "-", "/", "_").Replace(id), "="))
+	if err != nil || len(b) < 9 || b[0] != 1 {
+		return time.Time{}, false
+	}
+	ts := time.UnixMilli(int64(binary.BigEndian.Uint64(b[1:9])))
+	// Reject values that cannot be a log timestamp
+	if ts.Year() < 2010 || ts.After(time.Now().Add(24*time.Hour)) {
+		return time.Time{}, false
+	}
+	return ts, true
+}
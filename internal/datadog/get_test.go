@@ -0,0 +1,236 @@
+package datadog
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// encodeLogID builds a log ID in Datadog's format for the given time
+func encodeLogID(ts time.Time, suffix byte) string {
+	b := make([]byte, 18)
+	b[0] = 1
+	binary.BigEndian.PutUint64(b[1:9], uint64(ts.UnixMilli()))
+	b[17] = suffix
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+func TestIDTimestamp(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 10, 0, 0, 123e6, time.UTC)
+
+	tests := []struct {
+		name   string
+		id     string
+		want   time.Time
+		wantOK bool
+	}{
+		{"Datadog log ID", encodeLogID(ts, 1), ts, true},
+		{"Real log ID", "AQAAAYnY8U1uAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA", time.UnixMilli(0x189D8F14D6E), true},
+		{"Not base64", "not a log id!", time.Time{}, false},
+		{"Too short", base64.StdEncoding.EncodeToString([]byte{1, 2, 3}), time.Time{}, false},
+		{"Unknown version", base64.StdEncoding.EncodeToString(make([]byte, 18)), time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := idTimestamp(tt.id)
+			if ok != tt.wantOK {
+				t.Fatalf("idTimestamp() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("idTimestamp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextQuery(t *testing.T) {
+	tests := []struct {
+		host, service string
+		want          string
+	}{
+		{"web-1", "api", "host:web-1 service:api"},
+		{"", "api", "service:api"},
+		{"my host", "", `host:"my host"`},
+		{"", "", "*"},
+	}
+	for _, tt := range tests {
+		if got := contextQuery(tt.host, tt.service); got != tt.want {
+			t.Errorf("contextQuery(%q, %q) = %v, want %v", tt.host, tt.service, got, tt.want)
+		}
+	}
+}
+
+// v2TestLog builds a v2 search result
+func v2TestLog(id string, ts time.Time, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"id": id,
+		"attributes": map[string]interface{}{
+			"timestamp": ts.Format(time.RFC3339Nano),
+			"message":   message,
+			"service":   "api",
+			"host":      "web-1",
+		},
+	}
+}
+
+func TestClient_GetLog(t *testing.T) {
+	eventTime := time.Date(2024, 1, 15, 10, 0, 0, 500e6, time.UTC)
+	id := encodeLogID(eventTime, 1)
+
+	var v1Body v1ListRequest
+	var contextQueries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/logs-queries/list":
+			_ = json.NewDecoder(r.Body).Decode(&v1Body)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"logs": []map[string]interface{}{{
+					"id": v1Body.StartAt,
+					"content": map[string]interface{}{
+						"timestamp": eventTime.Format(time.RFC3339Nano),
+						"host":      "web-1",
+						"service":   "api",
+						"message":   "payment failed",
+						"tags":      []string{"env:prod", "source:go"},
+						"attributes": map[string]interface{}{
+							"status": "error",
+							"http":   map[string]interface{}{"status_code": 502},
+						},
+					},
+				}},
+			})
+		case "/api/v2/logs/events/search":
+			var body struct {
+				Filter struct {
+					Query string `json:"query"`
+				} `json:"filter"`
+				Sort string `json:"sort"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			contextQueries = append(contextQueries, body.Filter.Query)
+
+			var data []map[string]interface{}
+			if body.Sort == "-timestamp" {
+				data = []map[string]interface{}{
+					v2TestLog(id, eventTime, "payment failed"),
+					v2TestLog("same", eventTime.Add(-100*time.Millisecond), "same second"),
+					v2TestLog("b2", eventTime.Add(-2*time.Second), "retrying"),
+					v2TestLog("b3", eventTime.Add(-3*time.Second), "connecting"),
+				}
+			} else {
+				data = []map[string]interface{}{
+					v2TestLog(id, eventTime, "payment failed"),
+					v2TestLog("a1", eventTime.Add(time.Second), "rolled back"),
+					v2TestLog("a2", eventTime.Add(2*time.Second), "done"),
+					v2TestLog("a3", eventTime.Add(3*time.Second), "idle"),
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{
+		config:     &config.Config{},
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+	}
+
+	detail, err := client.GetLog(context.Background(), id, GetOptions{Lookback: 24 * time.Hour, Before: 2, After: 2, ContextWindow: 5 * time.Minute})
+	if err != nil {
+		t.Fatalf("GetLog() error = %v", err)
+	}
+
+	// The window is narrowed to the timestamp encoded in the ID
+	if v1Body.Time.From != "2024-01-15T09:59:00Z" || v1Body.Time.To != "2024-01-15T10:01:00Z" {
+		t.Errorf("v1 time window = %s - %s, want around the ID timestamp", v1Body.Time.From, v1Body.Time.To)
+	}
+	if detail.Status != "error" || detail.Source != "go" || detail.Host != "web-1" {
+		t.Errorf("event = %+v, want status, source and host", detail.Event)
+	}
+	if _, ok := detail.Attributes["http"].(map[string]interface{}); !ok {
+		t.Errorf("nested attributes not preserved: %v", detail.Attributes)
+	}
+
+	if detail.Context == nil {
+		t.Fatal("context not fetched")
+	}
+	ids := func(events []Event) []string {
+		var out []string
+		for _, e := range events {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+	if got := ids(detail.Context.Before); len(got) != 2 || got[0] != "b2" || got[1] != "same" {
+		t.Errorf("before = %v, want [b2 same] (oldest first, event excluded)", got)
+	}
+	if got := ids(detail.Context.After); len(got) != 2 || got[0] != "a1" || got[1] != "a2" {
+		t.Errorf("after = %v, want [a1 a2]", got)
+	}
+	for _, q := range contextQueries {
+		if q != "host:web-1 service:api" {
+			t.Errorf("context query = %q, want host and service", q)
+		}
+	}
+}
+
+func TestClient_GetLog_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// startAt is ignored for logs outside the window: other logs are returned
+		_, _ = w.Write([]byte(`{"logs":[{"id":"other","content":{"message":"unrelated"}}]}`))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		config:     &config.Config{},
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+	}
+
+	_, err := client.GetLog(context.Background(), "unknown-id", GetOptions{Lookback: time.Hour})
+	if !errors.Is(err, ErrLogNotFound) {
+		t.Errorf("GetLog() error = %v, want ErrLogNotFound", err)
+	}
+}
+
+func TestEventDetail_YAML(t *testing.T) {
+	detail := EventDetail{
+		Event: Event{
+			ID:         "abc",
+			Timestamp:  "2024-01-15T10:00:00.5Z",
+			Service:    "api",
+			Message:    "payment failed",
+			Attributes: map[string]interface{}{"http": map[string]interface{}{"status_code": 502}},
+		},
+	}
+
+	out, err := yaml.Marshal(detail)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	want := `id: abc
+timestamp: "2024-01-15T10:00:00.5Z"
+service: api
+message: payment failed
+attributes:
+    http:
+        status_code: 502
+`
+	if string(out) != want {
+		t.Errorf("yaml =\n%s\nwant\n%s", out, want)
+	}
+}
@@ -1,18 +1,25 @@
 package datadog
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"math"
 	"math/rand"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+	"github.com/jedipunkz/datadog-log-tail/internal/filter"
 	"github.com/jedipunkz/datadog-log-tail/internal/output"
+	"github.com/jedipunkz/datadog-log-tail/internal/respcache"
+	"github.com/jedipunkz/datadog-log-tail/internal/state"
 	"github.com/jedipunkz/datadog-log-tail/pkg/utils"
 )
 
@@ -25,6 +32,10 @@ type LogEntry struct {
 	Status     string                 `json:"status"`
 	Tags       []string               `json:"tags"`
 	Attributes map[string]interface{} `json:"attributes"`
+	Host       string                 `json:"host,omitempty"`
+	Source     string                 `json:"source,omitempty"`
+	TraceID    string                 `json:"trace_id,omitempty"`
+	SpanID     string                 `json:"span_id,omitempty"`
 }
 
 // v2 API response structure
@@ -60,6 +71,9 @@ type v2LogsResponse struct {
 			After string `json:"after"`
 		} `json:"page"`
 	} `json:"meta"`
+
+	// cached is set when the response was served from the response cache
+	cached bool
 }
 
 // Implement LogEntry interface methods
@@ -70,11 +84,21 @@ func (l LogEntry) GetService() string                    { return l.Service }
 func (l LogEntry) GetStatus() string                     { return l.Status }
 func (l LogEntry) GetTags() []string                     { return l.Tags }
 func (l LogEntry) GetAttributes() map[string]interface{} { return l.Attributes }
+func (l LogEntry) GetHost() string                       { return l.Host }
+func (l LogEntry) GetSource() string                     { return l.Source }
+func (l LogEntry) GetTraceID() string                    { return l.TraceID }
+func (l LogEntry) GetSpanID() string                     { return l.SpanID }
+
+// tailPageSize is the page size used while following cursors in tail mode
+const tailPageSize = 100
 
-// TailLogs tails logs in real-time
+// TailLogs tails logs in real-time until interrupted
 func (c *Client) TailLogs() error {
-	ctx := context.Background()
-	formatter := output.NewFormatter(c.config.GetOutputFormat())
+	// Stop cleanly on Ctrl-C / SIGTERM so that sinks can be flushed
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	formatter := c.newFormatter()
 
 	fmt.Println("Starting Datadog Logs tail...")
 	fmt.Printf("Output format: %s\n", c.config.GetOutputFormat())
@@ -84,9 +108,29 @@ func (c *Client) TailLogs() error {
 	if c.config.GetLogLevel() != "" {
 		fmt.Printf("Log level: %s\n", c.config.GetLogLevel())
 	}
+	if c.config.GetAlertsFile() != "" {
+		fmt.Printf("Alerting rules: %s\n", c.config.GetAlertsFile())
+	}
+	if c.config.GetStateFile() != "" {
+		fmt.Printf("State file: %s\n", c.config.GetStateFile())
+	}
 	fmt.Println("---")
 
 	var lastTimestamp time.Time
+	seen := newRecentIDs(maxRecentIDs, nil)
+
+	// Resume from the persisted cursor if available
+	if c.config.GetStateFile() != "" {
+		var err error
+		lastTimestamp, seen, err = c.resumeFromState(ctx, formatter)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
 	retryCount := 0
 	maxRetries := c.config.GetRetryCount()
 	baseInterval := 3 * time.Second // Conservative base interval to avoid rate limits
@@ -97,8 +141,12 @@ func (c *Client) TailLogs() error {
 	consecutiveSuccesses := 0           // Track consecutive successful requests
 	searchWindow := 30 * time.Second    // Dynamic search window
 
+	var newest time.Time // newest log timestamp seen, for the lag metric
+	cp := newContextPrinter()
+
 	for {
 		if retryCount >= maxRetries {
+			c.metrics.retriesExhausted()
 			return fmt.Errorf("maximum retry count (%d) reached", maxRetries)
 		}
 
@@ -111,7 +159,10 @@ func (c *Client) TailLogs() error {
 		}
 		to := time.Now()
 
-		logs, latest, err := c.fetchLogsV2(ctx, from, to)
+		logs, latest, truncated, err := c.fetchLogsV2(ctx, from, to)
+		if ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
 			// Smart rate limit handling with adaptive backoff
 			if strings.Contains(err.Error(), "429") {
@@ -120,8 +171,10 @@ func (c *Client) TailLogs() error {
 				jitter := time.Duration(rand.Intn(int(rateLimitBackoff / 10))) // Small jitter
 				waitTime := rateLimitBackoff + jitter
 
-				fmt.Fprintf(os.Stderr, "Rate limit reached. Backing off for %v...\n", waitTime)
-				time.Sleep(waitTime)
+				slog.Warn("Rate limit reached, backing off", "wait", waitTime)
+				if !sleepContext(ctx, waitTime) {
+					return nil
+				}
 
 				// After rate limit, use conservative interval and reset success counter
 				currentInterval = time.Duration(math.Max(float64(baseInterval*2), float64(currentInterval)))
@@ -133,10 +186,11 @@ func (c *Client) TailLogs() error {
 			}
 
 			retryCount++
-			fmt.Fprintf(os.Stderr, "Failed to fetch logs (attempt %d/%d): %v\n", retryCount, maxRetries, err)
 			backoff := utils.CalculateBackoff(retryCount)
-			fmt.Fprintf(os.Stderr, "Retrying in %v...\n", backoff)
-			time.Sleep(backoff)
+			slog.Warn("Failed to fetch logs", "attempt", retryCount, "max_attempts", maxRetries, "retry_in", backoff, "error", err)
+			if !sleepContext(ctx, backoff) {
+				return nil
+			}
 			continue
 		}
 
@@ -175,18 +229,11 @@ func (c *Client) TailLogs() error {
 		}
 
 		// Output logs immediately as they arrive for better real-time experience
-		for _, log := range logs {
-			formatted, err := formatter.Format(log)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to format log: %v\n", err)
-				continue
-			}
-			// Flush output immediately for real-time display
-			fmt.Println(formatted)
-			if err := os.Stdout.Sync(); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to sync stdout: %v\n", err)
-			}
+		c.deliver(ctx, formatter, logs, seen, cp)
+		if latest.After(newest) {
+			newest = latest
 		}
+		c.metrics.poll(currentInterval, searchWindow, newest)
 
 		// Update lastTimestamp to avoid duplicate logs
 		if !latest.IsZero() {
@@ -202,132 +249,237 @@ func (c *Client) TailLogs() error {
 				lastTimestamp = time.Now().Add(-10 * time.Second)
 			}
 		}
-		time.Sleep(currentInterval)
+
+		// When the per-poll cap was hit, skip the rest of the window so that
+		// the tail keeps up with real time instead of lagging further behind
+		if truncated {
+			slog.Warn("Too many logs in poll window; remaining logs were dropped (raise --max-logs-per-poll to keep them)",
+				"max_logs_per_poll", c.config.GetMaxLogsPerPoll(), "from", from.UTC().Format(time.RFC3339), "to", to.UTC().Format(time.RFC3339))
+			if to.After(lastTimestamp) {
+				lastTimestamp = to
+			}
+		}
+		c.saveState(lastTimestamp, seen)
+
+		if !sleepContext(ctx, currentInterval) {
+			return nil
+		}
 	}
 }
 
-// fetchLogsV2 fetches logs from Datadog Logs API v2
-func (c *Client) fetchLogsV2(ctx context.Context, from, to time.Time) ([]LogEntry, time.Time, error) {
-	endpoint := "/api/v2/logs/events/search"
+// deliver emits tailed logs that have not been seen before and evaluates
+// alerting rules on them
+func (c *Client) deliver(ctx context.Context, formatter output.Formatter, logs []LogEntry, seen *recentIDs, cp *contextPrinter) {
+	matches := make(map[string]bool, len(logs))
+	fresh := make([]LogEntry, 0, len(logs))
+	for _, log := range logs {
+		matches[log.ID] = true
+		if seen.add(log.ID) {
+			fresh = append(fresh, log)
+		}
+	}
+	c.loadContext(ctx, fresh, cp)
+	for _, log := range fresh {
+		c.emitMatch(formatter, log, matches, cp, true)
+		if c.alerts != nil {
+			c.alerts.Evaluate(log)
+		}
+	}
+}
 
-	query := c.buildQueryV2()
-	body := map[string]interface{}{
-		"filter": map[string]interface{}{
-			"from":  from.UTC().Format(time.RFC3339),
-			"to":    to.UTC().Format(time.RFC3339),
-			"query": query,
-		},
-		"page": map[string]interface{}{
-			"limit": 100, // Balanced limit to avoid overwhelming the API
-		},
-		"sort": "timestamp",
+// resumeFromState loads the persisted tail cursor and catches up on logs
+// that arrived while dlt was not running, bounded by the max catch-up
+// duration. It returns the cursor to continue tailing from.
+func (c *Client) resumeFromState(ctx context.Context, formatter output.Formatter) (time.Time, *recentIDs, error) {
+	st, err := state.Load(c.config.GetStateFile())
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	seen := newRecentIDs(maxRecentIDs, st.SeenIDs)
+	if st.LastTimestamp.IsZero() {
+		return time.Time{}, seen, nil
 	}
-	jsonBody, _ := json.Marshal(body)
 
-	req, err := c.createRequest(ctx, "POST", endpoint)
+	now := time.Now()
+	from := st.LastTimestamp
+	if limit := now.Add(-c.config.GetMaxCatchup()); from.Before(limit) {
+		slog.Warn("State is older than --max-catchup; skipping logs",
+			"max_catchup", c.config.GetMaxCatchup(), "from", from.Format(time.RFC3339), "to", limit.Format(time.RFC3339))
+		from = limit
+	}
+	if !now.After(from) {
+		return st.LastTimestamp, seen, nil
+	}
+
+	slog.Info("Resuming from state file", "from", from.Format(time.RFC3339))
+	logs, err := c.fetchAllLogsV2(ctx, from, now)
 	if err != nil {
-		return nil, time.Time{}, err
+		return time.Time{}, nil, fmt.Errorf("failed to catch up from state: %w", err)
+	}
+	c.deliver(ctx, formatter, logs, seen, newContextPrinter())
+
+	// Continue from the newest caught-up log, leaving a small overlap for
+	// late-arriving logs (duplicates are filtered by ID)
+	last := now.Add(-10 * time.Second)
+	for _, log := range logs {
+		if ts := time.Unix(log.Timestamp, 0); ts.After(last) {
+			last = ts
+		}
+	}
+	c.saveState(last, seen)
+	return last, seen, nil
+}
+
+// saveState persists the tail cursor if a state file is configured
+func (c *Client) saveState(lastTimestamp time.Time, seen *recentIDs) {
+	if c.config.GetStateFile() == "" || lastTimestamp.IsZero() {
+		return
+	}
+	st := &state.State{
+		LastTimestamp: lastTimestamp,
+		SeenIDs:       seen.list(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := state.Save(c.config.GetStateFile(), st); err != nil {
+		slog.Error("Failed to save state", "error", err)
+	}
+}
+
+// newFormatter creates the formatter for stdout output
+func (c *Client) newFormatter() output.Formatter {
+	return output.NewFormatterWithOptions(c.config.GetOutputFormat(), output.TextOptions{
+		Multiline: c.config.GetMultiline(),
+		Color:     c.config.GetColor(),
+	})
+}
+
+// emit writes a log entry to stdout and forwards it to all configured sinks
+func (c *Client) emit(formatter output.Formatter, log LogEntry, flush bool) {
+	c.print(formatter, log, "", flush)
+	c.forward(log)
+}
+
+// print writes a log entry to stdout. Marked entries (matches and context
+// lines) are prefixed in text output and carry a context field in JSON.
+func (c *Client) print(formatter output.Formatter, log LogEntry, mark string, flush bool) {
+	if c.noStdout {
+		return
+	}
+	var entry output.LogEntry = log
+	prefix := ""
+	if mark != "" {
+		if _, ok := formatter.(*output.JSONFormatter); ok {
+			entry = markedLog{LogEntry: log, Context: mark}
+		} else if mark == markMatch {
+			prefix = "> "
+		} else {
+			prefix = "  "
+		}
 	}
-	req.Body = io.NopCloser(bytes.NewReader(jsonBody))
-	req.ContentLength = int64(len(jsonBody))
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	formatted, err := formatter.Format(entry)
 	if err != nil {
-		return nil, time.Time{}, fmt.Errorf("failed to execute HTTP request: %w", err)
+		slog.Error("Failed to format log", "id", log.ID, "error", err)
+	} else {
+		fmt.Println(prefix + formatted)
+		// Flush output immediately for real-time display
+		if flush {
+			if err := os.Stdout.Sync(); err != nil {
+				// Sync fails on terminals and pipes; only worth a debug record
+				slog.Debug("Failed to sync stdout", "error", err)
+			}
+		}
 	}
-	defer func() { _ = resp.Body.Close() }()
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, time.Time{}, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+// forward records an emitted log entry and forwards it to all sinks
+func (c *Client) forward(log LogEntry) {
+	c.metrics.logEmitted(log)
+
+	for _, s := range c.sinks {
+		if err := s.Write(log); err != nil {
+			slog.Error("Failed to write log to sink", "error", err)
+		}
 	}
+}
 
-	var v2resp v2LogsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&v2resp); err != nil {
-		return nil, time.Time{}, fmt.Errorf("failed to parse response: %w", err)
+// sleepContext sleeps for the given duration and reports false if the
+// context was cancelled in the meantime
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
 	}
+}
 
+// fetchLogsV2 fetches all logs in the window from Datadog Logs API v2,
+// following pagination cursors until the window is drained or the
+// configured per-poll cap is reached. It reports whether logs were left
+// unread because of the cap.
+func (c *Client) fetchLogsV2(ctx context.Context, from, to time.Time) ([]LogEntry, time.Time, bool, error) {
+	maxLogs := c.config.GetMaxLogsPerPoll()
 	var logs []LogEntry
-	var latest time.Time
-	for _, d := range v2resp.Data {
-		ts, _ := time.Parse(time.RFC3339Nano, d.Attrs.Timestamp)
+	var cursor string
+	truncated := false
 
-		// Extract message from multiple possible fields
-		message := d.Attrs.Message
-		if message == "" {
-			message = d.Attrs.Content
-		}
-		if message == "" {
-			message = d.Attrs.Text
+	for {
+		page, nextCursor, _, err := c.fetchLogsV2WithPagination(ctx, from, to, cursor, tailPageSize)
+		if err != nil {
+			return nil, time.Time{}, false, err
 		}
-		if message == "" {
-			message = d.Attrs.Log
+		logs = append(logs, page...)
+
+		if nextCursor == "" {
+			break
 		}
-		if message == "" {
-			message = "No message content"
+		if maxLogs > 0 && len(logs) >= maxLogs {
+			// The API often returns a cursor to an empty page, so with
+			// exactly maxLogs logs one more log is looked up to tell
+			// whether any were left unread
+			truncated = len(logs) > maxLogs
+			if !truncated {
+				more, _, _, err := c.fetchLogsV2WithPagination(ctx, from, to, nextCursor, 1)
+				if err != nil {
+					return nil, time.Time{}, false, err
+				}
+				truncated = len(more) > 0
+			}
+			break
 		}
+		cursor = nextCursor
 
-		// Extract service name
-		service := d.Attrs.Service
-		if service == "" {
-			service = d.Attrs.Host
+		// Add a small delay between requests to avoid hitting rate limits
+		if !sleepContext(ctx, 500*time.Millisecond) {
+			return nil, time.Time{}, false, ctx.Err()
 		}
+	}
 
-		// Extract status/log level
-		status := d.Attrs.Status
-		if status == "" {
-			status = d.Attrs.LogLevel
-		}
+	if maxLogs > 0 && len(logs) > maxLogs {
+		logs = logs[:maxLogs]
+		truncated = true
+	}
 
-		log := LogEntry{
-			ID:         d.ID,
-			Timestamp:  ts.Unix(),
-			Message:    message,
-			Service:    service,
-			Status:     status,
-			Tags:       d.Attrs.Tags,
-			Attributes: d.Attrs.Attributes,
-		}
-		logs = append(logs, log)
-		if ts.After(latest) {
+	var latest time.Time
+	for _, log := range logs {
+		if ts := time.Unix(log.Timestamp, 0); ts.After(latest) {
 			latest = ts
 		}
 	}
-	return logs, latest, nil
+	return logs, latest, truncated, nil
 }
 
 // GetLogsFromTimestamp retrieves logs from a time range (batch mode)
 func (c *Client) GetLogsFromTimestamp() error {
 	ctx := context.Background()
-	formatter := output.NewFormatter(c.config.GetOutputFormat())
-
-	// Parse the time range from config
-	timestampStr := c.config.GetTimestamp()
-
-	// Ensure it's a range format (must contain comma)
-	if !strings.Contains(timestampStr, ",") {
-		return fmt.Errorf("timestamp must be a time range in format: from,to (e.g. 2024-01-15T10:00:00Z,2024-01-15T11:00:00Z)")
-	}
-
-	// Parse time range
-	parts := strings.Split(timestampStr, ",")
-	if len(parts) != 2 {
-		return fmt.Errorf("invalid timestamp range format (use: from,to in RFC3339, e.g. 2024-01-15T10:00:00Z,2024-01-15T11:00:00Z)")
-	}
+	formatter := c.newFormatter()
 
-	from, err := time.Parse(time.RFC3339, strings.TrimSpace(parts[0]))
-	if err != nil {
-		return fmt.Errorf("invalid start timestamp format (use RFC3339, e.g. 2024-01-15T10:00:00Z): %w", err)
-	}
-
-	to, err := time.Parse(time.RFC3339, strings.TrimSpace(parts[1]))
+	from, to, err := c.TimeRange()
 	if err != nil {
-		return fmt.Errorf("invalid end timestamp format (use RFC3339, e.g. 2024-01-15T10:00:00Z): %w", err)
-	}
-
-	if !to.After(from) {
-		return fmt.Errorf("end timestamp must be after start timestamp")
+		return err
 	}
 
 	fmt.Printf("Retrieving logs from %s to %s...\n", from.Format(time.RFC3339), to.Format(time.RFC3339))
@@ -341,7 +493,7 @@ func (c *Client) GetLogsFromTimestamp() error {
 	fmt.Println("---")
 
 	// Fetch all logs using pagination
-	allLogs, err := c.fetchAllLogsV2(ctx, from, to)
+	allLogs, err := c.searchLogs(ctx, from, to)
 	if err != nil {
 		return fmt.Errorf("failed to fetch logs: %w", err)
 	}
@@ -352,19 +504,60 @@ func (c *Client) GetLogsFromTimestamp() error {
 	}
 
 	// Output all logs
+	matches := make(map[string]bool, len(allLogs))
 	for _, log := range allLogs {
-		formatted, err := formatter.Format(log)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to format log: %v\n", err)
-			continue
-		}
-		fmt.Println(formatted)
+		matches[log.ID] = true
+	}
+	cp := newContextPrinter()
+	c.loadContext(ctx, allLogs, cp)
+	for _, log := range allLogs {
+		c.emitMatch(formatter, log, matches, cp, false)
 	}
 
 	fmt.Printf("\nRetrieved %d log entries.\n", len(allLogs))
 	return nil
 }
 
+// TimeRange returns the configured time range of batch mode
+func (c *Client) TimeRange() (time.Time, time.Time, error) {
+	return parseTimeRange(c.config.GetTimestamp())
+}
+
+// parseTimeRange parses a from,to time range in RFC3339
+func parseTimeRange(timestampStr string) (time.Time, time.Time, error) {
+	// Ensure it's a range format (must contain comma)
+	if !strings.Contains(timestampStr, ",") {
+		return time.Time{}, time.Time{}, fmt.Errorf("timestamp must be a time range in format: from,to (e.g. 2024-01-15T10:00:00Z,2024-01-15T11:00:00Z)")
+	}
+
+	// Parse time range
+	parts := strings.Split(timestampStr, ",")
+	if len(parts) != 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid timestamp range format (use: from,to in RFC3339, e.g. 2024-01-15T10:00:00Z,2024-01-15T11:00:00Z)")
+	}
+
+	from, err := time.Parse(time.RFC3339, strings.TrimSpace(parts[0]))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start timestamp format (use RFC3339, e.g. 2024-01-15T10:00:00Z): %w", err)
+	}
+
+	to, err := time.Parse(time.RFC3339, strings.TrimSpace(parts[1]))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end timestamp format (use RFC3339, e.g. 2024-01-15T10:00:00Z): %w", err)
+	}
+
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end timestamp must be after start timestamp")
+	}
+	return from, to, nil
+}
+
+// FetchLogs returns all logs between from and to matching the configured
+// filters, following pagination
+func (c *Client) FetchLogs(ctx context.Context, from, to time.Time) ([]LogEntry, error) {
+	return c.searchLogs(ctx, from, to)
+}
+
 // fetchAllLogsV2 fetches all logs from Datadog Logs API v2 using pagination with rate limiting
 func (c *Client) fetchAllLogsV2(ctx context.Context, from, to time.Time) ([]LogEntry, error) {
 	var allLogs []LogEntry
@@ -373,13 +566,15 @@ func (c *Client) fetchAllLogsV2(ctx context.Context, from, to time.Time) ([]LogE
 	retryCount := 0
 	maxRetries := 5
 	baseDelay := 2 * time.Second
+	failures := c.archiveFailures
 
 	for {
-		logs, nextCursor, err := c.fetchLogsV2WithPagination(ctx, from, to, cursor, pageSize)
+		logs, nextCursor, cached, err := c.fetchLogsV2WithPagination(ctx, from, to, cursor, pageSize)
 		if err != nil {
 			// Handle rate limiting with exponential backoff
 			if strings.Contains(err.Error(), "429") {
 				if retryCount >= maxRetries {
+					c.metrics.retriesExhausted()
 					return nil, fmt.Errorf("maximum retry count reached due to rate limiting: %w", err)
 				}
 
@@ -388,7 +583,7 @@ func (c *Client) fetchAllLogsV2(ctx context.Context, from, to time.Time) ([]LogE
 				jitter := time.Duration(rand.Intn(int(backoffDelay / 4))) // Add up to 25% jitter
 				totalDelay := backoffDelay + jitter
 
-				fmt.Fprintf(os.Stderr, "Rate limit reached. Retrying in %v... (attempt %d/%d)\n", totalDelay, retryCount+1, maxRetries)
+				slog.Warn("Rate limit reached, retrying", "wait", totalDelay, "attempt", retryCount+1, "max_attempts", maxRetries)
 				time.Sleep(totalDelay)
 				retryCount++
 				continue
@@ -408,114 +603,175 @@ func (c *Client) fetchAllLogsV2(ctx context.Context, from, to time.Time) ([]LogE
 
 		// Show progress for large datasets
 		if len(allLogs)%500 == 0 {
-			fmt.Fprintf(os.Stderr, "Retrieved %d log entries so far...\n", len(allLogs))
+			slog.Info("Retrieving logs", "retrieved", len(allLogs))
 		}
 
 		// Add a small delay between requests to avoid hitting rate limits
-		time.Sleep(500 * time.Millisecond)
+		if !cached {
+			time.Sleep(500 * time.Millisecond)
+		}
 	}
 
+	c.archiveCoverage(from, to, failures)
 	return allLogs, nil
 }
 
-// fetchLogsV2WithPagination fetches logs with pagination support
-func (c *Client) fetchLogsV2WithPagination(ctx context.Context, from, to time.Time, cursor string, limit int) ([]LogEntry, string, error) {
+// searchRequest is a Logs API v2 search request
+type searchRequest struct {
+	Query  string
+	From   time.Time
+	To     time.Time
+	Cursor string
+	Limit  int
+	Sort   string // "timestamp" (oldest first) or "-timestamp" (newest first)
+}
+
+// searchV2 runs a single Logs API v2 search request
+func (c *Client) searchV2(ctx context.Context, sr searchRequest) (*v2LogsResponse, error) {
 	endpoint := "/api/v2/logs/events/search"
 
-	query := c.buildQueryV2()
 	body := map[string]interface{}{
 		"filter": map[string]interface{}{
-			"from":  from.UTC().Format(time.RFC3339),
-			"to":    to.UTC().Format(time.RFC3339),
-			"query": query,
+			"from":  sr.From.UTC().Format(time.RFC3339),
+			"to":    sr.To.UTC().Format(time.RFC3339),
+			"query": sr.Query,
 		},
 		"page": map[string]interface{}{
-			"limit": limit,
+			"limit": sr.Limit,
 		},
-		"sort": "timestamp",
+		"sort": sr.Sort,
 	}
 
 	// Add cursor for pagination if available
-	if cursor != "" {
-		body["page"].(map[string]interface{})["cursor"] = cursor
+	if sr.Cursor != "" {
+		body["page"].(map[string]interface{})["cursor"] = sr.Cursor
 	}
 
 	jsonBody, _ := json.Marshal(body)
 
+	// Responses for windows that ended long enough ago do not change
+	var cacheKey string
+	if c.cache != nil && sr.To.Before(time.Now().Add(-c.config.GetCacheHorizon())) {
+		cacheKey = respcache.Key(c.baseURL, c.config.GetAPIKey(), endpoint, string(jsonBody))
+		if data, ok := c.cache.Get(cacheKey); ok {
+			var v2resp v2LogsResponse
+			if err := json.Unmarshal(data, &v2resp); err == nil {
+				slog.DebugContext(ctx, "Fetched logs page from cache", "from", sr.From.UTC().Format(time.RFC3339), "to", sr.To.UTC().Format(time.RFC3339),
+					"logs", len(v2resp.Data), "cursor", sr.Cursor, "next_cursor", v2resp.Meta.Page.After)
+				v2resp.cached = true
+				return &v2resp, nil
+			}
+		}
+	}
+
 	req, err := c.createRequest(ctx, "POST", endpoint)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
-	req.Body = io.NopCloser(bytes.NewReader(jsonBody))
-	req.ContentLength = int64(len(jsonBody))
-	req.Header.Set("Content-Type", "application/json")
+	setBody(req, jsonBody)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to execute HTTP request: %w", err)
+		return nil, err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, "", fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
-
 	var v2resp v2LogsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&v2resp); err != nil {
-		return nil, "", fmt.Errorf("failed to parse response: %w", err)
+	if err := json.Unmarshal(data, &v2resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
-
-	var logs []LogEntry
-	var latest time.Time
-	for _, d := range v2resp.Data {
-		ts, _ := time.Parse(time.RFC3339Nano, d.Attrs.Timestamp)
-
-		// Extract message from multiple possible fields
-		message := d.Attrs.Message
-		if message == "" {
-			message = d.Attrs.Content
-		}
-		if message == "" {
-			message = d.Attrs.Text
-		}
-		if message == "" {
-			message = d.Attrs.Log
-		}
-		if message == "" {
-			message = "No message content"
+	if cacheKey != "" {
+		if err := c.cache.Put(cacheKey, data); err != nil {
+			slog.Warn("Failed to cache response", "error", err)
 		}
+	}
 
-		// Extract service name
-		service := d.Attrs.Service
-		if service == "" {
-			service = d.Attrs.Host
-		}
+	slog.DebugContext(ctx, "Fetched logs page", "from", sr.From.UTC().Format(time.RFC3339), "to", sr.To.UTC().Format(time.RFC3339),
+		"logs", len(v2resp.Data), "cursor", sr.Cursor, "next_cursor", v2resp.Meta.Page.After)
+	return &v2resp, nil
+}
 
-		// Extract status/log level
-		status := d.Attrs.Status
-		if status == "" {
-			status = d.Attrs.LogLevel
-		}
+// fetchLogsV2WithPagination fetches logs with pagination support. It
+// reports whether the page was served from the response cache.
+func (c *Client) fetchLogsV2WithPagination(ctx context.Context, from, to time.Time, cursor string, limit int) ([]LogEntry, string, bool, error) {
+	v2resp, err := c.searchV2(ctx, searchRequest{
+		Query:  c.buildQueryV2(),
+		From:   from,
+		To:     to,
+		Cursor: cursor,
+		Limit:  limit,
+		Sort:   "timestamp",
+	})
+	if err != nil {
+		return nil, "", false, err
+	}
+	c.archiveLogs(v2resp.Data)
 
-		log := LogEntry{
-			ID:         d.ID,
-			Timestamp:  ts.Unix(),
-			Message:    message,
-			Service:    service,
-			Status:     status,
-			Tags:       d.Attrs.Tags,
-			Attributes: d.Attrs.Attributes,
-		}
-		logs = append(logs, log)
-		if ts.After(latest) {
-			latest = ts
-		}
+	logs := make([]LogEntry, 0, len(v2resp.Data))
+	for _, d := range v2resp.Data {
+		logs = append(logs, c.toLogEntry(d))
 	}
 
 	// Return the next cursor for pagination
-	nextCursor := v2resp.Meta.Page.After
-	return logs, nextCursor, nil
+	return logs, v2resp.Meta.Page.After, v2resp.cached, nil
+}
+
+// toLogEntry maps a v2 log to a log entry using the field map
+func (d v2Log) toLogEntry(fm config.FieldMap) LogEntry {
+	ts, _ := time.Parse(time.RFC3339Nano, d.Attrs.Timestamp)
+	f := mapFields(fm, d.Attrs.reserved(), d.Attrs.Attributes)
+
+	return LogEntry{
+		ID:         d.ID,
+		Timestamp:  ts.Unix(),
+		Message:    f.Message,
+		Service:    f.Service,
+		Status:     f.Status,
+		Tags:       d.Attrs.Tags,
+		Attributes: d.Attrs.Attributes,
+		Host:       f.Host,
+		Source:     f.Source,
+		TraceID:    f.TraceID,
+		SpanID:     f.SpanID,
+	}
+}
+
+// reserved returns the reserved fields of a v2 log by name
+func (a v2LogAttributes) reserved() map[string]string {
+	return map[string]string{
+		"message": a.Message,
+		"content": a.Content,
+		"text":    a.Text,
+		"log":     a.Log,
+		"service": a.Service,
+		"status":  a.Status,
+		"level":   a.LogLevel,
+		"host":    a.Host,
+		"source":  a.Source,
+	}
+}
+
+// attributeString returns a string or numeric attribute at a dotted path
+// (e.g. dd.trace_id) as a string
+func attributeString(attrs map[string]interface{}, path string) string {
+	v, ok := filter.Lookup(attrs, path)
+	if !ok {
+		return ""
+	}
+	switch val := v.(type) {
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return ""
+	}
 }
 
 // buildQueryV2 builds Datadog v2 query
@@ -1,9 +1,21 @@
 package datadog
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/jedipunkz/datadog-log-tail/internal/config"
+	"github.com/jedipunkz/datadog-log-tail/internal/metrics"
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+	"github.com/jedipunkz/datadog-log-tail/internal/respcache"
+	"github.com/jedipunkz/datadog-log-tail/internal/state"
 )
 
 func TestLogEntry_Interface(t *testing.T) {
@@ -282,3 +294,398 @@ func TestV2LogAttributes_StatusExtraction(t *testing.T) {
 		})
 	}
 }
+
+// recordingSink collects log entries written to it
+type recordingSink struct {
+	logs   []output.LogEntry
+	closed bool
+}
+
+func (r *recordingSink) Write(log output.LogEntry) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *recordingSink) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestClient_emit_ForwardsToSinks(t *testing.T) {
+	first := &recordingSink{}
+	second := &recordingSink{}
+	client := &Client{config: &config.Config{}}
+	client.AddSink(first)
+	client.AddSink(second)
+
+	log := LogEntry{ID: "abc", Message: "hello"}
+	client.emit(output.NewFormatter("json"), log, false)
+
+	if len(first.logs) != 1 || len(second.logs) != 1 {
+		t.Fatalf("sinks received %d and %d logs, want 1 each", len(first.logs), len(second.logs))
+	}
+	if first.logs[0].GetID() != "abc" {
+		t.Errorf("sink received id %v, want abc", first.logs[0].GetID())
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !first.closed || !second.closed {
+		t.Error("Close() did not close all sinks")
+	}
+}
+
+func TestParseTimeRange(t *testing.T) {
+	from, to, err := parseTimeRange("2024-01-15T10:00:00Z, 2024-01-15T11:00:00Z")
+	if err != nil {
+		t.Fatalf("parseTimeRange() error = %v", err)
+	}
+	if !from.Equal(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)) || to.Sub(from) != time.Hour {
+		t.Errorf("parseTimeRange() = %v, %v", from, to)
+	}
+
+	tests := []struct {
+		name          string
+		input         string
+		errorContains string
+	}{
+		{"Not a range", "2024-01-15T10:00:00Z", "must be a time range"},
+		{"Invalid start", "10:00,2024-01-15T11:00:00Z", "invalid start timestamp"},
+		{"End before start", "2024-01-15T11:00:00Z,2024-01-15T10:00:00Z", "must be after start"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseTimeRange(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("parseTimeRange(%q) error = %v, want error containing %q", tt.input, err, tt.errorContains)
+			}
+		})
+	}
+}
+
+func TestClient_DisableStdout(t *testing.T) {
+	sink := &recordingSink{}
+	client := &Client{config: &config.Config{}}
+	client.AddSink(sink)
+	client.DisableStdout()
+
+	client.emit(output.NewFormatter("json"), LogEntry{ID: "abc"}, false)
+	if len(sink.logs) != 1 {
+		t.Errorf("sink received %d logs, want 1 with stdout disabled", len(sink.logs))
+	}
+}
+
+func TestSleepContext(t *testing.T) {
+	if !sleepContext(context.Background(), time.Millisecond) {
+		t.Error("sleepContext() = false, want true for an active context")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if sleepContext(ctx, time.Hour) {
+		t.Error("sleepContext() = true, want false for a cancelled context")
+	}
+}
+
+func TestClient_resumeFromState(t *testing.T) {
+	now := time.Now().UTC()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := map[string]interface{}{
+			"data": []map[string]interface{}{
+				{"id": "seen-before", "attributes": map[string]interface{}{
+					"timestamp": now.Add(-3 * time.Minute).Format(time.RFC3339Nano), "message": "old"}},
+				{"id": "new", "attributes": map[string]interface{}{
+					"timestamp": now.Add(-2 * time.Minute).Format(time.RFC3339Nano), "message": "new"}},
+			},
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	if err := state.Save(statePath, &state.State{
+		LastTimestamp: now.Add(-5 * time.Minute),
+		SeenIDs:       []string{"seen-before"},
+	}); err != nil {
+		t.Fatalf("state.Save() error = %v", err)
+	}
+
+	rec := &recordingSink{}
+	client := &Client{
+		config:     &config.Config{StateFile: statePath, MaxCatchup: time.Hour},
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+	}
+	client.AddSink(rec)
+
+	last, seen, err := client.resumeFromState(context.Background(), output.NewFormatter("json"))
+	if err != nil {
+		t.Fatalf("resumeFromState() error = %v", err)
+	}
+
+	if len(rec.logs) != 1 || rec.logs[0].GetID() != "new" {
+		t.Fatalf("delivered %d logs, want only the unseen one", len(rec.logs))
+	}
+	if seen.add("new") {
+		t.Error("caught-up log ID was not recorded as seen")
+	}
+	if last.Before(now.Add(-11 * time.Second)) {
+		t.Errorf("cursor = %v, want close to now", last)
+	}
+
+	st, err := state.Load(statePath)
+	if err != nil {
+		t.Fatalf("state.Load() error = %v", err)
+	}
+	if !st.LastTimestamp.Equal(last) {
+		t.Errorf("persisted cursor = %v, want %v", st.LastTimestamp, last)
+	}
+}
+
+// pagedServer serves log pages of the given sizes, linked by cursors
+func pagedServer(t *testing.T, pageSizes []int) (*httptest.Server, *int) {
+	t.Helper()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Page struct {
+				Cursor string `json:"cursor"`
+			} `json:"page"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		page := 0
+		if body.Page.Cursor != "" {
+			_, _ = fmt.Sscanf(body.Page.Cursor, "page-%d", &page)
+		}
+		requests++
+
+		var data []map[string]interface{}
+		for i := 0; i < pageSizes[page]; i++ {
+			data = append(data, map[string]interface{}{
+				"id": fmt.Sprintf("log-%d-%d", page, i),
+				"attributes": map[string]interface{}{
+					"timestamp": time.Unix(1700000000+int64(page), 0).UTC().Format(time.RFC3339Nano),
+					"message":   "hello",
+				},
+			})
+		}
+		resp := map[string]interface{}{"data": data}
+		if page+1 < len(pageSizes) {
+			resp["meta"] = map[string]interface{}{"page": map[string]interface{}{"after": fmt.Sprintf("page-%d", page+1)}}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	return server, &requests
+}
+
+func TestClient_fetchLogsV2_FollowsCursor(t *testing.T) {
+	server, requests := pagedServer(t, []int{100, 100, 30})
+	defer server.Close()
+
+	client := &Client{
+		config:     &config.Config{MaxLogsPerPoll: 0},
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+	}
+
+	logs, latest, truncated, err := client.fetchLogsV2(context.Background(), time.Unix(1700000000, 0), time.Unix(1700000060, 0))
+	if err != nil {
+		t.Fatalf("fetchLogsV2() error = %v", err)
+	}
+	if len(logs) != 230 || *requests != 3 {
+		t.Errorf("fetchLogsV2() returned %d logs in %d requests, want 230 in 3", len(logs), *requests)
+	}
+	if truncated {
+		t.Error("fetchLogsV2() truncated = true, want false without a cap")
+	}
+	if latest.Unix() != 1700000002 {
+		t.Errorf("latest = %v, want timestamp of the last page", latest.Unix())
+	}
+}
+
+func TestClient_fetchLogsV2_Cap(t *testing.T) {
+	server, requests := pagedServer(t, []int{100, 100, 100})
+	defer server.Close()
+
+	client := &Client{
+		config:     &config.Config{MaxLogsPerPoll: 150},
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+	}
+
+	logs, _, truncated, err := client.fetchLogsV2(context.Background(), time.Unix(1700000000, 0), time.Unix(1700000060, 0))
+	if err != nil {
+		t.Fatalf("fetchLogsV2() error = %v", err)
+	}
+	if len(logs) != 150 || *requests != 2 {
+		t.Errorf("fetchLogsV2() returned %d logs in %d requests, want 150 in 2", len(logs), *requests)
+	}
+	if !truncated {
+		t.Error("fetchLogsV2() truncated = false, want true when the cap is hit")
+	}
+}
+
+func TestClient_fetchLogsV2_CapReachedExactly(t *testing.T) {
+	tests := []struct {
+		name          string
+		pageSizes     []int
+		wantTruncated bool
+	}{
+		{"Cursor to an empty page", []int{100, 50, 0}, false},
+		{"More logs after the cap", []int{100, 50, 10}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := pagedServer(t, tt.pageSizes)
+			defer server.Close()
+
+			client := &Client{
+				config:     &config.Config{MaxLogsPerPoll: 150},
+				httpClient: server.Client(),
+				baseURL:    server.URL,
+			}
+
+			logs, _, truncated, err := client.fetchLogsV2(context.Background(), time.Unix(1700000000, 0), time.Unix(1700000060, 0))
+			if err != nil {
+				t.Fatalf("fetchLogsV2() error = %v", err)
+			}
+			if len(logs) != 150 || *requests != 3 {
+				t.Errorf("fetchLogsV2() returned %d logs in %d requests, want 150 in 3", len(logs), *requests)
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("fetchLogsV2() truncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestClient_Metrics(t *testing.T) {
+	status := http.StatusTooManyRequests
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"data":[],"meta":{"page":{}}}`))
+	}))
+	defer server.Close()
+
+	registry := metrics.NewRegistry()
+	client := &Client{
+		config:     &config.Config{},
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+		registry:   registry,
+		metrics:    newClientMetrics(registry),
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	if _, _, _, err := client.fetchLogsV2WithPagination(ctx, now.Add(-time.Minute), now, "", 10); err == nil {
+		t.Fatal("expected rate limit error")
+	}
+	status = http.StatusOK
+	if _, _, _, err := client.fetchLogsV2WithPagination(ctx, now.Add(-time.Minute), now, "", 10); err != nil {
+		t.Fatalf("fetchLogsV2WithPagination() error = %v", err)
+	}
+
+	formatter := output.NewFormatter("json")
+	client.deliver(ctx, formatter, []LogEntry{
+		{ID: "1", Service: "web", Status: "info"},
+		{ID: "2", Service: "web", Status: "info"},
+		{ID: "2", Service: "web", Status: "info"}, // duplicate, not emitted
+		{ID: "3", Service: "api", Status: "error"},
+	}, newRecentIDs(maxRecentIDs, nil), newContextPrinter())
+	client.metrics.poll(5*time.Second, 30*time.Second, now.Add(-10*time.Second))
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"429 responses", client.metrics.apiRequests.Value("429"), 1},
+		{"200 responses", client.metrics.apiRequests.Value("200"), 1},
+		{"rate limited", client.metrics.rateLimited.Value(), 1},
+		{"web/info logs", client.metrics.logsEmitted.Value("web", "info"), 2},
+		{"api/error logs", client.metrics.logsEmitted.Value("api", "error"), 1},
+		{"poll interval", client.metrics.pollInterval.Value(), 5},
+		{"search window", client.metrics.searchWindow.Value(), 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+
+	if lag := client.metrics.lag.Value(); lag < 10 || lag > 20 {
+		t.Errorf("lag = %v, want about 10 seconds", lag)
+	}
+
+	var sb strings.Builder
+	if err := client.Metrics().WriteText(&sb); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	if !strings.Contains(sb.String(), `dlt_logs_emitted_total{service="web",status="info"} 2`) {
+		t.Errorf("exposition missing emitted logs:\n%s", sb.String())
+	}
+}
+
+func TestClient_FetchLogs_ResponseCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var body struct {
+			Page struct {
+				Cursor string `json:"cursor"`
+			} `json:"page"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		// The first page links to a second one
+		resp := map[string]interface{}{"data": []map[string]interface{}{
+			archiveLog("log-"+body.Page.Cursor, time.Now().Add(-3*time.Hour), "api", "info"),
+		}}
+		if body.Page.Cursor == "" {
+			resp["meta"] = map[string]interface{}{"page": map[string]interface{}{"after": "2"}}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client := &Client{
+		config:     &config.Config{OutputFormat: "text", CacheHorizon: time.Hour},
+		httpClient: server.Client(),
+		baseURL:    server.URL,
+		cache:      respcache.New(t.TempDir(), respcache.DefaultMaxSize),
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name         string
+		from         time.Time
+		to           time.Time
+		wantRequests int
+	}{
+		{name: "past window is fetched", from: now.Add(-4 * time.Hour), to: now.Add(-2 * time.Hour), wantRequests: 2},
+		{name: "past window is cached", from: now.Add(-4 * time.Hour), to: now.Add(-2 * time.Hour), wantRequests: 0},
+		{name: "other window is fetched", from: now.Add(-5 * time.Hour), to: now.Add(-2 * time.Hour), wantRequests: 2},
+		{name: "recent window is fetched", from: now.Add(-2 * time.Hour), to: now.Add(-30 * time.Minute), wantRequests: 2},
+		{name: "recent window is not cached", from: now.Add(-2 * time.Hour), to: now.Add(-30 * time.Minute), wantRequests: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			logs, err := client.FetchLogs(context.Background(), tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("FetchLogs() error = %v", err)
+			}
+			if len(logs) != 2 {
+				t.Errorf("FetchLogs() returned %d logs, want 2", len(logs))
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
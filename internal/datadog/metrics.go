@@ -0,0 +1,76 @@
+package datadog
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/metrics"
+)
+
+// clientMetrics are the metrics exposed by a client. All methods are no-ops
+// on a nil receiver.
+type clientMetrics struct {
+	logsEmitted    *metrics.Counter
+	apiRequests    *metrics.Counter
+	rateLimited    *metrics.Counter
+	retriesExhaust *metrics.Counter
+	pollInterval   *metrics.Gauge
+	searchWindow   *metrics.Gauge
+	lag            *metrics.Gauge
+	lastPoll       *metrics.Gauge
+}
+
+// newClientMetrics registers the client metrics
+func newClientMetrics(r *metrics.Registry) *clientMetrics {
+	return &clientMetrics{
+		logsEmitted:    r.Counter("dlt_logs_emitted_total", "Log entries emitted, by service and status.", "service", "status"),
+		apiRequests:    r.Counter("dlt_api_requests_total", "Datadog API requests, by HTTP status code (\"error\" for transport errors).", "code"),
+		rateLimited:    r.Counter("dlt_api_rate_limited_total", "Datadog API requests rejected with 429 Too Many Requests."),
+		retriesExhaust: r.Counter("dlt_retries_exhausted_total", "Times the retry limit was reached."),
+		pollInterval:   r.Gauge("dlt_poll_interval_seconds", "Current interval between tail polls."),
+		searchWindow:   r.Gauge("dlt_search_window_seconds", "Current search window used when no cursor is available."),
+		lag:            r.Gauge("dlt_lag_seconds", "Seconds between now and the newest log timestamp seen."),
+		lastPoll:       r.Gauge("dlt_last_poll_timestamp_seconds", "Unix time of the last successful tail poll."),
+	}
+}
+
+func (m *clientMetrics) logEmitted(log LogEntry) {
+	if m == nil {
+		return
+	}
+	m.logsEmitted.Inc(log.Service, log.Status)
+}
+
+func (m *clientMetrics) apiRequest(code int) {
+	if m == nil {
+		return
+	}
+	label := "error"
+	if code > 0 {
+		label = strconv.Itoa(code)
+	}
+	m.apiRequests.Inc(label)
+	if code == 429 {
+		m.rateLimited.Inc()
+	}
+}
+
+func (m *clientMetrics) retriesExhausted() {
+	if m == nil {
+		return
+	}
+	m.retriesExhaust.Inc()
+}
+
+// poll records the state of the tail loop after a successful poll
+func (m *clientMetrics) poll(interval, window time.Duration, newest time.Time) {
+	if m == nil {
+		return
+	}
+	m.pollInterval.SetDuration(interval)
+	m.searchWindow.SetDuration(window)
+	if !newest.IsZero() {
+		m.lag.SetDuration(time.Since(newest))
+	}
+	m.lastPoll.Set(float64(time.Now().Unix()))
+}
@@ -0,0 +1,93 @@
+package datadog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// levelKeys are the keys of a JSON message used to fill an empty status
+var levelKeys = []string{"level", "severity"}
+
+// toLogEntry maps a v2 log to a log entry and applies the configured
+// post-processing
+func (c *Client) toLogEntry(d v2Log) LogEntry {
+	log := d.toLogEntry(c.config.GetFieldMap())
+	c.postProcess(&log)
+	return log
+}
+
+// postProcess applies JSON message parsing and redaction to a log entry
+func (c *Client) postProcess(log *LogEntry) {
+	if c.config.GetParseJSON() {
+		parseJSONMessage(log, c.config.GetMessageKeys())
+	}
+	c.redactEntry(log)
+}
+
+// redactEntry redacts the message, tags and attributes of a log entry
+func (c *Client) redactEntry(log *LogEntry) {
+	if c.redactor == nil {
+		return
+	}
+	log.Message = c.redactor.String(log.Message)
+	log.Tags = c.redactor.Strings(log.Tags)
+	log.Attributes = c.redactor.Map(log.Attributes)
+}
+
+// parseJSONMessage parses a message that is a JSON object and merges its
+// fields into the attributes. Attributes already set by Datadog are kept.
+// The first of messageKeys present in the payload becomes the message, and
+// its level or severity fills an empty status.
+func parseJSONMessage(log *LogEntry, messageKeys []string) {
+	msg := strings.TrimSpace(log.Message)
+	if !strings.HasPrefix(msg, "{") {
+		return
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(msg), &payload); err != nil {
+		return
+	}
+
+	attrs := make(map[string]interface{}, len(log.Attributes)+len(payload))
+	for k, v := range payload {
+		attrs[k] = v
+	}
+	for k, v := range log.Attributes {
+		attrs[k] = v
+	}
+	log.Attributes = attrs
+
+	for _, key := range messageKeys {
+		if s, ok := scalarString(payload[key]); ok && s != "" {
+			log.Message = s
+			break
+		}
+	}
+	if log.Status == "" {
+		for _, key := range levelKeys {
+			if s, ok := scalarString(payload[key]); ok && s != "" {
+				log.Status = strings.ToLower(s)
+				break
+			}
+		}
+	}
+	if log.TraceID == "" {
+		log.TraceID = attributeString(attrs, "dd.trace_id")
+	}
+	if log.SpanID == "" {
+		log.SpanID = attributeString(attrs, "dd.span_id")
+	}
+}
+
+// scalarString returns a string, number or boolean value as a string
+func scalarString(v interface{}) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case float64, bool:
+		return fmt.Sprint(val), true
+	default:
+		return "", false
+	}
+}
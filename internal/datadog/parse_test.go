@@ -0,0 +1,144 @@
+package datadog
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+	"github.com/jedipunkz/datadog-log-tail/internal/redact"
+)
+
+func TestParseJSONMessage(t *testing.T) {
+	keys := []string{"msg", "message", "error"}
+
+	tests := []struct {
+		name        string
+		log         LogEntry
+		wantMessage string
+		wantStatus  string
+		wantTraceID string
+		wantAttrs   map[string]interface{}
+	}{
+		{
+			name:        "Promotes msg and fills status from level",
+			log:         LogEntry{Message: `{"msg":"user created","level":"INFO","user_id":42}`},
+			wantMessage: "user created",
+			wantStatus:  "info",
+			wantAttrs:   map[string]interface{}{"msg": "user created", "level": "INFO", "user_id": float64(42)},
+		},
+		{
+			name:        "Falls back to the next message key",
+			log:         LogEntry{Message: `{"error":"connection refused","severity":"error"}`},
+			wantMessage: "connection refused",
+			wantStatus:  "error",
+			wantAttrs:   map[string]interface{}{"error": "connection refused", "severity": "error"},
+		},
+		{
+			name:        "Skips an empty message key",
+			log:         LogEntry{Message: `{"msg":"","error":"boom"}`},
+			wantMessage: "boom",
+			wantAttrs:   map[string]interface{}{"msg": "", "error": "boom"},
+		},
+		{
+			name:        "Keeps existing status and attributes",
+			log:         LogEntry{Message: `{"message":"hi","level":"debug","env":"dev"}`, Status: "warn", Attributes: map[string]interface{}{"env": "prod"}},
+			wantMessage: "hi",
+			wantStatus:  "warn",
+			wantAttrs:   map[string]interface{}{"message": "hi", "level": "debug", "env": "prod"},
+		},
+		{
+			name:        "Keeps the JSON message without a message key",
+			log:         LogEntry{Message: `{"event":"login"}`},
+			wantMessage: `{"event":"login"}`,
+			wantAttrs:   map[string]interface{}{"event": "login"},
+		},
+		{
+			name:        "Trace ID from the payload",
+			log:         LogEntry{Message: `{"msg":"traced","dd":{"trace_id":"4242"}}`},
+			wantMessage: "traced",
+			wantTraceID: "4242",
+			wantAttrs:   map[string]interface{}{"msg": "traced", "dd": map[string]interface{}{"trace_id": "4242"}},
+		},
+		{
+			name:        "Not JSON",
+			log:         LogEntry{Message: "plain text", Attributes: map[string]interface{}{"a": "b"}},
+			wantMessage: "plain text",
+			wantAttrs:   map[string]interface{}{"a": "b"},
+		},
+		{
+			name:        "Invalid JSON",
+			log:         LogEntry{Message: `{"msg": "truncated`},
+			wantMessage: `{"msg": "truncated`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := tt.log
+			parseJSONMessage(&log, keys)
+			if log.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", log.Message, tt.wantMessage)
+			}
+			if log.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", log.Status, tt.wantStatus)
+			}
+			if log.TraceID != tt.wantTraceID {
+				t.Errorf("TraceID = %q, want %q", log.TraceID, tt.wantTraceID)
+			}
+			if !reflect.DeepEqual(log.Attributes, tt.wantAttrs) {
+				t.Errorf("Attributes = %v, want %v", log.Attributes, tt.wantAttrs)
+			}
+		})
+	}
+}
+
+func TestClient_toLogEntry_ParseJSON(t *testing.T) {
+	d := v2Log{ID: "1", Attrs: v2LogAttributes{Message: `{"msg":"parsed"}`, Service: "api"}}
+
+	tests := []struct {
+		name      string
+		parseJSON bool
+		want      string
+	}{
+		{"Disabled", false, `{"msg":"parsed"}`},
+		{"Enabled", true, "parsed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{config: &config.Config{ParseJSON: tt.parseJSON, MessageKeys: []string{"msg"}}}
+			if got := client.toLogEntry(d).Message; got != tt.want {
+				t.Errorf("Message = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_toLogEntry_Redact(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatalf("redact.New() error = %v", err)
+	}
+	client := &Client{config: &config.Config{ParseJSON: true, MessageKeys: []string{"msg"}}, redactor: redactor}
+
+	d := v2Log{ID: "1", Attrs: v2LogAttributes{
+		Message:    `{"msg":"welcome jane@example.com","card":"4111111111111111"}`,
+		Tags:       []string{"owner:ops@example.com"},
+		Attributes: map[string]interface{}{"usr": map[string]interface{}{"email": "jane@example.com"}},
+	}}
+	log := client.toLogEntry(d)
+
+	if log.Message != "welcome [REDACTED:email]" {
+		t.Errorf("Message = %q, want redacted email", log.Message)
+	}
+	if log.Tags[0] != "owner:[REDACTED:email]" {
+		t.Errorf("Tags = %v, want redacted email", log.Tags)
+	}
+	if got := log.Attributes["usr"].(map[string]interface{})["email"]; got != "[REDACTED:email]" {
+		t.Errorf("nested attribute = %v, want redacted email", got)
+	}
+	// Fields merged from the JSON message are redacted as well
+	if got := log.Attributes["card"]; got != "[REDACTED:credit_card]" {
+		t.Errorf("parsed attribute = %v, want redacted card number", got)
+	}
+}
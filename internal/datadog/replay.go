@@ -0,0 +1,122 @@
+package datadog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/filter"
+)
+
+// ReplayOptions control how exported logs are replayed
+type ReplayOptions struct {
+	// Speed replays logs with the time between their timestamps divided by
+	// Speed. Zero replays them as fast as possible.
+	Speed float64
+}
+
+// ReplayStats counts the replayed logs
+type ReplayStats struct {
+	Read    int
+	Emitted int
+}
+
+// Replay reads exported logs and runs them through the same pipeline as
+// fetched logs: the tag and level filters (evaluated locally), JSON message
+// parsing, redaction, stdout output, sinks and alerting rules.
+//
+// The input is a stream of JSON values, one of:
+//
+//	{"id":"...","timestamp":1705312800,"message":"..."}   a log entry exported with --format json
+//	{"id":"...","type":"log","attributes":{...}}          a log of the Logs API v2
+//	{"data":[...],"meta":{...}}                          a Logs API v2 search response
+func (c *Client) Replay(ctx context.Context, r io.Reader, opts ReplayOptions) (ReplayStats, error) {
+	var stats ReplayStats
+	expr, err := filter.Parse(c.buildQueryV2())
+	if err != nil {
+		return stats, fmt.Errorf("invalid filter: %w", err)
+	}
+	formatter := c.newFormatter()
+
+	dec := json.NewDecoder(r)
+	var prev int64
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			return stats, nil
+		} else if err != nil {
+			return stats, fmt.Errorf("failed to parse exported logs at offset %d: %w", dec.InputOffset(), err)
+		}
+		logs, err := c.decodeExported(raw)
+		if err != nil {
+			return stats, fmt.Errorf("failed to parse exported logs at offset %d: %w", dec.InputOffset(), err)
+		}
+
+		for _, log := range logs {
+			stats.Read++
+			if !expr.Match(log) {
+				continue
+			}
+
+			// Keep the original time between logs, sped up
+			if opts.Speed > 0 && stats.Emitted > 0 {
+				gap := time.Duration(float64(time.Duration(log.Timestamp-prev)*time.Second) / opts.Speed)
+				if gap > 0 && !sleepContext(ctx, gap) {
+					return stats, nil
+				}
+			}
+			if ctx.Err() != nil {
+				return stats, nil
+			}
+			prev = log.Timestamp
+
+			c.emit(formatter, log, opts.Speed > 0)
+			if c.alerts != nil {
+				c.alerts.Evaluate(log)
+			}
+			stats.Emitted++
+		}
+	}
+}
+
+// decodeExported decodes an exported JSON value into log entries
+func (c *Client) decodeExported(raw json.RawMessage) ([]LogEntry, error) {
+	var probe struct {
+		Data json.RawMessage `json:"data"`
+		Type string          `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, fmt.Errorf("expected a JSON object: %w", err)
+	}
+
+	switch {
+	case probe.Data != nil:
+		var resp v2LogsResponse
+		if err := json.Unmarshal(raw, &resp); err != nil {
+			return nil, fmt.Errorf("invalid API response: %w", err)
+		}
+		logs := make([]LogEntry, 0, len(resp.Data))
+		for _, d := range resp.Data {
+			logs = append(logs, c.toLogEntry(d))
+		}
+		return logs, nil
+
+	case probe.Type == "log":
+		var d v2Log
+		if err := json.Unmarshal(raw, &d); err != nil {
+			return nil, fmt.Errorf("invalid API log: %w", err)
+		}
+		return []LogEntry{c.toLogEntry(d)}, nil
+
+	default:
+		var log LogEntry
+		if err := json.Unmarshal(raw, &log); err != nil {
+			return nil, fmt.Errorf("invalid log entry: %w", err)
+		}
+		c.postProcess(&log)
+		return []LogEntry{log}, nil
+	}
+}
@@ -0,0 +1,145 @@
+package datadog
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+	"github.com/jedipunkz/datadog-log-tail/internal/redact"
+)
+
+func TestClient_Replay(t *testing.T) {
+	input := `{"id":"e1","timestamp":1705312800,"message":"exported","service":"api","status":"error"}
+{"id":"a1","type":"log","attributes":{"timestamp":"2024-01-15T10:00:01Z","message":"api log","service":"api","status":"info"}}
+{
+  "data": [
+    {"id":"r1","type":"log","attributes":{"timestamp":"2024-01-15T10:00:02Z","message":"from response","service":"web","status":"warn"}},
+    {"id":"r2","type":"log","attributes":{"timestamp":"2024-01-15T10:00:03Z","message":"second","service":"api","status":"warn"}}
+  ],
+  "meta": {"page": {}}
+}
+`
+	sink := &recordingSink{}
+	client := &Client{config: &config.Config{}}
+	client.AddSink(sink)
+
+	var stats ReplayStats
+	var err error
+	got := captureStdout(t, func() {
+		stats, err = client.Replay(context.Background(), strings.NewReader(input), ReplayOptions{})
+	})
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if stats.Read != 4 || stats.Emitted != 4 {
+		t.Errorf("Replay() stats = %+v, want 4 read and emitted", stats)
+	}
+	var ids []string
+	for _, log := range sink.logs {
+		ids = append(ids, log.GetID())
+	}
+	if strings.Join(ids, ",") != "e1,a1,r1,r2" {
+		t.Errorf("sink received %v, want [e1 a1 r1 r2]", ids)
+	}
+	if !strings.Contains(got, "from response") {
+		t.Errorf("stdout = %q, want replayed messages", got)
+	}
+}
+
+func TestClient_Replay_Filter(t *testing.T) {
+	input := `{"id":"1","message":"a","service":"api","status":"error"}
+{"id":"2","message":"b","service":"web","status":"error"}
+{"id":"3","message":"c","service":"api","status":"info"}
+`
+	cfg := &config.Config{OutputFormat: "text", Tags: "service:api", LogLevel: "error"}
+	if err := cfg.ValidateOffline(); err != nil {
+		t.Fatalf("ValidateOffline() error = %v", err)
+	}
+	sink := &recordingSink{}
+	client := &Client{config: cfg}
+	client.AddSink(sink)
+	client.DisableStdout()
+
+	stats, err := client.Replay(context.Background(), strings.NewReader(input), ReplayOptions{})
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if stats.Read != 3 || stats.Emitted != 1 {
+		t.Errorf("Replay() stats = %+v, want 3 read and 1 emitted", stats)
+	}
+	if len(sink.logs) != 1 || sink.logs[0].GetID() != "1" {
+		t.Errorf("sink received %v, want only log 1", sink.logs)
+	}
+}
+
+func TestClient_Replay_Redact(t *testing.T) {
+	redactor, err := redact.New(nil)
+	if err != nil {
+		t.Fatalf("redact.New() error = %v", err)
+	}
+	sink := &recordingSink{}
+	client := &Client{config: &config.Config{}, redactor: redactor}
+	client.AddSink(sink)
+	client.DisableStdout()
+
+	input := `{"id":"1","message":"welcome jane@example.com"}`
+	if _, err := client.Replay(context.Background(), strings.NewReader(input), ReplayOptions{}); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if got := sink.logs[0].GetMessage(); got != "welcome [REDACTED:email]" {
+		t.Errorf("Message = %q, want redacted email", got)
+	}
+}
+
+func TestClient_Replay_Speed(t *testing.T) {
+	// Two seconds apart replayed at 20x take 100ms
+	input := `{"id":"1","timestamp":1705312800,"message":"a"}
+{"id":"2","timestamp":1705312802,"message":"b"}
+`
+	client := &Client{config: &config.Config{}}
+	client.DisableStdout()
+
+	start := time.Now()
+	if _, err := client.Replay(context.Background(), strings.NewReader(input), ReplayOptions{Speed: 20}); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Replay() took %v, want about 100ms", elapsed)
+	}
+
+	// Cancelling the context stops the replay while waiting
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	stats, err := client.Replay(ctx, strings.NewReader(input), ReplayOptions{Speed: 1})
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if stats.Emitted != 1 {
+		t.Errorf("Replay() emitted %d logs, want 1 before the cancelled wait", stats.Emitted)
+	}
+}
+
+func TestClient_Replay_Invalid(t *testing.T) {
+	client := &Client{config: &config.Config{}}
+	client.DisableStdout()
+
+	tests := []struct {
+		name          string
+		input         string
+		errorContains string
+	}{
+		{name: "truncated", input: `{"id":"1"`, errorContains: "failed to parse exported logs"},
+		{name: "not an object", input: `[1,2]`, errorContains: "expected a JSON object"},
+		{name: "invalid response", input: `{"data":"x"}`, errorContains: "invalid API response"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Replay(context.Background(), strings.NewReader(tt.input), ReplayOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Replay() error = %v, want error containing %q", err, tt.errorContains)
+			}
+		})
+	}
+}
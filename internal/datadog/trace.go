@@ -0,0 +1,166 @@
+package datadog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// ErrTraceNotFound is returned when no logs carry a trace ID
+var ErrTraceNotFound = errors.New("no logs found for trace")
+
+const (
+	// maxTraceLogs bounds the number of logs fetched for a single trace
+	maxTraceLogs = 5000
+	// tracePageSize is the page size used while fetching trace logs
+	tracePageSize = 1000
+	// traceEdgeMargin is how close to the start of the searched window the
+	// oldest log may be before the window is widened further
+	traceEdgeMargin = time.Minute
+)
+
+// traceWindows are the lookbacks searched in turn until logs are found
+var traceWindows = []time.Duration{
+	15 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+	3 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+// TraceOptions controls how the logs of a trace are searched
+type TraceOptions struct {
+	// To is the end of the searched range (default: now)
+	To time.Time
+	// MaxLookback bounds how far back the search window is widened
+	MaxLookback time.Duration
+}
+
+// TraceLog is a log of a trace with its full-precision timestamp
+type TraceLog struct {
+	LogEntry
+	Time time.Time `json:"-"`
+}
+
+// Trace holds the logs of an APM trace, oldest first
+type Trace struct {
+	ID   string
+	Logs []TraceLog
+	// From and To are the searched range
+	From time.Time
+	To   time.Time
+	// Truncated is set when the trace had more than maxTraceLogs logs
+	Truncated bool
+}
+
+// GetTrace retrieves the logs carrying the given trace ID (dd.trace_id)
+// across all services. The search starts with a short window that is widened
+// until logs are found and the oldest of them is not at the edge of the
+// window, so that the start of the trace is not cut off.
+func (c *Client) GetTrace(ctx context.Context, id string, opts TraceOptions) (*Trace, error) {
+	to := opts.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	trace := &Trace{ID: id, To: to}
+	query := "@dd.trace_id:" + quoteQueryValue(id)
+	seen := make(map[string]bool)
+
+	// Each step only searches the part of the window not searched yet
+	end := to
+	for _, window := range lookbackWindows(opts.MaxLookback) {
+		from := to.Add(-window)
+		logs, truncated, err := c.searchTrace(ctx, query, from, end, maxTraceLogs-len(trace.Logs))
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range logs {
+			if !seen[l.ID] {
+				seen[l.ID] = true
+				trace.Logs = append(trace.Logs, l)
+			}
+		}
+		trace.From = from
+		if truncated {
+			trace.Truncated = true
+			break
+		}
+		if len(trace.Logs) > 0 && oldest(trace.Logs).Sub(from) > traceEdgeMargin {
+			break
+		}
+		slog.DebugContext(ctx, "Widening trace search window", "trace_id", id, "window", window, "logs", len(trace.Logs))
+		end = from
+	}
+
+	if len(trace.Logs) == 0 {
+		return nil, fmt.Errorf("%w: %s (searched %s to %s)", ErrTraceNotFound, id,
+			trace.From.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339))
+	}
+
+	sort.SliceStable(trace.Logs, func(i, j int) bool {
+		return trace.Logs[i].Time.Before(trace.Logs[j].Time)
+	})
+	return trace, nil
+}
+
+// searchTrace fetches up to limit logs matching the query in ascending
+// order, reporting whether more logs were available
+func (c *Client) searchTrace(ctx context.Context, query string, from, to time.Time, limit int) ([]TraceLog, bool, error) {
+	var logs []TraceLog
+	cursor := ""
+	for {
+		v2resp, err := c.searchV2(ctx, searchRequest{
+			Query:  query,
+			From:   from,
+			To:     to,
+			Cursor: cursor,
+			Limit:  min(tracePageSize, limit-len(logs)),
+			Sort:   "timestamp",
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		for _, d := range v2resp.Data {
+			ts, _ := time.Parse(time.RFC3339Nano, d.Attrs.Timestamp)
+			logs = append(logs, TraceLog{LogEntry: c.toLogEntry(d), Time: ts})
+		}
+		cursor = v2resp.Meta.Page.After
+		if cursor == "" || len(v2resp.Data) == 0 {
+			return logs, false, nil
+		}
+		if len(logs) >= limit {
+			return logs, true, nil
+		}
+	}
+}
+
+// lookbackWindows returns the trace search windows up to maxLookback,
+// ending with maxLookback itself
+func lookbackWindows(maxLookback time.Duration) []time.Duration {
+	if maxLookback <= 0 {
+		return traceWindows
+	}
+	var windows []time.Duration
+	for _, w := range traceWindows {
+		if w >= maxLookback {
+			break
+		}
+		windows = append(windows, w)
+	}
+	return append(windows, maxLookback)
+}
+
+// oldest returns the time of the oldest log
+func oldest(logs []TraceLog) time.Time {
+	t := logs[0].Time
+	for _, l := range logs[1:] {
+		if l.Time.Before(t) {
+			t = l.Time
+		}
+	}
+	return t
+}
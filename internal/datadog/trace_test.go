@@ -0,0 +1,176 @@
+package datadog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+)
+
+// rangeServer serves the logs whose timestamps fall within the requested
+// range and records the searched ranges and queries
+type rangeServer struct {
+	mu      sync.Mutex
+	logs    []map[string]interface{}
+	ranges  []string
+	queries []string
+}
+
+func (s *rangeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Filter struct {
+			From  string `json:"from"`
+			To    string `json:"to"`
+			Query string `json:"query"`
+		} `json:"filter"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	from, _ := time.Parse(time.RFC3339, body.Filter.From)
+	to, _ := time.Parse(time.RFC3339, body.Filter.To)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ranges = append(s.ranges, body.Filter.From+"/"+body.Filter.To)
+	s.queries = append(s.queries, body.Filter.Query)
+
+	data := []map[string]interface{}{}
+	for _, l := range s.logs {
+		ts, _ := time.Parse(time.RFC3339Nano, l["attributes"].(map[string]interface{})["timestamp"].(string))
+		if !ts.Before(from) && !ts.After(to) {
+			data = append(data, l)
+		}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+func traceTestLog(id string, ts time.Time, service string) map[string]interface{} {
+	l := v2TestLog(id, ts, id)
+	attrs := l["attributes"].(map[string]interface{})
+	attrs["service"] = service
+	attrs["attributes"] = map[string]interface{}{"dd": map[string]interface{}{"trace_id": "4242"}}
+	return l
+}
+
+func TestClient_GetTrace(t *testing.T) {
+	to := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		logs       []map[string]interface{}
+		wantIDs    string
+		wantRanges []string
+	}{
+		{
+			name: "Found in the first window",
+			logs: []map[string]interface{}{
+				traceTestLog("c", to.Add(-5*time.Minute+300*time.Millisecond), "api"),
+				traceTestLog("a", to.Add(-5*time.Minute), "api"),
+				traceTestLog("b", to.Add(-5*time.Minute+120*time.Millisecond), "payments"),
+			},
+			wantIDs:    "a,b,c",
+			wantRanges: []string{"2024-01-15T11:45:00Z/2024-01-15T12:00:00Z"},
+		},
+		{
+			name: "Widened when the trace starts at the window edge",
+			logs: []map[string]interface{}{
+				traceTestLog("a", to.Add(-15*time.Minute-10*time.Second), "api"),
+				traceTestLog("b", to.Add(-15*time.Minute+10*time.Second), "payments"),
+			},
+			wantIDs: "a,b",
+			wantRanges: []string{
+				"2024-01-15T11:45:00Z/2024-01-15T12:00:00Z",
+				"2024-01-15T11:00:00Z/2024-01-15T11:45:00Z",
+			},
+		},
+		{
+			name: "Widened until found",
+			logs: []map[string]interface{}{
+				traceTestLog("a", to.Add(-3*time.Hour), "api"),
+			},
+			wantIDs: "a",
+			wantRanges: []string{
+				"2024-01-15T11:45:00Z/2024-01-15T12:00:00Z",
+				"2024-01-15T11:00:00Z/2024-01-15T11:45:00Z",
+				"2024-01-15T06:00:00Z/2024-01-15T11:00:00Z",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &rangeServer{logs: tt.logs}
+			server := httptest.NewServer(srv)
+			defer server.Close()
+
+			client := &Client{config: &config.Config{}, httpClient: server.Client(), baseURL: server.URL}
+			trace, err := client.GetTrace(context.Background(), "4242", TraceOptions{To: to, MaxLookback: 24 * time.Hour})
+			if err != nil {
+				t.Fatalf("GetTrace() error = %v", err)
+			}
+
+			var ids []string
+			for _, l := range trace.Logs {
+				ids = append(ids, l.ID)
+				if l.TraceID != "4242" {
+					t.Errorf("log %s trace ID = %q, want 4242", l.ID, l.TraceID)
+				}
+			}
+			if got := strings.Join(ids, ","); got != tt.wantIDs {
+				t.Errorf("logs = %s, want %s", got, tt.wantIDs)
+			}
+			if !reflect.DeepEqual(srv.ranges, tt.wantRanges) {
+				t.Errorf("searched ranges = %v, want %v", srv.ranges, tt.wantRanges)
+			}
+			for _, q := range srv.queries {
+				if q != "@dd.trace_id:4242" {
+					t.Errorf("query = %q, want @dd.trace_id:4242", q)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_GetTrace_NotFound(t *testing.T) {
+	srv := &rangeServer{}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	client := &Client{config: &config.Config{}, httpClient: server.Client(), baseURL: server.URL}
+	_, err := client.GetTrace(context.Background(), "4242", TraceOptions{To: time.Now(), MaxLookback: 2 * time.Hour})
+	if !errors.Is(err, ErrTraceNotFound) {
+		t.Fatalf("GetTrace() error = %v, want ErrTraceNotFound", err)
+	}
+	// 15m, 1h and the 2h maximum
+	if len(srv.ranges) != 3 {
+		t.Errorf("searched %d windows, want 3: %v", len(srv.ranges), srv.ranges)
+	}
+}
+
+func TestLookbackWindows(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxLookback time.Duration
+		want        []time.Duration
+	}{
+		{"Default", 0, traceWindows},
+		{"Between windows", 2 * time.Hour, []time.Duration{15 * time.Minute, time.Hour, 2 * time.Hour}},
+		{"Equal to a window", 6 * time.Hour, []time.Duration{15 * time.Minute, time.Hour, 6 * time.Hour}},
+		{"Shorter than the first window", 5 * time.Minute, []time.Duration{5 * time.Minute}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lookbackWindows(tt.maxLookback); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lookbackWindows(%v) = %v, want %v", tt.maxLookback, got, tt.want)
+			}
+		})
+	}
+}
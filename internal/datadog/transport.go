@@ -0,0 +1,98 @@
+package datadog
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+	"github.com/jedipunkz/datadog-log-tail/internal/credentials"
+)
+
+// Connection pool settings. The tail loop talks to a single API host, so a
+// few idle connections kept alive between polls are enough.
+const (
+	maxIdleConns        = 10
+	maxIdleConnsPerHost = 4
+	idleConnTimeout     = 90 * time.Second
+	keepAlive           = 30 * time.Second
+	dialTimeout         = 10 * time.Second
+	tlsHandshakeTimeout = 10 * time.Second
+)
+
+// newTransport creates the HTTP transport used for API requests, applying
+// the proxy, CA certificate and client certificate settings
+func newTransport(cfg *config.Config) (*http.Transport, error) {
+	proxy := http.ProxyFromEnvironment
+	if cfg.GetProxy() != "" {
+		proxyURL, err := url.Parse(cfg.GetProxy())
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: keepAlive,
+	}
+
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdleConns,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       idleConnTimeout,
+		TLSHandshakeTimeout:   tlsHandshakeTimeout,
+		ExpectContinueTimeout: time.Second,
+	}, nil
+}
+
+// newTLSConfig creates the TLS configuration for API requests
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cfg.GetCACert() != "" {
+		pem, err := os.ReadFile(credentials.ExpandHome(cfg.GetCACert()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		// Extend the system roots so that public endpoints keep working
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in CA certificate file %s", cfg.GetCACert())
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.GetClientCert() != "" || cfg.GetClientKey() != "" {
+		cert, err := tls.LoadX509KeyPair(credentials.ExpandHome(cfg.GetClientCert()), credentials.ExpandHome(cfg.GetClientKey()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if cfg.GetInsecureSkipVerify() {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,192 @@
+package datadog
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/config"
+)
+
+// writePEM writes a PEM block to a file in a temporary directory
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// generateClientCert creates a self-signed client certificate and key
+func generateClientCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "dlt-test-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return writePEM(t, "client.pem", "CERTIFICATE", der), writePEM(t, "client-key.pem", "EC PRIVATE KEY", keyDER)
+}
+
+func newTestHTTPClient(t *testing.T, cfg *config.Config) *http.Client {
+	t.Helper()
+	transport, err := newTransport(cfg)
+	if err != nil {
+		t.Fatalf("newTransport() error = %v", err)
+	}
+	return &http.Client{Transport: transport, Timeout: 5 * time.Second}
+}
+
+func TestNewTransport_Pooling(t *testing.T) {
+	transport, err := newTransport(&config.Config{})
+	if err != nil {
+		t.Fatalf("newTransport() error = %v", err)
+	}
+	if transport.MaxIdleConnsPerHost != maxIdleConnsPerHost {
+		t.Errorf("MaxIdleConnsPerHost = %d, want %d", transport.MaxIdleConnsPerHost, maxIdleConnsPerHost)
+	}
+	if transport.IdleConnTimeout != idleConnTimeout {
+		t.Errorf("IdleConnTimeout = %v, want %v", transport.IdleConnTimeout, idleConnTimeout)
+	}
+	if transport.DisableKeepAlives {
+		t.Error("keep-alives should be enabled")
+	}
+	if transport.Proxy == nil {
+		t.Error("Proxy should fall back to the environment")
+	}
+}
+
+func TestNewTransport_CACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	caFile := writePEM(t, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+
+	tests := []struct {
+		name    string
+		config  *config.Config
+		wantErr bool
+	}{
+		{"Untrusted certificate", &config.Config{}, true},
+		{"Custom CA", &config.Config{CACert: caFile}, false},
+		{"Insecure skip verify", &config.Config{InsecureSkipVerify: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := newTestHTTPClient(t, tt.config).Get(server.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+		})
+	}
+}
+
+func TestNewTransport_ClientCert(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "dlt-test-client" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	certFile, keyFile := generateClientCert(t)
+	cfg := &config.Config{InsecureSkipVerify: true, ClientCert: certFile, ClientKey: keyFile}
+
+	resp, err := newTestHTTPClient(t, cfg).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	if _, err := newTestHTTPClient(t, &config.Config{InsecureSkipVerify: true}).Get(server.URL); err == nil {
+		t.Error("Get() without client certificate expected error but got none")
+	}
+}
+
+func TestNewTransport_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	resp, err := newTestHTTPClient(t, &config.Config{Proxy: proxy.URL}).Get("http://api.datadoghq.example/api/v2/logs/events/search")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if proxied != "http://api.datadoghq.example/api/v2/logs/events/search" {
+		t.Errorf("proxy received %q, want the absolute API URL", proxied)
+	}
+}
+
+func TestNewTransport_Errors(t *testing.T) {
+	invalidCA := filepath.Join(t.TempDir(), "invalid.pem")
+	if err := os.WriteFile(invalidCA, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		config        *config.Config
+		errorContains string
+	}{
+		{"Missing CA file", &config.Config{CACert: filepath.Join(t.TempDir(), "missing.pem")}, "failed to read CA certificate"},
+		{"Invalid CA file", &config.Config{CACert: invalidCA}, "no valid certificates"},
+		{"Missing client key", &config.Config{ClientCert: invalidCA, ClientKey: filepath.Join(t.TempDir(), "missing.pem")}, "failed to load client certificate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTransport(tt.config)
+			if err == nil {
+				t.Fatal("newTransport() expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("newTransport() error = %v, want error containing %v", err, tt.errorContains)
+			}
+		})
+	}
+}
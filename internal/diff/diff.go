@@ -0,0 +1,112 @@
+package diff
+
+import (
+	"sort"
+	"time"
+)
+
+// Kind is how a group changed between two windows
+type Kind string
+
+const (
+	KindNew       Kind = "new"
+	KindVanished  Kind = "vanished"
+	KindIncreased Kind = "increased"
+	KindDecreased Kind = "decreased"
+)
+
+// kindOrder is the order in which kinds are reported
+var kindOrder = map[Kind]int{KindNew: 0, KindIncreased: 1, KindDecreased: 2, KindVanished: 3}
+
+// Group is a group of logs (a message pattern or a combination of field
+// values) and its counts in the two windows
+type Group struct {
+	Key       string
+	Before    int
+	After     int
+	ExampleID string
+}
+
+// Options decide which changes are significant
+type Options struct {
+	// Before and After are the window lengths; counts are compared as
+	// rates so that windows of different lengths can be compared
+	Before time.Duration
+	After  time.Duration
+	// Threshold is the rate ratio (or its inverse) from which a group is
+	// reported as increased (or decreased)
+	Threshold float64
+	// MinCount is the count a group must reach in one of the windows to
+	// be reported as increased or decreased. New and vanished groups are
+	// always reported.
+	MinCount int
+}
+
+// Change is a significant change of a group
+type Change struct {
+	Kind      Kind    `json:"change"`
+	Key       string  `json:"group"`
+	Before    int     `json:"before"`
+	After     int     `json:"after"`
+	Ratio     float64 `json:"ratio,omitempty"`
+	ExampleID string  `json:"example_id,omitempty"`
+}
+
+// Compare returns the significant changes between the windows: new groups
+// first (most frequent first), then increased and decreased groups (largest
+// change first) and vanished groups
+func Compare(groups []Group, opts Options) []Change {
+	var changes []Change
+	for _, g := range groups {
+		c := Change{Key: g.Key, Before: g.Before, After: g.After, ExampleID: g.ExampleID}
+		switch {
+		case g.Before == 0 && g.After == 0:
+			continue
+		case g.Before == 0:
+			c.Kind = KindNew
+		case g.After == 0:
+			c.Kind = KindVanished
+		default:
+			if max(g.Before, g.After) < opts.MinCount {
+				continue
+			}
+			c.Ratio = rate(g.After, opts.After) / rate(g.Before, opts.Before)
+			switch {
+			case c.Ratio >= opts.Threshold:
+				c.Kind = KindIncreased
+			case c.Ratio <= 1/opts.Threshold:
+				c.Kind = KindDecreased
+			default:
+				continue
+			}
+		}
+		changes = append(changes, c)
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Kind != b.Kind {
+			return kindOrder[a.Kind] < kindOrder[b.Kind]
+		}
+		switch a.Kind {
+		case KindNew:
+			return a.After > b.After
+		case KindVanished:
+			return a.Before > b.Before
+		case KindIncreased:
+			return a.Ratio > b.Ratio
+		default:
+			return a.Ratio < b.Ratio
+		}
+	})
+	return changes
+}
+
+// rate returns a count per hour of a window. A zero window length compares
+// plain counts.
+func rate(count int, window time.Duration) float64 {
+	if window <= 0 {
+		return float64(count)
+	}
+	return float64(count) / window.Hours()
+}
@@ -0,0 +1,42 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	groups := []Group{
+		{Key: "stable", Before: 100, After: 120},
+		{Key: "spike", Before: 10, After: 80, ExampleID: "s1"},
+		{Key: "small spike", Before: 1, After: 4},
+		{Key: "drop", Before: 50, After: 5},
+		{Key: "rare new", After: 1, ExampleID: "n2"},
+		{Key: "new", After: 30, ExampleID: "n1"},
+		{Key: "gone", Before: 7, ExampleID: "g1"},
+		{Key: "double", Before: 10, After: 20},
+	}
+	opts := Options{Before: time.Hour, After: time.Hour, Threshold: 2, MinCount: 5}
+
+	want := []Change{
+		{Kind: KindNew, Key: "new", After: 30, ExampleID: "n1"},
+		{Kind: KindNew, Key: "rare new", After: 1, ExampleID: "n2"},
+		{Kind: KindIncreased, Key: "spike", Before: 10, After: 80, Ratio: 8, ExampleID: "s1"},
+		{Kind: KindIncreased, Key: "double", Before: 10, After: 20, Ratio: 2},
+		{Kind: KindDecreased, Key: "drop", Before: 50, After: 5, Ratio: 0.1},
+		{Kind: KindVanished, Key: "gone", Before: 7, ExampleID: "g1"},
+	}
+	if got := Compare(groups, opts); !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestCompare_WindowLengths(t *testing.T) {
+	// 60 logs in 30 minutes is the same rate as 120 logs in an hour
+	groups := []Group{{Key: "same rate", Before: 120, After: 60}}
+	opts := Options{Before: time.Hour, After: 30 * time.Minute, Threshold: 2, MinCount: 1}
+	if got := Compare(groups, opts); len(got) != 0 {
+		t.Errorf("Compare() = %+v, want no changes", got)
+	}
+}
@@ -0,0 +1,122 @@
+package facetcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// currentVersion is the cache file format version
+const currentVersion = 1
+
+// DefaultTTL is how long cached facet values are used before they are
+// looked up again
+const DefaultTTL = time.Hour
+
+// Entry holds the values of one facet and when they were looked up
+type Entry struct {
+	Values    []string  `json:"values"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Cache maps keys (e.g. "prod/datadoghq.eu/service") to cached facet values
+type Cache struct {
+	Version int              `json:"version"`
+	Entries map[string]Entry `json:"entries"`
+}
+
+// DefaultPath returns the default cache file location
+func DefaultPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "dlt", "facets.json")
+}
+
+// Key returns the cache key of a facet looked up with a profile on a site
+// (or API URL)
+func Key(profile, site, facet string) string {
+	if profile == "" {
+		profile = "default"
+	}
+	return profile + "/" + site + "/" + facet
+}
+
+// Load reads the cache file. A missing file, or one written by an
+// incompatible version, yields an empty cache.
+func Load(path string) (*Cache, error) {
+	empty := &Cache{Version: currentVersion, Entries: map[string]Entry{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return empty, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read facet cache: %w", err)
+	}
+
+	var c Cache
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse facet cache %s: %w", path, err)
+	}
+	if c.Version != currentVersion {
+		return empty, nil
+	}
+	if c.Entries == nil {
+		c.Entries = map[string]Entry{}
+	}
+	return &c, nil
+}
+
+// Get returns the cached values of key and whether they are younger than
+// ttl. Stale values are still returned so that callers can fall back to
+// them when a lookup fails.
+func (c *Cache) Get(key string, ttl time.Duration, now time.Time) ([]string, bool) {
+	e, ok := c.Entries[key]
+	if !ok {
+		return nil, false
+	}
+	return e.Values, now.Sub(e.FetchedAt) < ttl
+}
+
+// Put stores the values of key
+func (c *Cache) Put(key string, values []string, now time.Time) {
+	c.Entries[key] = Entry{Values: values, FetchedAt: now}
+}
+
+// Save writes the cache file atomically
+func Save(path string, c *Cache) error {
+	c.Version = currentVersion
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode facet cache: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary facet cache: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write facet cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close facet cache: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace facet cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package facetcache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "facets.json")
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	c.Put(Key("", "datadoghq.com", "service"), []string{"web", "payments"}, now)
+	c.Put(Key("", "datadoghq.eu", "service"), []string{"eu-web"}, now)
+	if err := Save(path, c); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	c, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	values, fresh := c.Get("default/datadoghq.com/service", DefaultTTL, now.Add(30*time.Minute))
+	if !reflect.DeepEqual(values, []string{"web", "payments"}) || !fresh {
+		t.Errorf("Get() = %v, %v, want fresh cached values", values, fresh)
+	}
+
+	// No temporary files are left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("cache directory has %d entries, want 1", len(entries))
+	}
+}
+
+func TestCache_Get(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	c := &Cache{Entries: map[string]Entry{
+		"prod/datadoghq.com/env": {Values: []string{"prod"}, FetchedAt: now.Add(-2 * time.Hour)},
+	}}
+
+	tests := []struct {
+		name       string
+		key        string
+		wantValues []string
+		wantFresh  bool
+	}{
+		{"Stale values are returned", "prod/datadoghq.com/env", []string{"prod"}, false},
+		{"Missing key", "prod/datadoghq.com/service", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, fresh := c.Get(tt.key, DefaultTTL, now)
+			if !reflect.DeepEqual(values, tt.wantValues) || fresh != tt.wantFresh {
+				t.Errorf("Get(%s) = %v, %v, want %v, %v", tt.key, values, fresh, tt.wantValues, tt.wantFresh)
+			}
+		})
+	}
+
+	if _, fresh := c.Get("prod/datadoghq.com/env", 3*time.Hour, now); !fresh {
+		t.Error("Get() with a longer TTL = stale, want fresh")
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	_ = os.WriteFile(corrupt, []byte("{not json"), 0600)
+	if _, err := Load(corrupt); err == nil || !strings.Contains(err.Error(), "failed to parse facet cache") {
+		t.Errorf("Load(corrupt) error = %v, want parse error", err)
+	}
+
+	// A cache written by another version is discarded rather than an error
+	old := filepath.Join(dir, "old.json")
+	_ = os.WriteFile(old, []byte(`{"version":99,"entries":{"default/service":{"values":["web"]}}}`), 0600)
+	c, err := Load(old)
+	if err != nil {
+		t.Fatalf("Load(old) error = %v", err)
+	}
+	if len(c.Entries) != 0 {
+		t.Errorf("Load(old) entries = %v, want none", c.Entries)
+	}
+}
@@ -0,0 +1,215 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+// Expr is a parsed log search expression that can be evaluated locally
+// against log entries. The syntax follows the Datadog search syntax:
+//
+//	status:error AND @service:payments
+//	service:web (status:warn OR status:error) -env:dev
+//	"connection refused"
+//
+// Terms separated by whitespace are combined with AND. Values may contain
+// the * wildcard. Bare words match the log message (case-insensitive).
+type Expr struct {
+	root node
+	src  string
+}
+
+// Parse parses a search expression. An empty expression matches every entry.
+func Parse(expr string) (*Expr, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	if len(tokens) == 0 {
+		return &Expr{root: matchAll{}, src: expr}, nil
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in query %q", p.tokens[p.pos].text, expr)
+	}
+	return &Expr{root: root, src: expr}, nil
+}
+
+// Match reports whether the log entry satisfies the expression
+func (e *Expr) Match(log output.LogEntry) bool {
+	return e.root.match(log)
+}
+
+// String returns the original expression
+func (e *Expr) String() string {
+	return e.src
+}
+
+type node interface {
+	match(log output.LogEntry) bool
+}
+
+type matchAll struct{}
+
+func (matchAll) match(output.LogEntry) bool { return true }
+
+type andNode struct{ children []node }
+
+func (n andNode) match(log output.LogEntry) bool {
+	for _, c := range n.children {
+		if !c.match(log) {
+			return false
+		}
+	}
+	return true
+}
+
+type orNode struct{ children []node }
+
+func (n orNode) match(log output.LogEntry) bool {
+	for _, c := range n.children {
+		if c.match(log) {
+			return true
+		}
+	}
+	return false
+}
+
+type notNode struct{ child node }
+
+func (n notNode) match(log output.LogEntry) bool {
+	return !n.child.match(log)
+}
+
+// termNode matches a single key:value pair or a free-text word
+type termNode struct {
+	key   string // empty for free text
+	value string
+}
+
+func (n termNode) match(log output.LogEntry) bool {
+	if n.key == "" {
+		return wildcardMatch(strings.ToLower(log.GetMessage()), "*"+strings.ToLower(n.value)+"*")
+	}
+
+	if strings.HasPrefix(n.key, "@") {
+		path := strings.TrimPrefix(n.key, "@")
+		if v, ok := Lookup(log.GetAttributes(), path); ok {
+			return valueMatch(v, n.value)
+		}
+		// Reserved attributes can also be addressed with the @ prefix
+		if v, ok := reservedField(log, path); ok {
+			return wildcardMatch(strings.ToLower(v), strings.ToLower(n.value))
+		}
+		return false
+	}
+
+	if v, ok := reservedField(log, n.key); ok {
+		return wildcardMatch(strings.ToLower(v), strings.ToLower(n.value))
+	}
+
+	// Anything else is matched against the entry's tags
+	for _, tag := range log.GetTags() {
+		k, v, found := strings.Cut(tag, ":")
+		if !found || !strings.EqualFold(k, n.key) {
+			continue
+		}
+		if wildcardMatch(strings.ToLower(v), strings.ToLower(n.value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedField returns the value of a reserved (top-level) log field
+func reservedField(log output.LogEntry, key string) (string, bool) {
+	switch strings.ToLower(key) {
+	case "status":
+		return log.GetStatus(), true
+	case "service":
+		return log.GetService(), true
+	case "message":
+		return log.GetMessage(), true
+	case "id":
+		return log.GetID(), true
+	// Optional fields fall back to tags (e.g. host:web-1) when unset
+	case "host":
+		return log.GetHost(), log.GetHost() != ""
+	case "source":
+		return log.GetSource(), log.GetSource() != ""
+	case "trace_id":
+		return log.GetTraceID(), log.GetTraceID() != ""
+	case "span_id":
+		return log.GetSpanID(), log.GetSpanID() != ""
+	}
+	return "", false
+}
+
+// Lookup resolves a dotted attribute path (e.g. "http.status_code") in a
+// nested attribute map. Keys containing dots are tried as a whole first.
+func Lookup(attrs map[string]interface{}, path string) (interface{}, bool) {
+	if attrs == nil {
+		return nil, false
+	}
+	if v, ok := attrs[path]; ok {
+		return v, true
+	}
+	head, rest, found := strings.Cut(path, ".")
+	for found {
+		if v, ok := attrs[head]; ok {
+			if nested, ok := v.(map[string]interface{}); ok {
+				if r, ok := Lookup(nested, rest); ok {
+					return r, true
+				}
+			}
+		}
+		var next string
+		next, rest, found = strings.Cut(rest, ".")
+		head = head + "." + next
+	}
+	return nil, false
+}
+
+// valueMatch compares an attribute value with a (possibly wildcarded) pattern
+func valueMatch(v interface{}, pattern string) bool {
+	switch val := v.(type) {
+	case []interface{}:
+		for _, item := range val {
+			if valueMatch(item, pattern) {
+				return true
+			}
+		}
+		return false
+	case nil:
+		return false
+	default:
+		return wildcardMatch(strings.ToLower(fmt.Sprint(val)), strings.ToLower(pattern))
+	}
+}
+
+// wildcardMatch matches s against pattern where * matches any sequence
+func wildcardMatch(s, pattern string) bool {
+	if !strings.Contains(pattern, "*") {
+		return s == pattern
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(s, part)
+		if idx < 0 {
+			return false
+		}
+		s = s[idx+len(part):]
+	}
+	return strings.HasSuffix(s, last)
+}
@@ -0,0 +1,153 @@
+package filter
+
+import (
+	"testing"
+)
+
+// Mock LogEntry for testing
+type mockLogEntry struct {
+	id         string
+	timestamp  int64
+	message    string
+	service    string
+	status     string
+	tags       []string
+	attributes map[string]interface{}
+	host       string
+	source     string
+	traceID    string
+	spanID     string
+}
+
+func (m *mockLogEntry) GetID() string                         { return m.id }
+func (m *mockLogEntry) GetTimestamp() int64                   { return m.timestamp }
+func (m *mockLogEntry) GetMessage() string                    { return m.message }
+func (m *mockLogEntry) GetService() string                    { return m.service }
+func (m *mockLogEntry) GetStatus() string                     { return m.status }
+func (m *mockLogEntry) GetTags() []string                     { return m.tags }
+func (m *mockLogEntry) GetAttributes() map[string]interface{} { return m.attributes }
+func (m *mockLogEntry) GetHost() string                       { return m.host }
+func (m *mockLogEntry) GetSource() string                     { return m.source }
+func (m *mockLogEntry) GetTraceID() string                    { return m.traceID }
+func (m *mockLogEntry) GetSpanID() string                     { return m.spanID }
+
+func TestExpr_Match(t *testing.T) {
+	log := &mockLogEntry{
+		id:      "log-1",
+		message: "Connection refused by upstream",
+		service: "payments",
+		status:  "error",
+		tags:    []string{"env:prod", "version:1.2.3"},
+		host:    "web-1",
+		source:  "go",
+		traceID: "1234567890",
+		spanID:  "987",
+		attributes: map[string]interface{}{
+			"service": "payments",
+			"http": map[string]interface{}{
+				"status_code": float64(502),
+				"method":      "POST",
+			},
+			"user.id": "u-42",
+		},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{"Empty query", "", true},
+		{"Status match", "status:error", true},
+		{"Status case insensitive", "status:ERROR", true},
+		{"Status mismatch", "status:info", false},
+		{"Explicit AND", "status:error AND @service:payments", true},
+		{"Implicit AND", "status:error service:payments", true},
+		{"AND with mismatch", "status:error AND service:web", false},
+		{"OR", "status:info OR status:error", true},
+		{"Parentheses", "service:payments (status:warn OR status:error)", true},
+		{"Negation with dash", "-env:dev", true},
+		{"Negation with NOT", "NOT env:prod", false},
+		{"Tag match", "env:prod", true},
+		{"Tag wildcard", "version:1.*", true},
+		{"Tag missing", "team:core", false},
+		{"Nested attribute", "@http.status_code:502", true},
+		{"Nested attribute wildcard", "@http.method:P*", true},
+		{"Dotted attribute key", "@user.id:u-42", true},
+		{"Missing attribute", "@http.url:*", false},
+		{"Host", "host:web-*", true},
+		{"Host mismatch", "host:web-2", false},
+		{"Source", "source:go", true},
+		{"Trace ID", "trace_id:1234567890", true},
+		{"Span ID with @ prefix", "@span_id:987", true},
+		{"Free text", "refused", true},
+		{"Quoted phrase", `"connection refused"`, true},
+		{"Quoted phrase mismatch", `"connection reset"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.query, err)
+			}
+			if got := expr.Match(log); got != tt.want {
+				t.Errorf("Parse(%q).Match() = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"Unterminated quote", `"oops`},
+		{"Missing closing paren", "(status:error"},
+		{"Dangling operator", "status:error AND"},
+		{"Stray closing paren", "status:error)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.query); err == nil {
+				t.Errorf("Parse(%q) expected error but got none", tt.query)
+			}
+		})
+	}
+}
+
+func TestWildcardMatch(t *testing.T) {
+	tests := []struct {
+		s       string
+		pattern string
+		want    bool
+	}{
+		{"payments", "payments", true},
+		{"payments", "pay*", true},
+		{"payments", "*ments", true},
+		{"payments", "p*m*s", true},
+		{"payments", "*", true},
+		{"payments", "web*", false},
+		{"ab", "a*b*b", false},
+	}
+
+	for _, tt := range tests {
+		if got := wildcardMatch(tt.s, tt.pattern); got != tt.want {
+			t.Errorf("wildcardMatch(%q, %q) = %v, want %v", tt.s, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestExpr_Match_HostTagFallback(t *testing.T) {
+	log := &mockLogEntry{tags: []string{"host:web-1"}}
+
+	expr, err := Parse("host:web-1")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !expr.Match(log) {
+		t.Error("host:web-1 should match the host tag when the host is unset")
+	}
+}
@@ -0,0 +1,206 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	quoted bool
+}
+
+// tokenize splits a search expression into tokens
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(expr) {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")"})
+			i++
+		case c == '-':
+			tokens = append(tokens, token{kind: tokNot, text: "-"})
+			i++
+		default:
+			word, n, err := readWord(expr[i:])
+			if err != nil {
+				return nil, err
+			}
+			quoted := c == '"'
+			i += n
+			if quoted {
+				tokens = append(tokens, token{kind: tokWord, text: word, quoted: true})
+				continue
+			}
+			switch word {
+			case "AND":
+				tokens = append(tokens, token{kind: tokAnd, text: word})
+			case "OR":
+				tokens = append(tokens, token{kind: tokOr, text: word})
+			case "NOT":
+				tokens = append(tokens, token{kind: tokNot, text: word})
+			default:
+				tokens = append(tokens, token{kind: tokWord, text: word})
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// readWord reads a single word, honoring double-quoted sections
+func readWord(s string) (string, int, error) {
+	var b strings.Builder
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		if c == ' ' || c == '\t' || c == '\n' || c == '(' || c == ')' {
+			break
+		}
+		if c == '"' {
+			end := strings.IndexByte(s[i+1:], '"')
+			if end < 0 {
+				return "", 0, fmt.Errorf("unterminated quote in query")
+			}
+			b.WriteString(s[i+1 : i+1+end])
+			i += end + 2
+			continue
+		}
+		if c == '\\' && i+1 < len(s) {
+			b.WriteByte(s[i+1])
+			i += 2
+			continue
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String(), i, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// parseOr parses: and ("OR" and)*
+func (p *parser) parseOr() (node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []node{first}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind != tokOr {
+			break
+		}
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return orNode{children: children}, nil
+}
+
+// parseAnd parses: unary (["AND"] unary)*
+func (p *parser) parseAnd() (node, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []node{first}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind == tokOr || tok.kind == tokRParen {
+			break
+		}
+		if tok.kind == tokAnd {
+			p.pos++
+		}
+		next, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return andNode{children: children}, nil
+}
+
+// parseUnary parses: ("NOT" | "-") unary | "(" or ")" | term
+func (p *parser) parseUnary() (node, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	switch tok.kind {
+	case tokNot:
+		p.pos++
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{child: child}, nil
+	case tokLParen:
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing, ok := p.peek()
+		if !ok || closing.kind != tokRParen {
+			return nil, fmt.Errorf("missing closing parenthesis in query")
+		}
+		p.pos++
+		return inner, nil
+	case tokWord:
+		p.pos++
+		if tok.quoted {
+			return termNode{value: tok.text}, nil
+		}
+		return parseTerm(tok.text), nil
+	default:
+		return nil, fmt.Errorf("unexpected %q in query", tok.text)
+	}
+}
+
+// parseTerm splits a word into an optional key and a value
+func parseTerm(word string) node {
+	key, value, found := strings.Cut(word, ":")
+	if !found || key == "" {
+		return termNode{value: word}
+	}
+	return termNode{key: key, value: value}
+}
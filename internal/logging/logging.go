@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// LevelTrace is below slog.LevelDebug and enables full HTTP dumps
+// (redacted headers and response bodies)
+const LevelTrace = slog.LevelDebug - 4
+
+// Options selects the verbosity and destination of diagnostic logs
+type Options struct {
+	// Verbose enables debug logs: API requests, latency, rate limits and cursors
+	Verbose bool
+	// Quiet only logs errors
+	Quiet bool
+	// DebugHTTP additionally dumps request headers and response bodies
+	DebugHTTP bool
+	// File appends logs to a file instead of stderr
+	File string
+}
+
+// Level returns the minimum level selected by the options
+func (o Options) Level() (slog.Level, error) {
+	if o.Quiet && (o.Verbose || o.DebugHTTP) {
+		return 0, fmt.Errorf("--quiet cannot be combined with --verbose or --debug-http")
+	}
+	switch {
+	case o.DebugHTTP:
+		return LevelTrace, nil
+	case o.Verbose:
+		return slog.LevelDebug, nil
+	case o.Quiet:
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, nil
+	}
+}
+
+// Setup creates the logger selected by the options and installs it as the
+// default slog logger. The returned function closes the log file, if any.
+func Setup(o Options) (func() error, error) {
+	level, err := o.Level()
+	if err != nil {
+		return nil, err
+	}
+
+	closer := func() error { return nil }
+	var w io.Writer = os.Stderr
+	withTime := false
+	if o.File != "" {
+		f, err := os.OpenFile(o.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		w, closer, withTime = f, f.Close, true
+	}
+
+	slog.SetDefault(slog.New(NewHandler(w, level, withTime)))
+	return closer, nil
+}
+
+// NewHandler creates a text handler writing records at or above level.
+// Timestamps are omitted unless withTime is set, which keeps interactive
+// output on stderr short.
+func NewHandler(w io.Writer, level slog.Level, withTime bool) slog.Handler {
+	return slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) > 0 {
+				return a
+			}
+			switch a.Key {
+			case slog.TimeKey:
+				if !withTime {
+					return slog.Attr{}
+				}
+			case slog.LevelKey:
+				if l, ok := a.Value.Any().(slog.Level); ok && l <= LevelTrace {
+					return slog.String(slog.LevelKey, "TRACE")
+				}
+			}
+			return a
+		},
+	})
+}
+
+// Enabled reports whether the default logger logs records at level
+func Enabled(level slog.Level) bool {
+	return slog.Default().Enabled(context.Background(), level)
+}
@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOptions_Level(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		want    slog.Level
+		wantErr bool
+	}{
+		{"Default", Options{}, slog.LevelInfo, false},
+		{"Verbose", Options{Verbose: true}, slog.LevelDebug, false},
+		{"Debug HTTP", Options{DebugHTTP: true}, LevelTrace, false},
+		{"Verbose and debug HTTP", Options{Verbose: true, DebugHTTP: true}, LevelTrace, false},
+		{"Quiet", Options{Quiet: true}, slog.LevelError, false},
+		{"Quiet and verbose", Options{Quiet: true, Verbose: true}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.Level()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Level() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Level() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, slog.LevelDebug, false))
+
+	logger.Info("resuming", "from", "2024-01-15T10:00:00Z")
+	logger.Log(context.Background(), LevelTrace, "dropped")
+
+	got := buf.String()
+	if strings.Contains(got, "time=") {
+		t.Errorf("output contains a timestamp: %q", got)
+	}
+	if !strings.Contains(got, "level=INFO msg=resuming from=2024-01-15T10:00:00Z") {
+		t.Errorf("output = %q, want info record", got)
+	}
+	if strings.Contains(got, "dropped") {
+		t.Errorf("output contains a record below the level: %q", got)
+	}
+}
+
+func TestNewHandler_Trace(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(&buf, LevelTrace, true))
+
+	logger.Log(context.Background(), LevelTrace, "HTTP response body")
+
+	got := buf.String()
+	if !strings.Contains(got, "time=") {
+		t.Errorf("output = %q, want a timestamp", got)
+	}
+	if !strings.Contains(got, "level=TRACE") {
+		t.Errorf("output = %q, want level=TRACE", got)
+	}
+}
+
+func TestSetup_File(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+
+	path := filepath.Join(t.TempDir(), "dlt.log")
+	closeLog, err := Setup(Options{Verbose: true, File: path})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	slog.Debug("API request", "method", "POST")
+	if !Enabled(slog.LevelDebug) || Enabled(LevelTrace) {
+		t.Error("Enabled() does not match the verbose level")
+	}
+	if err := closeLog(); err != nil {
+		t.Fatalf("close error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "level=DEBUG msg=\"API request\" method=POST") {
+		t.Errorf("log file = %q, want debug record", data)
+	}
+}
@@ -0,0 +1,237 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// contentType is the Prometheus text exposition format content type
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Registry holds metrics and renders them in the Prometheus text
+// exposition format
+type Registry struct {
+	mu      sync.Mutex
+	metrics []*metric
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Counter is a monotonically increasing metric, optionally with labels
+type Counter struct {
+	m *metric
+}
+
+// Gauge is a metric that can go up and down, optionally with labels
+type Gauge struct {
+	m *metric
+}
+
+// metric is a named family of series keyed by their label values
+type metric struct {
+	mu     sync.Mutex
+	name   string
+	help   string
+	kind   string
+	labels []string
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+	value  float64
+}
+
+// Counter registers a new counter
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{m: r.register(name, help, "counter", labels)}
+}
+
+// Gauge registers a new gauge
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{m: r.register(name, help, "gauge", labels)}
+}
+
+func (r *Registry) register(name, help, kind string, labels []string) *metric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, m := range r.metrics {
+		if m.name == name {
+			panic(fmt.Sprintf("metrics: %s registered twice", name))
+		}
+	}
+	m := &metric{name: name, help: help, kind: kind, labels: labels, series: make(map[string]*series)}
+	r.metrics = append(r.metrics, m)
+	return m
+}
+
+// Inc increments the counter for the given label values by 1
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increments the counter for the given label values by v
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if c == nil || v < 0 {
+		return
+	}
+	c.m.update(labelValues, func(s *series) { s.value += v })
+}
+
+// Set sets the gauge for the given label values
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	if g == nil {
+		return
+	}
+	g.m.update(labelValues, func(s *series) { s.value = v })
+}
+
+// SetDuration sets the gauge to a duration in seconds
+func (g *Gauge) SetDuration(d time.Duration, labelValues ...string) {
+	g.Set(d.Seconds(), labelValues...)
+}
+
+// Value returns the current value for the given label values
+func (c *Counter) Value(labelValues ...string) float64 {
+	if c == nil {
+		return 0
+	}
+	return c.m.value(labelValues)
+}
+
+// Value returns the current value for the given label values
+func (g *Gauge) Value(labelValues ...string) float64 {
+	if g == nil {
+		return 0
+	}
+	return g.m.value(labelValues)
+}
+
+func (m *metric) update(labelValues []string, fn func(*series)) {
+	if len(labelValues) != len(m.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", m.name, len(m.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), labelValues...)}
+		m.series[key] = s
+	}
+	fn(s)
+}
+
+func (m *metric) value(labelValues []string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.series[strings.Join(labelValues, "\xff")]; ok {
+		return s.value
+	}
+	return 0
+}
+
+// WriteText writes all metrics in the Prometheus text exposition format.
+// Series are sorted by label values so that the output is stable.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]*metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.mu.Lock()
+		keys := make([]string, 0, len(m.series))
+		for k := range m.series {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fmt.Fprintf(bw, "# HELP %s %s\n", m.name, escapeHelp(m.help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", m.name, m.kind)
+		if len(m.labels) == 0 && len(keys) == 0 {
+			// Unlabelled metrics are always exposed, starting at zero
+			fmt.Fprintf(bw, "%s 0\n", m.name)
+		}
+		for _, k := range keys {
+			s := m.series[k]
+			bw.WriteString(m.name)
+			if len(m.labels) > 0 {
+				bw.WriteByte('{')
+				for i, label := range m.labels {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					fmt.Fprintf(bw, "%s=\"%s\"", label, escapeLabelValue(s.values[i]))
+				}
+				bw.WriteByte('}')
+			}
+			fmt.Fprintf(bw, " %s\n", formatValue(s.value))
+		}
+		m.mu.Unlock()
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	_ = r.WriteText(w)
+}
+
+// Serve exposes the registry on addr at /metrics. The listener is opened
+// before returning so that address errors are reported immediately.
+func Serve(addr string, r *Registry) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on metrics address: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r)
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() { _ = srv.Serve(ln) }()
+	return srv, nil
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
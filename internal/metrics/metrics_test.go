@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+	logs := r.Counter("dlt_logs_emitted_total", "Log entries emitted.", "service", "status")
+	limited := r.Counter("dlt_api_rate_limited_total", "Rate limited API requests.")
+	interval := r.Gauge("dlt_poll_interval_seconds", "Current poll interval.")
+
+	logs.Inc("web", "info")
+	logs.Inc("web", "info")
+	logs.Add(3, "api", "error")
+	logs.Add(-1, "api", "error") // counters never decrease
+	interval.SetDuration(2500 * time.Millisecond)
+
+	var sb strings.Builder
+	if err := r.WriteText(&sb); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	want := `# HELP dlt_logs_emitted_total Log entries emitted.
+# TYPE dlt_logs_emitted_total counter
+dlt_logs_emitted_total{service="api",status="error"} 3
+dlt_logs_emitted_total{service="web",status="info"} 2
+# HELP dlt_api_rate_limited_total Rate limited API requests.
+# TYPE dlt_api_rate_limited_total counter
+dlt_api_rate_limited_total 0
+# HELP dlt_poll_interval_seconds Current poll interval.
+# TYPE dlt_poll_interval_seconds gauge
+dlt_poll_interval_seconds 2.5
+`
+	if got := sb.String(); got != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", got, want)
+	}
+
+	if v := logs.Value("web", "info"); v != 2 {
+		t.Errorf("Value() = %v, want 2", v)
+	}
+	if v := limited.Value(); v != 0 {
+		t.Errorf("Value() = %v, want 0", v)
+	}
+}
+
+func TestRegistry_Escaping(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("dlt_test_total", "Help with \\ and\nnewline.", "service")
+	c.Inc(`we"b\` + "\n")
+
+	var sb strings.Builder
+	if err := r.WriteText(&sb); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	got := sb.String()
+	if !strings.Contains(got, `# HELP dlt_test_total Help with \\ and\nnewline.`) {
+		t.Errorf("help not escaped:\n%s", got)
+	}
+	if !strings.Contains(got, `dlt_test_total{service="we\"b\\\n"} 1`) {
+		t.Errorf("label value not escaped:\n%s", got)
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var c *Counter
+	var g *Gauge
+	c.Inc()
+	g.Set(1)
+	if c.Value() != 0 || g.Value() != 0 {
+		t.Error("nil metrics should report zero")
+	}
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("dlt_api_requests_total", "API requests.", "code").Inc("200")
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+
+	if ct := resp.Header.Get("Content-Type"); ct != contentType {
+		t.Errorf("Content-Type = %v, want %v", ct, contentType)
+	}
+	if !strings.Contains(string(body), `dlt_api_requests_total{code="200"} 1`) {
+		t.Errorf("body = %s, want request counter", body)
+	}
+}
+
+func TestServe(t *testing.T) {
+	r := NewRegistry()
+	r.Gauge("dlt_lag_seconds", "Lag.").Set(1.5)
+
+	srv, err := Serve("127.0.0.1:0", r)
+	if err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	defer func() { _ = srv.Close() }()
+
+	if _, err := Serve("invalid-address", r); err == nil {
+		t.Error("Serve() with an invalid address expected error but got none")
+	}
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// DiffWindow is one of the compared time windows
+type DiffWindow struct {
+	From time.Time
+	To   time.Time
+	Logs int
+}
+
+// DiffRow is a group of logs that changed between the windows
+type DiffRow struct {
+	Change    string
+	Group     string
+	Before    int
+	After     int
+	Ratio     float64
+	ExampleID string
+}
+
+// WriteDiff writes the changes between two windows as a table. unchanged
+// is the number of groups without a significant change:
+//
+//	Before: 2024-01-15 09:00:00 - 2024-01-15 10:00:00  1200 logs
+//	After:  2024-01-15 10:00:00 - 2024-01-15 11:00:00  1500 logs
+//
+//	CHANGE     BEFORE  AFTER  RATIO  EXAMPLE ID  GROUP
+//	new        0       42     -      AQAAAYx...  payment <NUM> failed
+//	increased  10      80     8.0x   AQAAAYy...  retrying request <*>
+//
+//	12 groups unchanged
+func WriteDiff(w io.Writer, before, after DiffWindow, rows []DiffRow, unchanged int) error {
+	for _, win := range []struct {
+		label string
+		DiffWindow
+	}{{"Before:", before}, {"After: ", after}} {
+		if _, err := fmt.Fprintf(w, "%s %s - %s  %d logs\n", win.label,
+			win.From.Local().Format(time.DateTime), win.To.Local().Format(time.DateTime), win.Logs); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+
+	if len(rows) == 0 {
+		_, err := fmt.Fprintf(w, "No significant changes (%d groups compared)\n", unchanged)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "CHANGE\tBEFORE\tAFTER\tRATIO\tEXAMPLE ID\tGROUP")
+	for _, r := range rows {
+		ratio := "-"
+		if r.Ratio > 0 {
+			ratio = fmt.Sprintf("%.1fx", r.Ratio)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\n", r.Change, r.Before, r.After, ratio, r.ExampleID, r.Group)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if unchanged > 0 {
+		if _, err := fmt.Fprintf(w, "\n%d groups unchanged\n", unchanged); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWriteDiff(t *testing.T) {
+	base := time.Date(2024, 1, 15, 9, 0, 0, 0, time.Local)
+	before := DiffWindow{From: base, To: base.Add(time.Hour), Logs: 120}
+	after := DiffWindow{From: base.Add(time.Hour), To: base.Add(2 * time.Hour), Logs: 150}
+	rows := []DiffRow{
+		{Change: "new", Group: "payment <NUM> failed", After: 42, ExampleID: "a1"},
+		{Change: "increased", Group: "retrying <*>", Before: 10, After: 80, Ratio: 8, ExampleID: "b2"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDiff(&buf, before, after, rows, 12); err != nil {
+		t.Fatalf("WriteDiff() error = %v", err)
+	}
+
+	want := "Before: 2024-01-15 09:00:00 - 2024-01-15 10:00:00  120 logs\n" +
+		"After:  2024-01-15 10:00:00 - 2024-01-15 11:00:00  150 logs\n\n" +
+		"CHANGE     BEFORE  AFTER  RATIO  EXAMPLE ID  GROUP\n" +
+		"new        0       42     -      a1          payment <NUM> failed\n" +
+		"increased  10      80     8.0x   b2          retrying <*>\n" +
+		"\n12 groups unchanged\n"
+	if buf.String() != want {
+		t.Errorf("WriteDiff() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteDiff_NoChanges(t *testing.T) {
+	base := time.Date(2024, 1, 15, 9, 0, 0, 0, time.Local)
+	win := DiffWindow{From: base, To: base.Add(time.Hour), Logs: 10}
+
+	var buf bytes.Buffer
+	if err := WriteDiff(&buf, win, win, nil, 3); err != nil {
+		t.Fatalf("WriteDiff() error = %v", err)
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\nNo significant changes (3 groups compared)\n")) {
+		t.Errorf("WriteDiff() = %q, want no changes message", buf.String())
+	}
+}
@@ -16,6 +16,10 @@ type LogEntry interface {
 	GetStatus() string
 	GetTags() []string
 	GetAttributes() map[string]interface{}
+	GetHost() string
+	GetSource() string
+	GetTraceID() string
+	GetSpanID() string
 }
 
 // Formatter interface for log output formatting
@@ -27,17 +31,34 @@ type Formatter interface {
 type JSONFormatter struct{}
 
 // TextFormatter formats logs as plain text
-type TextFormatter struct{}
+type TextFormatter struct {
+	TextOptions
+}
+
+// TextOptions controls how the text formatter renders messages
+type TextOptions struct {
+	// Multiline selects how messages with embedded newlines are rendered
+	// (raw, indent, escape or first-line)
+	Multiline string
+	// Color dims stack frame lines with ANSI escape codes
+	Color bool
+}
 
 // NewFormatter creates a new formatter based on the specified format
 func NewFormatter(format string) Formatter {
+	return NewFormatterWithOptions(format, TextOptions{})
+}
+
+// NewFormatterWithOptions creates a new formatter based on the specified
+// format. The options only apply to the text format.
+func NewFormatterWithOptions(format string, opts TextOptions) Formatter {
 	switch strings.ToLower(format) {
 	case "json":
 		return &JSONFormatter{}
 	case "text":
-		return &TextFormatter{}
+		return &TextFormatter{TextOptions: opts}
 	default:
-		return &TextFormatter{} // Default to text format
+		return &TextFormatter{TextOptions: opts} // Default to text format
 	}
 }
 
@@ -60,14 +81,35 @@ func (f *TextFormatter) Format(log LogEntry) (string, error) {
 		tagsStr = " [" + strings.Join(log.GetTags(), ", ") + "]"
 	}
 
-	// Format: [timestamp] [level] [service] message [tags]
-	formatted := fmt.Sprintf("[%s] [%s] [%s] %s%s",
+	// Format host, source and trace correlation as key=value fields
+	fieldsStr := ""
+	for _, field := range []struct{ key, value string }{
+		{"host", log.GetHost()},
+		{"source", log.GetSource()},
+		{"trace_id", log.GetTraceID()},
+		{"span_id", log.GetSpanID()},
+	} {
+		if field.value != "" {
+			fieldsStr += " " + field.key + "=" + field.value
+		}
+	}
+
+	message, continuation := f.formatMessage(log.GetMessage())
+
+	// Format: [timestamp] [level] [service] message [tags] host=... source=... trace_id=... span_id=...
+	formatted := fmt.Sprintf("[%s] [%s] [%s] %s%s%s",
 		timestamp,
 		strings.ToUpper(log.GetStatus()),
 		log.GetService(),
-		log.GetMessage(),
+		message,
 		tagsStr,
+		fieldsStr,
 	)
 
+	// Continuation lines follow the header in indent mode
+	if len(continuation) > 0 {
+		formatted += "\n" + strings.Join(continuation, "\n")
+	}
+
 	return formatted, nil
 }
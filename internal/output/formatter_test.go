@@ -15,6 +15,10 @@ type mockLogEntry struct {
 	status     string
 	tags       []string
 	attributes map[string]interface{}
+	host       string
+	source     string
+	traceID    string
+	spanID     string
 }
 
 func (m *mockLogEntry) GetID() string                         { return m.id }
@@ -24,6 +28,10 @@ func (m *mockLogEntry) GetService() string                    { return m.service
 func (m *mockLogEntry) GetStatus() string                     { return m.status }
 func (m *mockLogEntry) GetTags() []string                     { return m.tags }
 func (m *mockLogEntry) GetAttributes() map[string]interface{} { return m.attributes }
+func (m *mockLogEntry) GetHost() string                       { return m.host }
+func (m *mockLogEntry) GetSource() string                     { return m.source }
+func (m *mockLogEntry) GetTraceID() string                    { return m.traceID }
+func (m *mockLogEntry) GetSpanID() string                     { return m.spanID }
 
 func TestNewFormatter(t *testing.T) {
 	tests := []struct {
@@ -212,6 +220,23 @@ func TestTextFormatter_Format(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Host, source and trace correlation",
+			log: &mockLogEntry{
+				timestamp: 1642694400,
+				message:   "Charge failed",
+				service:   "payments",
+				status:    "error",
+				host:      "web-1",
+				source:    "go",
+				traceID:   "1234567890",
+				spanID:    "987",
+			},
+			contains: []string{
+				"Charge failed host=web-1 source=go trace_id=1234567890 span_id=987",
+			},
+			wantErr: false,
+		},
 		{
 			name: "Empty fields",
 			log: &mockLogEntry{
@@ -0,0 +1,105 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Multiline modes select how text output renders messages with embedded
+// newlines, such as stack traces
+const (
+	// MultilineRaw prints messages as they are
+	MultilineRaw = "raw"
+	// MultilineIndent prints continuation lines indented under the header
+	MultilineIndent = "indent"
+	// MultilineEscape writes newlines as \n so every entry is a single line
+	MultilineEscape = "escape"
+	// MultilineFirstLine prints only the first line and the number of lines omitted
+	MultilineFirstLine = "first-line"
+)
+
+// MultilineModes lists the valid multiline modes
+var MultilineModes = []string{MultilineRaw, MultilineIndent, MultilineEscape, MultilineFirstLine}
+
+// IsMultilineMode reports whether mode is a valid multiline mode. An empty
+// mode is the same as raw.
+func IsMultilineMode(mode string) bool {
+	if mode == "" {
+		return true
+	}
+	for _, m := range MultilineModes {
+		if mode == m {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// continuationIndent prefixes continuation lines in indent mode
+	continuationIndent = "    "
+	ansiDim            = "\x1b[2m"
+	ansiReset          = "\x1b[0m"
+)
+
+// stackFrame matches lines of Java, JavaScript, Go and Python stack traces
+var stackFrame = regexp.MustCompile(`^(\s+at\s|\s*\.\.\. \d+ (more|common frames omitted)|\s+\S+\.go:\d+|\s+File ".*", line \d+|\S+/\S+\(.*\)$|\S+\.\S+\(.*\)$)`)
+
+// isStackFrame reports whether a continuation line is part of a stack trace
+func isStackFrame(line string) bool {
+	return stackFrame.MatchString(line)
+}
+
+// splitMessage returns the first line of a message and its continuation
+// lines, without trailing empty lines
+func splitMessage(message string) (string, []string) {
+	lines := strings.Split(strings.TrimRight(message, "\r\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines[0], lines[1:]
+}
+
+// formatMessage renders a message for text output. The header line is
+// returned separately from the continuation lines so that tags and fields
+// can follow the header; continuation lines are empty except in indent mode.
+func (f *TextFormatter) formatMessage(message string) (string, []string) {
+	switch f.Multiline {
+	case MultilineIndent:
+		first, rest := splitMessage(message)
+		for i, line := range rest {
+			rest[i] = continuationIndent + f.dimFrame(line)
+		}
+		return first, rest
+	case MultilineEscape:
+		first, rest := splitMessage(message)
+		for i, line := range rest {
+			rest[i] = f.dimFrame(line)
+		}
+		return strings.Join(append([]string{first}, rest...), `\n`), nil
+	case MultilineFirstLine:
+		first, rest := splitMessage(message)
+		if len(rest) == 0 {
+			return first, nil
+		}
+		return fmt.Sprintf("%s (+%d lines)", first, len(rest)), nil
+	default:
+		if !f.Color || !strings.Contains(message, "\n") {
+			return message, nil
+		}
+		lines := strings.Split(message, "\n")
+		for i, line := range lines[1:] {
+			lines[i+1] = f.dimFrame(line)
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+}
+
+// dimFrame dims a stack frame line when color output is enabled
+func (f *TextFormatter) dimFrame(line string) string {
+	if f.Color && isStackFrame(line) {
+		return ansiDim + line + ansiReset
+	}
+	return line
+}
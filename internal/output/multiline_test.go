@@ -0,0 +1,130 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const javaStackTrace = "java.lang.IllegalStateException: boom\n" +
+	"\tat com.example.Service.run(Service.java:42)\n" +
+	"\tat com.example.Main.main(Main.java:7)\n" +
+	"Caused by: java.io.IOException: closed\n" +
+	"\t... 2 more\n"
+
+func TestTextFormatter_Multiline(t *testing.T) {
+	ts := time.Date(2024, 1, 15, 10, 0, 0, 0, time.Local).Unix()
+	log := &mockLogEntry{
+		timestamp: ts,
+		message:   javaStackTrace,
+		service:   "api",
+		status:    "error",
+		tags:      []string{"env:prod"},
+	}
+	header := "[2024-01-15 10:00:00] [ERROR] [api] "
+
+	tests := []struct {
+		name string
+		opts TextOptions
+		want string
+	}{
+		{
+			name: "Raw",
+			opts: TextOptions{Multiline: MultilineRaw},
+			want: header + javaStackTrace + " [env:prod]",
+		},
+		{
+			name: "Indent",
+			opts: TextOptions{Multiline: MultilineIndent},
+			want: header + "java.lang.IllegalStateException: boom [env:prod]\n" +
+				"    \tat com.example.Service.run(Service.java:42)\n" +
+				"    \tat com.example.Main.main(Main.java:7)\n" +
+				"    Caused by: java.io.IOException: closed\n" +
+				"    \t... 2 more",
+		},
+		{
+			name: "Escape",
+			opts: TextOptions{Multiline: MultilineEscape},
+			want: header + `java.lang.IllegalStateException: boom\n` +
+				`	at com.example.Service.run(Service.java:42)\n` +
+				`	at com.example.Main.main(Main.java:7)\n` +
+				`Caused by: java.io.IOException: closed\n` +
+				`	... 2 more [env:prod]`,
+		},
+		{
+			name: "First line",
+			opts: TextOptions{Multiline: MultilineFirstLine},
+			want: header + "java.lang.IllegalStateException: boom (+4 lines) [env:prod]",
+		},
+		{
+			name: "Indent with color dims stack frames",
+			opts: TextOptions{Multiline: MultilineIndent, Color: true},
+			want: header + "java.lang.IllegalStateException: boom [env:prod]\n" +
+				"    " + ansiDim + "\tat com.example.Service.run(Service.java:42)" + ansiReset + "\n" +
+				"    " + ansiDim + "\tat com.example.Main.main(Main.java:7)" + ansiReset + "\n" +
+				"    Caused by: java.io.IOException: closed\n" +
+				"    " + ansiDim + "\t... 2 more" + ansiReset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewFormatterWithOptions("text", tt.opts).Format(log)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Format() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextFormatter_Multiline_SingleLine(t *testing.T) {
+	log := &mockLogEntry{message: "all good\n", service: "api", status: "info"}
+
+	for _, mode := range []string{MultilineIndent, MultilineEscape, MultilineFirstLine} {
+		got, err := NewFormatterWithOptions("text", TextOptions{Multiline: mode, Color: true}).Format(log)
+		if err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		if !strings.HasSuffix(got, "] all good") {
+			t.Errorf("%s: Format() = %q, want single line without marker", mode, got)
+		}
+	}
+}
+
+func TestIsStackFrame(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"\tat com.example.Service.run(Service.java:42)", true},
+		{"    at handler (/app/index.js:10:5)", true},
+		{"\t... 12 more", true},
+		{"\t/usr/local/go/src/runtime/panic.go:770 +0x124", true},
+		{"main.main()", true},
+		{"github.com/example/app/pkg.(*Server).Serve(0xc000010000)", true},
+		{`  File "/app/main.py", line 3, in <module>`, true},
+		{"Caused by: java.io.IOException: closed", false},
+		{"goroutine 1 [running]:", false},
+		{"retrying in 5s", false},
+	}
+
+	for _, tt := range tests {
+		if got := isStackFrame(tt.line); got != tt.want {
+			t.Errorf("isStackFrame(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestIsMultilineMode(t *testing.T) {
+	for _, mode := range append([]string{""}, MultilineModes...) {
+		if !IsMultilineMode(mode) {
+			t.Errorf("IsMultilineMode(%q) = false, want true", mode)
+		}
+	}
+	if IsMultilineMode("fold") {
+		t.Error("IsMultilineMode(fold) = true, want false")
+	}
+}
@@ -0,0 +1,57 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// PatternRow is a message template and the logs matching it
+type PatternRow struct {
+	Template  string
+	Count     int
+	FirstSeen time.Time
+	LastSeen  time.Time
+	ExampleID string
+}
+
+// WritePatterns writes message templates as a table, in the given order.
+// logs and patterns are the totals the rows were taken from:
+//
+//	1200 logs in 3 patterns
+//
+//	COUNT  SHARE  FIRST SEEN           LAST SEEN            EXAMPLE ID  TEMPLATE
+//	900    75.0%  2024-01-15 10:00:03  2024-01-15 10:59:58  AQAAAYx...  payment <NUM> failed: card declined
+func WritePatterns(w io.Writer, rows []PatternRow, logs, patterns int) error {
+	if logs == 0 {
+		_, err := fmt.Fprintln(w, "No logs found")
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%d logs in %d patterns\n\n", logs, patterns); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "COUNT\tSHARE\tFIRST SEEN\tLAST SEEN\tEXAMPLE ID\tTEMPLATE")
+	for _, r := range rows {
+		_, _ = fmt.Fprintf(tw, "%d\t%.1f%%\t%s\t%s\t%s\t%s\n",
+			r.Count,
+			100*float64(r.Count)/float64(logs),
+			r.FirstSeen.Local().Format(time.DateTime),
+			r.LastSeen.Local().Format(time.DateTime),
+			r.ExampleID,
+			r.Template,
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(rows) < patterns {
+		if _, err := fmt.Fprintf(w, "\n%d more patterns not shown\n", patterns-len(rows)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWritePatterns(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.Local)
+	rows := []PatternRow{
+		{Template: "payment <NUM> failed", Count: 3, FirstSeen: base, LastSeen: base.Add(time.Minute), ExampleID: "a1"},
+		{Template: "cache warmed", Count: 1, FirstSeen: base, LastSeen: base, ExampleID: "b2"},
+	}
+
+	var buf bytes.Buffer
+	if err := WritePatterns(&buf, rows, 5, 3); err != nil {
+		t.Fatalf("WritePatterns() error = %v", err)
+	}
+
+	want := "5 logs in 3 patterns\n\n" +
+		"COUNT  SHARE  FIRST SEEN           LAST SEEN            EXAMPLE ID  TEMPLATE\n" +
+		"3      60.0%  2024-01-15 10:00:00  2024-01-15 10:01:00  a1          payment <NUM> failed\n" +
+		"1      20.0%  2024-01-15 10:00:00  2024-01-15 10:00:00  b2          cache warmed\n" +
+		"\n1 more patterns not shown\n"
+	if buf.String() != want {
+		t.Errorf("WritePatterns() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWritePatterns_NoLogs(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePatterns(&buf, nil, 0, 0); err != nil {
+		t.Fatalf("WritePatterns() error = %v", err)
+	}
+	if buf.String() != "No logs found\n" {
+		t.Errorf("WritePatterns() = %q, want no logs message", buf.String())
+	}
+}
@@ -0,0 +1,100 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// TimelineStep is a single log of a trace timeline
+type TimelineStep struct {
+	Time time.Time
+	Log  LogEntry
+}
+
+// WriteTimeline writes the steps of a trace, oldest first, as a timeline.
+// Each service is indented by the order in which it first appears, and every
+// step shows the time elapsed since the previous one:
+//
+//	Trace 1234: 3 logs from 2 services in 352ms (2024-01-15)
+//
+//	10:00:00.120          api [INFO] GET /checkout
+//	10:00:00.132   +12ms    payments [INFO] charging card
+//	10:00:00.472  +340ms  api [ERROR] checkout failed
+//
+// Messages are rendered like text output with opts. Continuation lines of
+// multiline messages are aligned to the message column, so stack traces do
+// not break the timeline.
+func WriteTimeline(w io.Writer, traceID string, steps []TimelineStep, opts TextOptions) error {
+	if len(steps) == 0 {
+		_, err := fmt.Fprintf(w, "Trace %s: no logs\n", traceID)
+		return err
+	}
+
+	depth := make(map[string]int)
+	deltas := make([]string, len(steps))
+	width := 0
+	for i, s := range steps {
+		service := s.Log.GetService()
+		if _, ok := depth[service]; !ok {
+			depth[service] = len(depth)
+		}
+		if i > 0 {
+			deltas[i] = "+" + formatDuration(s.Time.Sub(steps[i-1].Time))
+		}
+		width = max(width, len(deltas[i]))
+	}
+
+	first, last := steps[0].Time, steps[len(steps)-1].Time
+	services := "services"
+	if len(depth) == 1 {
+		services = "service"
+	}
+	logs := "logs"
+	if len(steps) == 1 {
+		logs = "log"
+	}
+	if _, err := fmt.Fprintf(w, "Trace %s: %d %s from %d %s in %s (%s)\n\n",
+		traceID, len(steps), logs, len(depth), services, formatDuration(last.Sub(first)), first.Local().Format("2006-01-02")); err != nil {
+		return err
+	}
+
+	formatter := &TextFormatter{TextOptions: opts}
+	for i, s := range steps {
+		service := s.Log.GetService()
+		delta := ""
+		if width > 0 {
+			delta = fmt.Sprintf("%*s  ", width, deltas[i])
+		}
+		header := fmt.Sprintf("%s  %s%s%s [%s] ",
+			s.Time.Local().Format("15:04:05.000"),
+			delta,
+			strings.Repeat("  ", depth[service]),
+			service,
+			strings.ToUpper(s.Log.GetStatus()),
+		)
+		message, continuation := formatter.formatMessage(s.Log.GetMessage())
+		lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+		lines = append(lines, continuation...)
+		column := strings.Repeat(" ", utf8.RuneCountInString(header))
+		if _, err := fmt.Fprintln(w, header+lines[0]); err != nil {
+			return err
+		}
+		for _, line := range lines[1:] {
+			if _, err := fmt.Fprintln(w, column+line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formatDuration formats a duration between steps with millisecond precision
+func formatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,119 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteTimeline(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 120e6, time.Local)
+	steps := []TimelineStep{
+		{Time: base, Log: &mockLogEntry{service: "api", status: "info", message: "GET /checkout"}},
+		{Time: base.Add(12 * time.Millisecond), Log: &mockLogEntry{service: "payments", status: "info", message: "charging card"}},
+		{Time: base.Add(352 * time.Millisecond), Log: &mockLogEntry{service: "api", status: "error", message: "checkout failed"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTimeline(&buf, "1234", steps, TextOptions{}); err != nil {
+		t.Fatalf("WriteTimeline() error = %v", err)
+	}
+
+	want := "Trace 1234: 3 logs from 2 services in 352ms (2024-01-15)\n\n" +
+		"10:00:00.120          api [INFO] GET /checkout\n" +
+		"10:00:00.132   +12ms    payments [INFO] charging card\n" +
+		"10:00:00.472  +340ms  api [ERROR] checkout failed\n"
+	if buf.String() != want {
+		t.Errorf("WriteTimeline() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteTimeline_SingleLog(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.Local)
+	steps := []TimelineStep{
+		{Time: base, Log: &mockLogEntry{service: "api", status: "info", message: "done"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTimeline(&buf, "1", steps, TextOptions{}); err != nil {
+		t.Fatalf("WriteTimeline() error = %v", err)
+	}
+
+	want := "Trace 1: 1 log from 1 service in 0s (2024-01-15)\n\n" +
+		"10:00:00.000  api [INFO] done\n"
+	if buf.String() != want {
+		t.Errorf("WriteTimeline() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteTimeline_Multiline(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.Local)
+	steps := []TimelineStep{
+		{Time: base, Log: &mockLogEntry{service: "api", status: "info", message: "GET /checkout"}},
+		{Time: base.Add(5 * time.Millisecond), Log: &mockLogEntry{service: "payments", status: "error", message: "panic: boom\n\tmain.go:12\n"}},
+	}
+	column := strings.Repeat(" ", len("10:00:00.005  +5ms    payments [ERROR] "))
+	header := "Trace 1: 2 logs from 2 services in 5ms (2024-01-15)\n\n" +
+		"10:00:00.000        api [INFO] GET /checkout\n"
+
+	tests := []struct {
+		name string
+		mode string
+		want string
+	}{
+		{
+			name: "Aligns raw continuation lines to the message column",
+			mode: MultilineRaw,
+			want: "10:00:00.005  +5ms    payments [ERROR] panic: boom\n" +
+				column + "\tmain.go:12\n",
+		},
+		{
+			name: "Indents continuation lines under the message",
+			mode: MultilineIndent,
+			want: "10:00:00.005  +5ms    payments [ERROR] panic: boom\n" +
+				column + "    \tmain.go:12\n",
+		},
+		{
+			name: "Escapes newlines",
+			mode: MultilineEscape,
+			want: "10:00:00.005  +5ms    payments [ERROR] panic: boom\\n\tmain.go:12\n",
+		},
+		{
+			name: "Prints only the first line",
+			mode: MultilineFirstLine,
+			want: "10:00:00.005  +5ms    payments [ERROR] panic: boom (+1 lines)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteTimeline(&buf, "1", steps, TextOptions{Multiline: tt.mode}); err != nil {
+				t.Fatalf("WriteTimeline() error = %v", err)
+			}
+			if want := header + tt.want; buf.String() != want {
+				t.Errorf("WriteTimeline() =\n%s\nwant\n%s", buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{1500 * time.Microsecond, "2ms"},
+		{250 * time.Microsecond, "250µs"},
+		{1234 * time.Millisecond, "1.234s"},
+		{90 * time.Second, "1m30s"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
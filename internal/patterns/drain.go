@@ -0,0 +1,178 @@
+package patterns
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Wildcard replaces the tokens in which the messages of a cluster differ
+const Wildcard = "<*>"
+
+// Options tune the clustering
+type Options struct {
+	// Depth is the number of leading tokens used to route a message
+	// through the parse tree before it is compared with clusters
+	Depth int
+	// Similarity is the minimum share of equal tokens for a message to
+	// join a cluster (0 to 1)
+	Similarity float64
+	// MaxChildren bounds the branches per tree node; further tokens are
+	// routed through a wildcard branch
+	MaxChildren int
+}
+
+// DefaultOptions returns the default clustering options
+func DefaultOptions() Options {
+	return Options{Depth: 1, Similarity: 0.4, MaxChildren: 100}
+}
+
+// Cluster is a group of messages sharing a template
+type Cluster struct {
+	ID        int       `json:"id"`
+	Template  string    `json:"template"`
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	ExampleID string    `json:"example_id"`
+
+	tokens []string
+}
+
+// node is a node of the parse tree
+type node struct {
+	children map[string]*node
+	clusters []*Cluster
+}
+
+func newNode() *node {
+	return &node{children: make(map[string]*node)}
+}
+
+// Miner clusters log messages with a Drain-like algorithm: messages are
+// masked and tokenized, routed through a fixed-depth tree by token count
+// and leading tokens, and joined to the most similar cluster of the leaf,
+// whose template then gets wildcards where the messages differ.
+//
+// A Miner is not safe for concurrent use.
+type Miner struct {
+	opts     Options
+	byLength map[int]*node
+	clusters []*Cluster
+}
+
+// NewMiner creates a miner with the given options
+func NewMiner(opts Options) *Miner {
+	return &Miner{opts: opts, byLength: make(map[int]*node)}
+}
+
+// Add clusters a message and returns its cluster
+func (m *Miner) Add(id, message string, t time.Time) *Cluster {
+	tokens := Tokenize(message)
+	leaf := m.leaf(tokens)
+
+	c := m.match(leaf, tokens)
+	if c == nil {
+		c = &Cluster{ID: len(m.clusters) + 1, tokens: tokens, FirstSeen: t, LastSeen: t, ExampleID: id}
+		c.Template = strings.Join(tokens, " ")
+		leaf.clusters = append(leaf.clusters, c)
+		m.clusters = append(m.clusters, c)
+	} else {
+		c.merge(tokens)
+	}
+
+	c.Count++
+	if t.Before(c.FirstSeen) {
+		c.FirstSeen = t
+	}
+	if t.After(c.LastSeen) {
+		c.LastSeen = t
+	}
+	return c
+}
+
+// Clusters returns all clusters, most frequent first
+func (m *Miner) Clusters() []*Cluster {
+	clusters := make([]*Cluster, len(m.clusters))
+	copy(clusters, m.clusters)
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].Count > clusters[j].Count
+	})
+	return clusters
+}
+
+// leaf returns the tree leaf of a token sequence, creating the path
+func (m *Miner) leaf(tokens []string) *node {
+	n, ok := m.byLength[len(tokens)]
+	if !ok {
+		n = newNode()
+		m.byLength[len(tokens)] = n
+	}
+	for i := 0; i < m.opts.Depth && i < len(tokens); i++ {
+		key := tokens[i]
+		child, ok := n.children[key]
+		if !ok {
+			if len(n.children) >= m.opts.MaxChildren {
+				key = Wildcard
+				child = n.children[key]
+			}
+			if child == nil {
+				child = newNode()
+				n.children[key] = child
+			}
+		}
+		n = child
+	}
+	return n
+}
+
+// match returns the most similar cluster of a leaf at or above the
+// similarity threshold, preferring clusters with more wildcards on ties
+func (m *Miner) match(leaf *node, tokens []string) *Cluster {
+	var best *Cluster
+	bestSim, bestParams := -1.0, -1
+	for _, c := range leaf.clusters {
+		sim, params := similarity(c.tokens, tokens)
+		if sim > bestSim || (sim == bestSim && params > bestParams) {
+			best, bestSim, bestParams = c, sim, params
+		}
+	}
+	if best == nil || bestSim < m.opts.Similarity {
+		return nil
+	}
+	return best
+}
+
+// similarity returns the share of template tokens equal to the message
+// tokens, and the number of wildcards in the template. Both sequences have
+// the same length.
+func similarity(template, tokens []string) (float64, int) {
+	if len(template) == 0 {
+		return 1, 0
+	}
+	equal, params := 0, 0
+	for i, t := range template {
+		switch {
+		case t == Wildcard:
+			params++
+		case t == tokens[i]:
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(template)), params
+}
+
+// merge replaces the template tokens that differ from the message with
+// wildcards
+func (c *Cluster) merge(tokens []string) {
+	changed := false
+	for i, t := range c.tokens {
+		if t != Wildcard && t != tokens[i] {
+			c.tokens[i] = Wildcard
+			changed = true
+		}
+	}
+	if changed {
+		c.Template = strings.Join(c.tokens, " ")
+	}
+}
@@ -0,0 +1,89 @@
+package patterns
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMiner(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	messages := []struct {
+		id      string
+		message string
+	}{
+		{"a", "user alice logged in from 10.0.0.1"},
+		{"b", "payment 42 failed: card declined"},
+		{"c", "user bob logged in from 10.0.0.2"},
+		{"d", "payment 43 failed: card declined"},
+		{"e", "user carol logged in from 10.0.0.3"},
+		{"f", "cache warmed"},
+	}
+
+	m := NewMiner(DefaultOptions())
+	for i, msg := range messages {
+		m.Add(msg.id, msg.message, base.Add(time.Duration(i)*time.Minute))
+	}
+
+	clusters := m.Clusters()
+	if len(clusters) != 3 {
+		for _, c := range clusters {
+			t.Logf("%d %s", c.Count, c.Template)
+		}
+		t.Fatalf("got %d clusters, want 3", len(clusters))
+	}
+
+	tests := []struct {
+		template  string
+		count     int
+		firstSeen time.Time
+		lastSeen  time.Time
+		exampleID string
+	}{
+		{"user <*> logged in from <IP>", 3, base, base.Add(4 * time.Minute), "a"},
+		{"payment <NUM> failed: card declined", 2, base.Add(time.Minute), base.Add(3 * time.Minute), "b"},
+		{"cache warmed", 1, base.Add(5 * time.Minute), base.Add(5 * time.Minute), "f"},
+	}
+	for i, tt := range tests {
+		c := clusters[i]
+		if c.Template != tt.template || c.Count != tt.count || c.ExampleID != tt.exampleID {
+			t.Errorf("cluster %d = %q x%d (%s), want %q x%d (%s)", i, c.Template, c.Count, c.ExampleID, tt.template, tt.count, tt.exampleID)
+		}
+		if !c.FirstSeen.Equal(tt.firstSeen) || !c.LastSeen.Equal(tt.lastSeen) {
+			t.Errorf("cluster %d seen %v-%v, want %v-%v", i, c.FirstSeen, c.LastSeen, tt.firstSeen, tt.lastSeen)
+		}
+	}
+}
+
+func TestMiner_Similarity(t *testing.T) {
+	m := NewMiner(DefaultOptions())
+	now := time.Now()
+
+	// Messages of the same length that share too few tokens stay apart
+	m.Add("1", "disk full on node", now)
+	m.Add("2", "disk quota exceeded today", now)
+	if n := len(m.Clusters()); n != 2 {
+		t.Errorf("got %d clusters, want 2", n)
+	}
+
+	// Messages of different lengths never share a cluster
+	m.Add("3", "disk full on node again", now)
+	if n := len(m.Clusters()); n != 3 {
+		t.Errorf("got %d clusters, want 3", n)
+	}
+}
+
+func TestMiner_MaxChildren(t *testing.T) {
+	m := NewMiner(Options{Depth: 1, Similarity: 0.4, MaxChildren: 2})
+	now := time.Now()
+
+	m.Add("1", "alpha started worker", now)
+	m.Add("2", "beta started worker", now)
+	// The third leading token goes through the wildcard branch
+	m.Add("3", "gamma started worker", now)
+	m.Add("4", "delta started worker", now)
+
+	clusters := m.Clusters()
+	if len(clusters) != 3 || clusters[0].Template != "<*> started worker" || clusters[0].Count != 2 {
+		t.Errorf("clusters = %+v, want gamma and delta merged", clusters)
+	}
+}
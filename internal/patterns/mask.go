@@ -0,0 +1,53 @@
+package patterns
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Placeholders of masked variable parts
+const (
+	maskString = "<STR>"
+	maskUUID   = "<UUID>"
+	maskIP     = "<IP>"
+	maskHex    = "<HEX>"
+	maskNumber = "<NUM>"
+)
+
+var (
+	// doubleQuoted matches a double-quoted string with escapes
+	doubleQuoted = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+	// singleQuoted matches a single-quoted string that starts a word, so
+	// that apostrophes (don't) are not taken as quotes
+	singleQuoted = regexp.MustCompile(`(^|[\s=:(\[,])'[^']*'`)
+	uuid         = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+	ipv4         = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?::\d{1,5})?\b`)
+	// hexCandidate matches 0x-prefixed numbers and long runs of hex digits;
+	// runs without a letter are left to the number mask
+	hexCandidate = regexp.MustCompile(`\b(?:0[xX][0-9a-fA-F]+|[0-9a-fA-F]{8,})\b`)
+	// number matches integers and decimals, optionally with a duration unit
+	number = regexp.MustCompile(`\b\d+(?:\.\d+)?(?:ms|us|µs|ns|s|m|h)?\b`)
+)
+
+// Mask replaces the variable parts of a message (quoted strings, UUIDs, IP
+// addresses, hex values and numbers) with placeholders. Only the first line
+// is used, so that stack traces cluster by their error line.
+func Mask(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	line = doubleQuoted.ReplaceAllString(line, maskString)
+	line = singleQuoted.ReplaceAllString(line, "${1}"+maskString)
+	line = uuid.ReplaceAllString(line, maskUUID)
+	line = ipv4.ReplaceAllString(line, maskIP)
+	line = hexCandidate.ReplaceAllStringFunc(line, func(s string) string {
+		if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") || strings.ContainsAny(s, "abcdefABCDEF") {
+			return maskHex
+		}
+		return s
+	})
+	return number.ReplaceAllString(line, maskNumber)
+}
+
+// Tokenize masks a message and splits it into whitespace-separated tokens
+func Tokenize(message string) []string {
+	return strings.Fields(Mask(message))
+}
@@ -0,0 +1,30 @@
+package patterns
+
+import "testing"
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"Numbers and durations", "request took 250ms after 3 retries (1.5 s)", "request took <NUM> after <NUM> retries (<NUM> s)"},
+		{"UUID", "order 3f2504e0-4f89-11d3-9a0c-0305e82c3301 created", "order <UUID> created"},
+		{"IP with port", "connection from 10.0.12.7:51234 refused", "connection from <IP> refused"},
+		{"Hex", "object 0x7ffd5e8c at deadbeef12", "object <HEX> at <HEX>"},
+		{"Long number is not hex", "user 12345678 created", "user <NUM> created"},
+		{"Double-quoted string", `unknown field "first name" in request`, "unknown field <STR> in request"},
+		{"Single-quoted string", "cannot open 'config.yaml' for reading", "cannot open <STR> for reading"},
+		{"Apostrophe", "don't retry", "don't retry"},
+		{"Words with digits are kept", "GET /api/v2/users returned", "GET /api/v2/users returned"},
+		{"Only the first line", "panic: nil map\ngoroutine 1 [running]:", "panic: nil map"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Mask(tt.message); got != tt.want {
+				t.Errorf("Mask(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
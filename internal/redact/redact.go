@@ -0,0 +1,227 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Rule is a custom redaction rule. If the pattern has a capture group, only
+// the first group is replaced (e.g. the token after "password="); otherwise
+// the whole match is.
+type Rule struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+}
+
+// detector is a compiled redaction rule
+type detector struct {
+	name    string
+	re      *regexp.Regexp
+	isValid func(string) bool
+}
+
+// builtins are the detectors that are always enabled
+var builtins = []detector{
+	{name: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)},
+	{name: "bearer_token", re: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`)},
+	{name: "aws_access_key", re: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[A-Z0-9]{16}\b`)},
+	{name: "aws_secret_key", re: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`)},
+	{name: "email", re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	// Card numbers either use card-style grouping (4-4-4-4, 4-4-4-4-3,
+	// 4-6-5 or 4-6-4) or are written without separators
+	{name: "credit_card", re: regexp.MustCompile(`\b(?:\d{4}(?: \d{4}){3}(?: \d{3})?|\d{4}(?:-\d{4}){3}(?:-\d{3})?|\d{4} \d{6} \d{4,5}|\d{4}-\d{6}-\d{4,5}|\d{13,16})\b`), isValid: cardNumber},
+}
+
+// idAttributes are attributes holding numeric IDs that may look like card
+// numbers; they are never redacted so that logs can still be correlated
+var idAttributes = map[string]bool{
+	"dd.trace_id": true,
+	"dd.span_id":  true,
+	"trace_id":    true,
+	"span_id":     true,
+}
+
+// Redactor replaces sensitive data with [REDACTED:<rule>] markers
+type Redactor struct {
+	detectors []detector
+}
+
+// New creates a redactor with the built-in detectors (emails, JWTs, AWS
+// keys, Bearer tokens and credit card numbers) and the given custom rules
+func New(rules []Rule) (*Redactor, error) {
+	detectors := append([]detector(nil), builtins...)
+	for i, rule := range rules {
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("redaction rule %d: pattern is required", i+1)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redaction rule %d: invalid pattern: %w", i+1, err)
+		}
+		name := rule.Name
+		if name == "" {
+			name = "custom"
+		}
+		detectors = append(detectors, detector{name: name, re: re})
+	}
+	return &Redactor{detectors: detectors}, nil
+}
+
+// String redacts a string. A nil redactor returns s unchanged.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, d := range r.detectors {
+		s = d.redact(s)
+	}
+	return s
+}
+
+// Strings redacts a slice of strings, returning a new slice
+func (r *Redactor) Strings(values []string) []string {
+	if r == nil || values == nil {
+		return values
+	}
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = r.String(v)
+	}
+	return out
+}
+
+// Map redacts the string values of nested attributes, returning a copy.
+// Trace and span IDs (dd.trace_id, dd.span_id, trace_id, span_id) are kept.
+func (r *Redactor) Map(attrs map[string]interface{}) map[string]interface{} {
+	if r == nil || attrs == nil {
+		return attrs
+	}
+	return r.mapAt(attrs, "")
+}
+
+// mapAt redacts the attributes nested under the dotted path prefix
+func (r *Redactor) mapAt(attrs map[string]interface{}, prefix string) map[string]interface{} {
+	out := make(map[string]interface{}, len(attrs))
+	for k, v := range attrs {
+		path := prefix + k
+		if idAttributes[path] {
+			out[k] = v
+			continue
+		}
+		out[k] = r.value(v, path)
+	}
+	return out
+}
+
+// value redacts a single attribute value at path
+func (r *Redactor) value(v interface{}, path string) interface{} {
+	switch val := v.(type) {
+	case string:
+		return r.String(val)
+	case map[string]interface{}:
+		return r.mapAt(val, path+".")
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = r.value(item, path)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// redact replaces the matches of a detector, or only their first capture
+// group when the pattern has one
+func (d detector) redact(s string) string {
+	matches := d.re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	marker := "[REDACTED:" + d.name + "]"
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		if d.isValid != nil && !d.isValid(s[start:end]) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(marker)
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// cardNumber reports whether a matched number is likely a card number:
+// grouped numbers need a valid Luhn checksum, and numbers without
+// separators also an issuer prefix and length, so that IDs and epoch
+// timestamps are not mistaken for cards
+func cardNumber(number string) bool {
+	if strings.ContainsAny(number, " -") {
+		return luhn(number)
+	}
+	return issuerLength(number) == len(number) && luhn(number)
+}
+
+// issuerLength returns the card number length of the issuer identified by
+// the prefix of number, or 0 if the prefix belongs to no known issuer
+func issuerLength(number string) int {
+	prefix := func(n int) int {
+		v, _ := strconv.Atoi(number[:n])
+		return v
+	}
+	switch p2 := prefix(2); {
+	case number[0] == '4':
+		// Visa: 13 digits for old cards, 16 otherwise
+		if len(number) == 13 {
+			return 13
+		}
+		return 16
+	case p2 >= 51 && p2 <= 55, prefix(4) >= 2221 && prefix(4) <= 2720:
+		return 16 // Mastercard
+	case p2 == 34 || p2 == 37:
+		return 15 // American Express
+	case p2 == 36 || p2 == 38 || p2 == 39 || (prefix(3) >= 300 && prefix(3) <= 305):
+		return 14 // Diners Club
+	case prefix(4) == 6011, p2 == 65, prefix(3) >= 644 && prefix(3) <= 649, p2 == 62:
+		return 16 // Discover and UnionPay
+	case prefix(4) >= 3528 && prefix(4) <= 3589:
+		return 16 // JCB
+	default:
+		return 0
+	}
+}
+
+// luhn reports whether a number (with optional spaces or dashes) has 13 to
+// 19 digits and a valid Luhn checksum
+func luhn(number string) bool {
+	var digits []int
+	for _, c := range number {
+		if c >= '0' && c <= '9' {
+			digits = append(digits, int(c-'0'))
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := range digits {
+		d := digits[len(digits)-1-i]
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,177 @@
+// Package respcache caches API responses on disk, so that repeated
+// searches of past time windows are answered without requests.
+//
+// Each response is stored in its own file named after the hash of its key.
+// When the cache grows beyond its maximum size, the least recently used
+// responses are removed.
+package respcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultMaxSize is the default maximum cache size in bytes
+const DefaultMaxSize = 256 << 20
+
+// entryExt is the file extension of cached responses
+const entryExt = ".json"
+
+// Cache is an on-disk response cache
+type Cache struct {
+	dir     string
+	maxSize int64
+}
+
+// DefaultDir returns the default cache location
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "dlt", "responses")
+}
+
+// New returns a cache in dir that is kept below maxSize bytes
+func New(dir string, maxSize int64) *Cache {
+	return &Cache{dir: dir, maxSize: maxSize}
+}
+
+// Key returns the cache key of a response to a request identified by parts
+func Key(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the cached response of key
+func (c *Cache) Get(key string) ([]byte, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	// Mark the response as recently used
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return data, true
+}
+
+// Put stores the response of key and removes the least recently used
+// responses if the cache exceeds its maximum size. Responses larger than
+// the maximum size are not stored.
+func (c *Cache) Put(key string, data []byte) error {
+	if int64(len(data)) > c.maxSize {
+		return nil
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create response cache directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, key+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cached response: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cached response: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close cached response: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.path(key)); err != nil {
+		return fmt.Errorf("failed to store cached response: %w", err)
+	}
+	return c.prune()
+}
+
+// prune removes the least recently used responses until the cache fits
+// into its maximum size
+func (c *Cache) prune() error {
+	entries, err := readEntries(c.dir)
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, e := range entries {
+		total += e.size
+	}
+	if total <= c.maxSize {
+		return nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].used.Before(entries[j].used) })
+	for _, e := range entries {
+		if total <= c.maxSize {
+			break
+		}
+		if err := os.Remove(e.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove cached response: %w", err)
+		}
+		total -= e.size
+	}
+	return nil
+}
+
+// path returns the file of a cached response
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+entryExt)
+}
+
+// Clear removes all cached responses in dir and returns how many there were
+// and their total size
+func Clear(dir string) (int, int64, error) {
+	entries, err := readEntries(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	var size int64
+	for i, e := range entries {
+		if err := os.Remove(e.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return i, size, fmt.Errorf("failed to remove cached response: %w", err)
+		}
+		size += e.size
+	}
+	return len(entries), size, nil
+}
+
+// entry is a cached response file
+type entry struct {
+	path string
+	size int64
+	used time.Time
+}
+
+// readEntries lists the cached responses in dir; a missing directory has
+// none
+func readEntries(dir string) ([]entry, error) {
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response cache: %w", err)
+	}
+
+	var entries []entry
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != entryExt {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry{path: filepath.Join(dir, f.Name()), size: info.Size(), used: info.ModTime()})
+	}
+	return entries, nil
+}
@@ -0,0 +1,109 @@
+package respcache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache_GetPut(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "responses"), DefaultMaxSize)
+	key := Key("https://api.datadoghq.com", `{"query":"service:api"}`)
+
+	if _, ok := c.Get(key); ok {
+		t.Fatal("Get() hit on an empty cache")
+	}
+	if err := c.Put(key, []byte(`{"data":[]}`)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	data, ok := c.Get(key)
+	if !ok || string(data) != `{"data":[]}` {
+		t.Errorf("Get() = %q, %v, want the stored response", data, ok)
+	}
+
+	info, err := os.Stat(c.path(key))
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("cached response permissions = %04o, want 0600", perm)
+	}
+}
+
+func TestKey(t *testing.T) {
+	if Key("a", "bc") == Key("ab", "c") {
+		t.Error("Key() is equal for different parts")
+	}
+	if Key("a", "b") != Key("a", "b") {
+		t.Error("Key() differs for equal parts")
+	}
+}
+
+func TestCache_Prune(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, 25)
+
+	// Each response is 10 bytes; "a" is used least recently
+	old := time.Now().Add(-time.Hour)
+	for i, key := range []string{"a", "b"} {
+		if err := c.Put(key, []byte("0123456789")); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+		used := old.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(c.path(key), used, used); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("Get(a) missed")
+	}
+
+	// Using "a" makes "b" the least recently used response
+	if err := c.Put("c", []byte("0123456789")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("Get(%s) hit = %v, want %v", key, ok, want)
+		}
+	}
+
+	// Responses larger than the cache are not stored
+	if err := c.Put("big", make([]byte, 26)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, ok := c.Get("big"); ok {
+		t.Error("Get(big) hit, want responses larger than the cache skipped")
+	}
+}
+
+func TestClear(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir, DefaultMaxSize)
+	for _, key := range []string{"a", "b"} {
+		if err := c.Put(key, []byte("12345")); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	n, size, err := Clear(dir)
+	if err != nil || n != 2 || size != 10 {
+		t.Errorf("Clear() = %d, %d, %v, want 2 responses of 10 bytes", n, size, err)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get(a) hit after Clear()")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("unrelated file was removed: %v", err)
+	}
+
+	// A missing cache is empty
+	if n, _, err := Clear(filepath.Join(dir, "missing")); err != nil || n != 0 {
+		t.Errorf("Clear() = %d, %v, want nothing to clear", n, err)
+	}
+}
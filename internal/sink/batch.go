@@ -0,0 +1,108 @@
+package sink
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+	"github.com/jedipunkz/datadog-log-tail/pkg/utils"
+)
+
+// BatchOptions controls how entries are grouped before being sent
+type BatchOptions struct {
+	Size       int           // Maximum entries per batch
+	Interval   time.Duration // Maximum time an entry waits before being sent
+	MaxRetries int           // Retries for a failed batch before it is dropped
+}
+
+// DefaultBatchOptions returns the default batching options
+func DefaultBatchOptions() BatchOptions {
+	return BatchOptions{
+		Size:       100,
+		Interval:   time.Second,
+		MaxRetries: 3,
+	}
+}
+
+// batcher collects entries in the background and hands them to a send
+// function in batches, retrying failed batches with exponential backoff
+type batcher struct {
+	opts    BatchOptions
+	send    func([]output.LogEntry) error
+	entries chan output.LogEntry
+	done    chan struct{}
+	once    sync.Once
+	sleep   func(time.Duration)
+}
+
+func newBatcher(opts BatchOptions, send func([]output.LogEntry) error) *batcher {
+	b := &batcher{
+		opts:    opts,
+		send:    send,
+		entries: make(chan output.LogEntry, opts.Size*4),
+		done:    make(chan struct{}),
+		sleep:   time.Sleep,
+	}
+	go b.run()
+	return b
+}
+
+// add queues an entry, blocking when the queue is full
+func (b *batcher) add(log output.LogEntry) {
+	b.entries <- log
+}
+
+// close flushes queued entries and stops the background goroutine
+func (b *batcher) close() {
+	b.once.Do(func() {
+		close(b.entries)
+		<-b.done
+	})
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.opts.Interval)
+	defer ticker.Stop()
+
+	batch := make([]output.LogEntry, 0, b.opts.Size)
+	for {
+		select {
+		case log, ok := <-b.entries:
+			if !ok {
+				b.flush(batch)
+				return
+			}
+			batch = append(batch, log)
+			if len(batch) >= b.opts.Size {
+				b.flush(batch)
+				batch = make([]output.LogEntry, 0, b.opts.Size)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				b.flush(batch)
+				batch = make([]output.LogEntry, 0, b.opts.Size)
+			}
+		}
+	}
+}
+
+// flush sends a batch, retrying retryable errors
+func (b *batcher) flush(batch []output.LogEntry) {
+	if len(batch) == 0 {
+		return
+	}
+	for attempt := 0; ; attempt++ {
+		err := b.send(batch)
+		if err == nil {
+			return
+		}
+		if attempt >= b.opts.MaxRetries || !utils.ShouldRetry(err) {
+			slog.Error("Dropping batch of log entries", "entries", len(batch), "error", err)
+			return
+		}
+		b.sleep(utils.CalculateBackoff(attempt + 1))
+	}
+}
@@ -0,0 +1,41 @@
+package sink
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+// File appends newline-delimited log entries to a file
+type File struct {
+	path string
+	file *os.File
+	line *lineWriter
+}
+
+// NewFile creates a new file sink, creating the file if it does not exist
+func NewFile(path string, formatter output.Formatter) (*File, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sink file: %w", err)
+	}
+	return &File{
+		path: path,
+		file: f,
+		line: &lineWriter{w: f, formatter: formatter},
+	}, nil
+}
+
+// Write appends a single log entry
+func (f *File) Write(log output.LogEntry) error {
+	if err := f.line.Write(log); err != nil {
+		return fmt.Errorf("failed to write to %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// Close closes the file
+func (f *File) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,43 @@
+package sink
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+func TestFile_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dlt.log")
+	if err := os.WriteFile(path, []byte("existing\n"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	f, err := NewFile(path, output.NewFormatter("text"))
+	if err != nil {
+		t.Fatalf("NewFile() error = %v", err)
+	}
+	if err := f.Write(newTestLog("1", "first")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := f.Write(newTestLog("2", "second")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("file has %d lines, want 3: %q", len(lines), data)
+	}
+	if lines[0] != "existing" || !strings.Contains(lines[1], "first") || !strings.Contains(lines[2], "second") {
+		t.Errorf("unexpected file content: %q", data)
+	}
+}
@@ -0,0 +1,73 @@
+package sink
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+// HTTP posts batches of log entries as NDJSON to an HTTP endpoint
+type HTTP struct {
+	url        string
+	formatter  output.Formatter
+	httpClient *http.Client
+	batcher    *batcher
+}
+
+// NewHTTP creates a new HTTP sink
+func NewHTTP(url string, formatter output.Formatter, opts BatchOptions) *HTTP {
+	h := &HTTP{
+		url:        url,
+		formatter:  formatter,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	h.batcher = newBatcher(opts, h.send)
+	return h
+}
+
+// Write queues a log entry for the next batch
+func (h *HTTP) Write(log output.LogEntry) error {
+	h.batcher.add(log)
+	return nil
+}
+
+// Close sends any pending entries
+func (h *HTTP) Close() error {
+	h.batcher.close()
+	return nil
+}
+
+// send posts a batch as newline-delimited formatted entries
+func (h *HTTP) send(batch []output.LogEntry) error {
+	var body bytes.Buffer
+	for _, log := range batch {
+		formatted, err := h.formatter.Format(log)
+		if err != nil {
+			return fmt.Errorf("failed to format log: %w", err)
+		}
+		body.WriteString(formatted)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequest("POST", h.url, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute HTTP request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("sink error: %s - %s", resp.Status, string(respBody))
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package sink
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+func TestHTTP_BatchesNDJSON(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+			t.Errorf("Content-Type = %v, want application/x-ndjson", ct)
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	opts := BatchOptions{Size: 2, Interval: time.Hour, MaxRetries: 0}
+	h := NewHTTP(server.URL, output.NewFormatter("json"), opts)
+	for _, id := range []string{"1", "2", "3"} {
+		if err := h.Write(newTestLog(id, "msg "+id)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("server received %d batches, want 2", len(bodies))
+	}
+	lines := strings.Split(strings.TrimSpace(bodies[0]), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("first batch has %d lines, want 2", len(lines))
+	}
+	var got mockLogEntry
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("line is not JSON: %v", err)
+	}
+	if got.ID != "1" {
+		t.Errorf("first entry id = %v, want 1", got.ID)
+	}
+}
+
+func TestHTTP_RetriesServerErrors(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	h := NewHTTP(server.URL, output.NewFormatter("json"), BatchOptions{Size: 10, Interval: time.Hour, MaxRetries: 3})
+	h.batcher.sleep = func(time.Duration) {}
+	_ = h.Write(newTestLog("1", "msg"))
+	_ = h.Close()
+
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
@@ -0,0 +1,341 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+// OTLP encodings
+const (
+	OTLPEncodingProtobuf = "protobuf"
+	OTLPEncodingJSON     = "json"
+)
+
+// scopeName is the instrumentation scope reported in exported logs
+const scopeName = "github.com/jedipunkz/datadog-log-tail"
+
+// OTLP exports log entries to an OpenTelemetry collector using OTLP/HTTP.
+// Entries are mapped to the OpenTelemetry log data model:
+//
+//	Timestamp  -> time_unix_nano
+//	Status     -> severity_text / severity_number
+//	Service    -> service.name resource attribute
+//	Host       -> host.name resource attribute
+//	Source     -> source attribute
+//	TraceID    -> trace_id (Datadog decimal IDs become 128-bit hex)
+//	SpanID     -> span_id
+//	Message    -> body
+//	ID         -> log.record.uid attribute
+//	Tags       -> ddtags attribute (string array)
+//	Attributes -> attributes (nested maps become key/value lists)
+type OTLP struct {
+	url        string
+	encoding   string
+	httpClient *http.Client
+	batcher    *batcher
+	now        func() time.Time
+}
+
+// NewOTLP creates a new OTLP/HTTP sink. encoding is "protobuf" or "json".
+func NewOTLP(url, encoding string, opts BatchOptions) (*OTLP, error) {
+	if encoding != OTLPEncodingProtobuf && encoding != OTLPEncodingJSON {
+		return nil, fmt.Errorf("invalid OTLP encoding: %s (protobuf or json must be specified)", encoding)
+	}
+	o := &OTLP{
+		url:        url,
+		encoding:   encoding,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		now:        time.Now,
+	}
+	o.batcher = newBatcher(opts, o.send)
+	return o, nil
+}
+
+// Write queues a log entry for the next export
+func (o *OTLP) Write(log output.LogEntry) error {
+	o.batcher.add(log)
+	return nil
+}
+
+// Close exports any pending entries
+func (o *OTLP) Close() error {
+	o.batcher.close()
+	return nil
+}
+
+// send exports a batch of log entries
+func (o *OTLP) send(batch []output.LogEntry) error {
+	req := buildExportRequest(batch, o.now())
+
+	var body []byte
+	var contentType string
+	if o.encoding == OTLPEncodingJSON {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("failed to encode OTLP request: %w", err)
+		}
+		body = data
+		contentType = "application/json"
+	} else {
+		body = req.marshalProto()
+		contentType = "application/x-protobuf"
+	}
+
+	httpReq, err := http.NewRequest("POST", o.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+
+	resp, err := o.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to execute HTTP request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("OTLP export error: %s - %s", resp.Status, string(respBody))
+	}
+	return nil
+}
+
+// OTLP log data model (opentelemetry/proto/logs/v1/logs.proto). JSON field
+// names follow the OTLP/HTTP JSON encoding.
+
+type otlpExportRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         uint64         `json:"timeUnixNano,string"`
+	ObservedTimeUnixNano uint64         `json:"observedTimeUnixNano,string"`
+	SeverityNumber       int            `json:"severityNumber,omitempty"`
+	SeverityText         string         `json:"severityText,omitempty"`
+	Body                 otlpAnyValue   `json:"body"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *int64          `json:"intValue,string,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+	KvlistValue *otlpKvlist     `json:"kvlistValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+type otlpKvlist struct {
+	Values []otlpKeyValue `json:"values"`
+}
+
+// resourceKey identifies the resource a log entry belongs to
+type resourceKey struct {
+	service string
+	host    string
+}
+
+// buildExportRequest groups log entries by service and host into resource logs
+func buildExportRequest(batch []output.LogEntry, observed time.Time) otlpExportRequest {
+	var req otlpExportRequest
+	index := make(map[resourceKey]int)
+
+	for _, log := range batch {
+		key := resourceKey{service: log.GetService(), host: log.GetHost()}
+		i, ok := index[key]
+		if !ok {
+			var attrs []otlpKeyValue
+			if key.service != "" {
+				attrs = append(attrs, otlpKeyValue{Key: "service.name", Value: stringValue(key.service)})
+			}
+			if key.host != "" {
+				attrs = append(attrs, otlpKeyValue{Key: "host.name", Value: stringValue(key.host)})
+			}
+			req.ResourceLogs = append(req.ResourceLogs, otlpResourceLogs{
+				Resource:  otlpResource{Attributes: attrs},
+				ScopeLogs: []otlpScopeLogs{{Scope: otlpScope{Name: scopeName}}},
+			})
+			i = len(req.ResourceLogs) - 1
+			index[key] = i
+		}
+		scope := &req.ResourceLogs[i].ScopeLogs[0]
+		scope.LogRecords = append(scope.LogRecords, buildLogRecord(log, observed))
+	}
+	return req
+}
+
+// buildLogRecord maps a single log entry to an OTLP log record
+func buildLogRecord(log output.LogEntry, observed time.Time) otlpLogRecord {
+	record := otlpLogRecord{
+		TimeUnixNano:         uint64(log.GetTimestamp()) * uint64(time.Second),
+		ObservedTimeUnixNano: uint64(observed.UnixNano()),
+		SeverityNumber:       severityNumber(log.GetStatus()),
+		SeverityText:         log.GetStatus(),
+		Body:                 stringValue(log.GetMessage()),
+		TraceID:              traceID(log.GetTraceID()),
+		SpanID:               spanID(log.GetSpanID()),
+	}
+
+	if id := log.GetID(); id != "" {
+		record.Attributes = append(record.Attributes, otlpKeyValue{Key: "log.record.uid", Value: stringValue(id)})
+	}
+	if tags := log.GetTags(); len(tags) > 0 {
+		values := make([]otlpAnyValue, 0, len(tags))
+		for _, tag := range tags {
+			values = append(values, stringValue(tag))
+		}
+		record.Attributes = append(record.Attributes, otlpKeyValue{Key: "ddtags", Value: otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}})
+	}
+	if source := log.GetSource(); source != "" {
+		record.Attributes = append(record.Attributes, otlpKeyValue{Key: "source", Value: stringValue(source)})
+	}
+	record.Attributes = append(record.Attributes, keyValues(log.GetAttributes())...)
+	return record
+}
+
+// traceID converts a Datadog trace ID to a 16-byte OTLP trace ID in hex.
+// Datadog reports 64-bit IDs in decimal; 128-bit IDs in hex are kept as is.
+func traceID(id string) string {
+	return otlpID(id, 16)
+}
+
+// spanID converts a Datadog span ID to an 8-byte OTLP span ID in hex
+func spanID(id string) string {
+	return otlpID(id, 8)
+}
+
+// otlpID returns id as a zero-padded hex string of size bytes, or "" when
+// it is neither a decimal uint64 nor a hex string of that size
+func otlpID(id string, size int) string {
+	if id == "" {
+		return ""
+	}
+	if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+		if n == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%0*x", size*2, n)
+	}
+	if b, err := hex.DecodeString(id); err == nil && len(b) == size {
+		return strings.ToLower(id)
+	}
+	return ""
+}
+
+// severityNumber maps a Datadog status to an OpenTelemetry severity number
+func severityNumber(status string) int {
+	switch strings.ToLower(status) {
+	case "trace":
+		return 1
+	case "debug":
+		return 5
+	case "info", "notice":
+		return 9
+	case "warn", "warning":
+		return 13
+	case "error", "err":
+		return 17
+	case "critical", "crit", "alert":
+		return 18
+	case "emergency", "emerg", "fatal":
+		return 21
+	default:
+		return 0 // unspecified
+	}
+}
+
+// keyValues converts an attribute map to OTLP key/values sorted by key
+func keyValues(attrs map[string]interface{}) []otlpKeyValue {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		if v, ok := anyValue(attrs[k]); ok {
+			kvs = append(kvs, otlpKeyValue{Key: k, Value: v})
+		}
+	}
+	return kvs
+}
+
+// anyValue converts a decoded JSON value to an OTLP AnyValue
+func anyValue(v interface{}) (otlpAnyValue, bool) {
+	switch val := v.(type) {
+	case string:
+		return stringValue(val), true
+	case bool:
+		return otlpAnyValue{BoolValue: &val}, true
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1<<53 {
+			i := int64(val)
+			return otlpAnyValue{IntValue: &i}, true
+		}
+		return otlpAnyValue{DoubleValue: &val}, true
+	case int:
+		i := int64(val)
+		return otlpAnyValue{IntValue: &i}, true
+	case int64:
+		return otlpAnyValue{IntValue: &val}, true
+	case []interface{}:
+		values := make([]otlpAnyValue, 0, len(val))
+		for _, item := range val {
+			if av, ok := anyValue(item); ok {
+				values = append(values, av)
+			}
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}, true
+	case map[string]interface{}:
+		return otlpAnyValue{KvlistValue: &otlpKvlist{Values: keyValues(val)}}, true
+	case nil:
+		return otlpAnyValue{}, false
+	default:
+		return stringValue(fmt.Sprint(val)), true
+	}
+}
+
+func stringValue(s string) otlpAnyValue {
+	return otlpAnyValue{StringValue: &s}
+}
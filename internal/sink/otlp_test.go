@@ -0,0 +1,183 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+func TestBuildExportRequest(t *testing.T) {
+	logs := []*mockLogEntry{
+		{ID: "1", Timestamp: 1642694400, Message: "first", Service: "api", Status: "error", Tags: []string{"env:prod"},
+			Attributes: map[string]interface{}{
+				"http":    map[string]interface{}{"status_code": float64(502)},
+				"latency": 1.5,
+				"retry":   true,
+				"nothing": nil,
+			}},
+		{ID: "2", Timestamp: 1642694401, Message: "second", Service: "web", Status: "info",
+			Host: "web-1", Source: "nginx", TraceID: "1234", SpanID: "5678"},
+		{ID: "3", Timestamp: 1642694402, Message: "third", Service: "api", Status: "warn"},
+	}
+	observed := time.Unix(1700000000, 0)
+	req := buildExportRequest([]output.LogEntry{logs[0], logs[1], logs[2]}, observed)
+
+	if len(req.ResourceLogs) != 2 {
+		t.Fatalf("resource logs = %d, want 2 (grouped by service)", len(req.ResourceLogs))
+	}
+	api := req.ResourceLogs[0]
+	if *api.Resource.Attributes[0].Value.StringValue != "api" || api.Resource.Attributes[0].Key != "service.name" {
+		t.Errorf("resource attribute = %+v, want service.name=api", api.Resource.Attributes[0])
+	}
+	records := api.ScopeLogs[0].LogRecords
+	if len(records) != 2 {
+		t.Fatalf("api log records = %d, want 2", len(records))
+	}
+
+	r := records[0]
+	if r.TimeUnixNano != 1642694400*uint64(time.Second) {
+		t.Errorf("TimeUnixNano = %d, want %d", r.TimeUnixNano, 1642694400*uint64(time.Second))
+	}
+	if r.SeverityNumber != 17 || r.SeverityText != "error" {
+		t.Errorf("severity = (%d, %s), want (17, error)", r.SeverityNumber, r.SeverityText)
+	}
+	if *r.Body.StringValue != "first" {
+		t.Errorf("Body = %v, want first", *r.Body.StringValue)
+	}
+
+	keys := make([]string, 0, len(r.Attributes))
+	for _, kv := range r.Attributes {
+		keys = append(keys, kv.Key)
+	}
+	if got := strings.Join(keys, ","); got != "log.record.uid,ddtags,http,latency,retry" {
+		t.Errorf("attribute keys = %s, want log.record.uid,ddtags,http,latency,retry", got)
+	}
+	web := req.ResourceLogs[1]
+	if len(web.Resource.Attributes) != 2 || web.Resource.Attributes[1].Key != "host.name" || *web.Resource.Attributes[1].Value.StringValue != "web-1" {
+		t.Errorf("resource attributes = %+v, want service.name and host.name", web.Resource.Attributes)
+	}
+	traced := web.ScopeLogs[0].LogRecords[0]
+	if traced.TraceID != "000000000000000000000000000004d2" || traced.SpanID != "000000000000162e" {
+		t.Errorf("trace/span IDs = %s/%s, want zero-padded hex", traced.TraceID, traced.SpanID)
+	}
+	if last := traced.Attributes[len(traced.Attributes)-1]; last.Key != "source" || *last.Value.StringValue != "nginx" {
+		t.Errorf("source attribute = %+v, want source=nginx", last)
+	}
+
+	httpAttr := r.Attributes[2].Value.KvlistValue
+	if httpAttr == nil || *httpAttr.Values[0].Value.IntValue != 502 {
+		t.Errorf("nested attribute not mapped to kvlist with int value: %+v", r.Attributes[2])
+	}
+	if *r.Attributes[3].Value.DoubleValue != 1.5 {
+		t.Errorf("latency = %+v, want double 1.5", r.Attributes[3].Value)
+	}
+}
+
+func TestOTLPID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		size int
+		want string
+	}{
+		{"Decimal", "1234", 8, "00000000000004d2"},
+		{"Max uint64", "18446744073709551615", 16, "0000000000000000ffffffffffffffff"},
+		{"128-bit hex", "6566F6A4D4B3CB1E5BA19E6F1C3CB3E4", 16, "6566f6a4d4b3cb1e5ba19e6f1c3cb3e4"},
+		{"Zero", "0", 8, ""},
+		{"Empty", "", 8, ""},
+		{"Invalid", "not-an-id", 8, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := otlpID(tt.id, tt.size); got != tt.want {
+				t.Errorf("otlpID(%q, %d) = %q, want %q", tt.id, tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOTLPAnyValue_JSONEncoding(t *testing.T) {
+	i := int64(42)
+	data, err := json.Marshal(otlpAnyValue{IntValue: &i})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	// int64 values are encoded as strings in OTLP JSON
+	if string(data) != `{"intValue":"42"}` {
+		t.Errorf("Marshal() = %s, want {\"intValue\":\"42\"}", data)
+	}
+}
+
+func TestOTLPKeyValue_ProtoEncoding(t *testing.T) {
+	var p protoBuffer
+	otlpKeyValue{Key: "a", Value: stringValue("b")}.encode(&p)
+
+	// key (1, "a") + value (2, AnyValue{string_value (1, "b")})
+	want := []byte{0x0a, 0x01, 'a', 0x12, 0x03, 0x0a, 0x01, 'b'}
+	if !bytes.Equal(p.buf, want) {
+		t.Errorf("encode() = % x, want % x", p.buf, want)
+	}
+}
+
+func TestOTLP_Export(t *testing.T) {
+	tests := []struct {
+		name        string
+		encoding    string
+		contentType string
+	}{
+		{"Protobuf", OTLPEncodingProtobuf, "application/x-protobuf"},
+		{"JSON", OTLPEncodingJSON, "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotType string
+			var gotBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/logs" {
+					t.Errorf("path = %v, want /v1/logs", r.URL.Path)
+				}
+				gotType = r.Header.Get("Content-Type")
+				gotBody, _ = io.ReadAll(r.Body)
+			}))
+			defer server.Close()
+
+			s, err := Parse("otlp://" + strings.TrimPrefix(server.URL, "http://") + "?encoding=" + tt.encoding)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			_ = s.Write(newTestLog("1", "hello"))
+			_ = s.Close()
+
+			if gotType != tt.contentType {
+				t.Errorf("Content-Type = %v, want %v", gotType, tt.contentType)
+			}
+			if !bytes.Contains(gotBody, []byte("hello")) {
+				t.Errorf("body does not contain message: %q", gotBody)
+			}
+			if tt.encoding == OTLPEncodingJSON {
+				var req map[string]interface{}
+				if err := json.Unmarshal(gotBody, &req); err != nil {
+					t.Errorf("body is not valid JSON: %v", err)
+				}
+				if _, ok := req["resourceLogs"]; !ok {
+					t.Errorf("JSON body missing resourceLogs: %s", gotBody)
+				}
+			}
+		})
+	}
+}
+
+func TestNewOTLP_InvalidEncoding(t *testing.T) {
+	if _, err := NewOTLP("http://localhost:4318/v1/logs", "xml", DefaultBatchOptions()); err == nil {
+		t.Error("NewOTLP() expected error but got none")
+	}
+}
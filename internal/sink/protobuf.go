@@ -0,0 +1,152 @@
+package sink
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math"
+)
+
+// Minimal protobuf wire format encoder for the OTLP logs messages. Field
+// numbers follow opentelemetry/proto/collector/logs/v1/logs_service.proto,
+// opentelemetry/proto/logs/v1/logs.proto and
+// opentelemetry/proto/common/v1/common.proto.
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+type protoBuffer struct {
+	buf []byte
+}
+
+func (p *protoBuffer) tag(field, wireType int) {
+	p.varint(uint64(field<<3 | wireType))
+}
+
+func (p *protoBuffer) varint(v uint64) {
+	p.buf = binary.AppendUvarint(p.buf, v)
+}
+
+func (p *protoBuffer) uint64Field(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	p.tag(field, wireVarint)
+	p.varint(v)
+}
+
+func (p *protoBuffer) fixed64Field(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	p.tag(field, wireFixed64)
+	p.buf = binary.LittleEndian.AppendUint64(p.buf, v)
+}
+
+func (p *protoBuffer) bytesField(field int, b []byte) {
+	p.tag(field, wireBytes)
+	p.varint(uint64(len(b)))
+	p.buf = append(p.buf, b...)
+}
+
+func (p *protoBuffer) stringField(field int, s string) {
+	if s == "" {
+		return
+	}
+	p.bytesField(field, []byte(s))
+}
+
+// messageField encodes a nested message
+func (p *protoBuffer) messageField(field int, encode func(*protoBuffer)) {
+	var nested protoBuffer
+	encode(&nested)
+	p.bytesField(field, nested.buf)
+}
+
+// marshalProto encodes an ExportLogsServiceRequest
+func (r otlpExportRequest) marshalProto() []byte {
+	var p protoBuffer
+	for _, rl := range r.ResourceLogs {
+		p.messageField(1, rl.encode)
+	}
+	return p.buf
+}
+
+func (rl otlpResourceLogs) encode(p *protoBuffer) {
+	p.messageField(1, rl.Resource.encode)
+	for _, sl := range rl.ScopeLogs {
+		p.messageField(2, sl.encode)
+	}
+}
+
+func (r otlpResource) encode(p *protoBuffer) {
+	for _, kv := range r.Attributes {
+		p.messageField(1, kv.encode)
+	}
+}
+
+func (sl otlpScopeLogs) encode(p *protoBuffer) {
+	p.messageField(1, func(s *protoBuffer) {
+		s.stringField(1, sl.Scope.Name)
+	})
+	for _, lr := range sl.LogRecords {
+		p.messageField(2, lr.encode)
+	}
+}
+
+func (lr otlpLogRecord) encode(p *protoBuffer) {
+	p.fixed64Field(1, lr.TimeUnixNano)
+	p.uint64Field(2, uint64(lr.SeverityNumber))
+	p.stringField(3, lr.SeverityText)
+	p.messageField(5, lr.Body.encode)
+	for _, kv := range lr.Attributes {
+		p.messageField(6, kv.encode)
+	}
+	if traceID, err := hex.DecodeString(lr.TraceID); err == nil && len(traceID) > 0 {
+		p.bytesField(9, traceID)
+	}
+	if spanID, err := hex.DecodeString(lr.SpanID); err == nil && len(spanID) > 0 {
+		p.bytesField(10, spanID)
+	}
+	p.fixed64Field(11, lr.ObservedTimeUnixNano)
+}
+
+func (kv otlpKeyValue) encode(p *protoBuffer) {
+	p.stringField(1, kv.Key)
+	p.messageField(2, kv.Value.encode)
+}
+
+func (v otlpAnyValue) encode(p *protoBuffer) {
+	switch {
+	case v.StringValue != nil:
+		// Empty strings are still encoded so that the oneof is set
+		p.bytesField(1, []byte(*v.StringValue))
+	case v.BoolValue != nil:
+		p.tag(2, wireVarint)
+		if *v.BoolValue {
+			p.varint(1)
+		} else {
+			p.varint(0)
+		}
+	case v.IntValue != nil:
+		p.tag(3, wireVarint)
+		p.varint(uint64(*v.IntValue))
+	case v.DoubleValue != nil:
+		p.tag(4, wireFixed64)
+		p.buf = binary.LittleEndian.AppendUint64(p.buf, math.Float64bits(*v.DoubleValue))
+	case v.ArrayValue != nil:
+		p.messageField(5, func(a *protoBuffer) {
+			for _, item := range v.ArrayValue.Values {
+				a.messageField(1, item.encode)
+			}
+		})
+	case v.KvlistValue != nil:
+		p.messageField(6, func(l *protoBuffer) {
+			for _, kv := range v.KvlistValue.Values {
+				l.messageField(1, kv.encode)
+			}
+		})
+	}
+}
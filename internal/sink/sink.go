@@ -0,0 +1,177 @@
+package sink
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+// Sink is a destination that tailed log entries are forwarded to
+type Sink interface {
+	// Write forwards a single log entry
+	Write(log output.LogEntry) error
+	// Close flushes pending entries and releases resources
+	Close() error
+}
+
+// Parse creates a sink from a URL-style specification.
+//
+// Supported specifications:
+//
+//	syslog://host:514              RFC5424 syslog over UDP
+//	syslog+udp://host:514          RFC5424 syslog over UDP
+//	syslog+tcp://host:514          RFC5424 syslog over TCP (octet counting)
+//	http://host:port/path          HTTP POST batches (NDJSON)
+//	https://host:port/path         HTTP POST batches over TLS (NDJSON)
+//	unix:///path/to.sock           Unix domain socket (newline-delimited)
+//	file:///path/to/file.log       Append to file (newline-delimited)
+//	otlp://host:4318               OpenTelemetry OTLP/HTTP logs export
+//	otlp+https://host:4318         OpenTelemetry OTLP/HTTP logs export over TLS
+//
+// Every sink accepts a format query parameter (json or text) selecting its
+// formatter, e.g. file:///tmp/dlt.log?format=text. OTLP sinks use the
+// encoding query parameter (protobuf or json) instead.
+func Parse(spec string) (Sink, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sink %q: %w", spec, err)
+	}
+	params := u.Query()
+
+	switch strings.ToLower(u.Scheme) {
+	case "syslog", "syslog+udp", "syslog+tcp":
+		network := "udp"
+		if strings.HasSuffix(strings.ToLower(u.Scheme), "+tcp") {
+			network = "tcp"
+		}
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid sink %q: syslog address is required", spec)
+		}
+		formatter, err := formatterParam(params, "text")
+		if err != nil {
+			return nil, fmt.Errorf("invalid sink %q: %w", spec, err)
+		}
+		return NewSyslog(network, u.Host, params.Get("app"), params.Get("facility"), formatter)
+
+	case "http", "https":
+		formatter, err := formatterParam(params, "json")
+		if err != nil {
+			return nil, fmt.Errorf("invalid sink %q: %w", spec, err)
+		}
+		opts, err := batchParams(params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sink %q: %w", spec, err)
+		}
+		// Sink-specific parameters are not forwarded to the endpoint
+		for _, key := range []string{"format", "batch", "interval"} {
+			params.Del(key)
+		}
+		u.RawQuery = params.Encode()
+		return NewHTTP(u.String(), formatter, opts), nil
+
+	case "otlp", "otlp+http", "otlp+https":
+		encoding := params.Get("encoding")
+		if encoding == "" {
+			encoding = OTLPEncodingProtobuf
+		}
+		opts, err := batchParams(params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sink %q: %w", spec, err)
+		}
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid sink %q: collector address is required", spec)
+		}
+		endpoint := url.URL{Scheme: "http", Host: u.Host, Path: u.Path}
+		if strings.HasSuffix(strings.ToLower(u.Scheme), "+https") {
+			endpoint.Scheme = "https"
+		}
+		if endpoint.Path == "" || endpoint.Path == "/" {
+			endpoint.Path = "/v1/logs"
+		}
+		return NewOTLP(endpoint.String(), encoding, opts)
+
+	case "unix":
+		path := u.Path
+		if path == "" {
+			path = u.Opaque
+		}
+		if path == "" {
+			return nil, fmt.Errorf("invalid sink %q: socket path is required", spec)
+		}
+		formatter, err := formatterParam(params, "json")
+		if err != nil {
+			return nil, fmt.Errorf("invalid sink %q: %w", spec, err)
+		}
+		return NewUnix(path, formatter)
+
+	case "file":
+		// Both file:///abs/path and file:relative/path are accepted
+		path := u.Path
+		if path == "" {
+			path = u.Opaque
+		}
+		if path == "" {
+			return nil, fmt.Errorf("invalid sink %q: file path is required", spec)
+		}
+		formatter, err := formatterParam(params, "text")
+		if err != nil {
+			return nil, fmt.Errorf("invalid sink %q: %w", spec, err)
+		}
+		return NewFile(path, formatter)
+
+	default:
+		return nil, fmt.Errorf("unsupported sink %q (use syslog://, syslog+tcp://, http://, https://, otlp://, otlp+https://, unix:// or file://)", spec)
+	}
+}
+
+// formatterParam returns the formatter selected by the format query parameter
+func formatterParam(params url.Values, def string) (output.Formatter, error) {
+	format := params.Get("format")
+	if format == "" {
+		format = def
+	}
+	if format != "json" && format != "text" {
+		return nil, fmt.Errorf("invalid format: %s (json or text must be specified)", format)
+	}
+	return output.NewFormatter(format), nil
+}
+
+// batchParams parses the batch and interval query parameters
+func batchParams(params url.Values) (BatchOptions, error) {
+	opts := DefaultBatchOptions()
+	if v := params.Get("batch"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1 {
+			return opts, fmt.Errorf("invalid batch size: %s", v)
+		}
+		opts.Size = size
+	}
+	if v := params.Get("interval"); v != "" {
+		interval, err := time.ParseDuration(v)
+		if err != nil || interval <= 0 {
+			return opts, fmt.Errorf("invalid batch interval: %s", v)
+		}
+		opts.Interval = interval
+	}
+	return opts, nil
+}
+
+// lineWriter writes formatted entries as newline-delimited lines
+type lineWriter struct {
+	w         io.Writer
+	formatter output.Formatter
+}
+
+func (l *lineWriter) Write(log output.LogEntry) error {
+	formatted, err := l.formatter.Format(log)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(l.w, formatted+"\n")
+	return err
+}
@@ -0,0 +1,96 @@
+package sink
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Mock LogEntry for testing
+type mockLogEntry struct {
+	ID         string                 `json:"id"`
+	Timestamp  int64                  `json:"timestamp"`
+	Message    string                 `json:"message"`
+	Service    string                 `json:"service"`
+	Status     string                 `json:"status"`
+	Tags       []string               `json:"tags"`
+	Attributes map[string]interface{} `json:"attributes"`
+	Host       string                 `json:"host,omitempty"`
+	Source     string                 `json:"source,omitempty"`
+	TraceID    string                 `json:"trace_id,omitempty"`
+	SpanID     string                 `json:"span_id,omitempty"`
+}
+
+func (m *mockLogEntry) GetID() string                         { return m.ID }
+func (m *mockLogEntry) GetTimestamp() int64                   { return m.Timestamp }
+func (m *mockLogEntry) GetMessage() string                    { return m.Message }
+func (m *mockLogEntry) GetService() string                    { return m.Service }
+func (m *mockLogEntry) GetStatus() string                     { return m.Status }
+func (m *mockLogEntry) GetTags() []string                     { return m.Tags }
+func (m *mockLogEntry) GetAttributes() map[string]interface{} { return m.Attributes }
+func (m *mockLogEntry) GetHost() string                       { return m.Host }
+func (m *mockLogEntry) GetSource() string                     { return m.Source }
+func (m *mockLogEntry) GetTraceID() string                    { return m.TraceID }
+func (m *mockLogEntry) GetSpanID() string                     { return m.SpanID }
+
+func newTestLog(id, message string) *mockLogEntry {
+	return &mockLogEntry{
+		ID:        id,
+		Timestamp: 1642694400,
+		Message:   message,
+		Service:   "api",
+		Status:    "error",
+		Tags:      []string{"env:test"},
+	}
+}
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name          string
+		spec          string
+		wantType      string
+		wantErr       bool
+		errorContains string
+	}{
+		{"File sink", "file://" + filepath.Join(dir, "a.log"), "*sink.File", false, ""},
+		{"File sink relative", "file:" + filepath.Join(dir, "b.log") + "?format=json", "*sink.File", false, ""},
+		{"HTTP sink", "http://localhost:9000/ingest?batch=10&interval=2s", "*sink.HTTP", false, ""},
+		{"Unsupported scheme", "kafka://localhost:9092", "", true, "unsupported sink"},
+		{"Invalid format", "file://" + filepath.Join(dir, "c.log") + "?format=xml", "", true, "invalid format"},
+		{"Invalid batch", "http://localhost:9000?batch=0", "", true, "invalid batch size"},
+		{"Missing syslog address", "syslog+tcp://", "", true, "syslog address is required"},
+		{"Missing unix path", "unix://", "", true, "socket path is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Parse() expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Parse() error = %v, want to contain %v", err, tt.errorContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			defer func() { _ = s.Close() }()
+
+			var got string
+			switch s.(type) {
+			case *File:
+				got = "*sink.File"
+			case *HTTP:
+				got = "*sink.HTTP"
+			}
+			if got != tt.wantType {
+				t.Errorf("Parse(%q) = %s, want %s", tt.spec, got, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package sink
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+// syslog facility codes (RFC5424 section 6.2.1)
+var facilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// Syslog sends log entries as RFC5424 messages over UDP or TCP
+type Syslog struct {
+	network   string
+	addr      string
+	appName   string
+	facility  int
+	hostname  string
+	formatter output.Formatter
+	conn      net.Conn
+}
+
+// NewSyslog creates a new syslog sink. appName defaults to the entry's
+// service and facility defaults to user.
+func NewSyslog(network, addr, appName, facility string, formatter output.Formatter) (*Syslog, error) {
+	fac := facilities["user"]
+	if facility != "" {
+		f, ok := facilities[strings.ToLower(facility)]
+		if !ok {
+			return nil, fmt.Errorf("invalid syslog facility: %s", facility)
+		}
+		fac = f
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	s := &Syslog{
+		network:   network,
+		addr:      addr,
+		appName:   appName,
+		facility:  fac,
+		hostname:  hostname,
+		formatter: formatter,
+	}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Syslog) connect() error {
+	conn, err := net.DialTimeout(s.network, s.addr, 10*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog %s://%s: %w", s.network, s.addr, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// Write sends a single log entry, reconnecting once if the connection was lost
+func (s *Syslog) Write(log output.LogEntry) error {
+	msg, err := s.message(log)
+	if err != nil {
+		return err
+	}
+
+	frame := msg
+	if s.network == "tcp" {
+		// Octet-counting framing (RFC6587 section 3.4.1)
+		frame = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	if _, err := s.conn.Write([]byte(frame)); err != nil {
+		_ = s.conn.Close()
+		if err := s.connect(); err != nil {
+			return err
+		}
+		if _, err := s.conn.Write([]byte(frame)); err != nil {
+			return fmt.Errorf("failed to write to syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection
+func (s *Syslog) Close() error {
+	return s.conn.Close()
+}
+
+// message builds an RFC5424 message:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (s *Syslog) message(log output.LogEntry) (string, error) {
+	formatted, err := s.formatter.Format(log)
+	if err != nil {
+		return "", err
+	}
+
+	pri := s.facility*8 + severity(log.GetStatus())
+	timestamp := time.Unix(log.GetTimestamp(), 0).UTC().Format(time.RFC3339)
+
+	appName := s.appName
+	if appName == "" {
+		appName = log.GetService()
+	}
+
+	hostname := log.GetHost()
+	if hostname == "" {
+		hostname = s.hostname
+	}
+
+	sd := "-"
+	if id := log.GetID(); id != "" {
+		sd = fmt.Sprintf(`[dlt@32473 id="%s"]`, escapeSDParam(id))
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s - - %s %s",
+		pri,
+		timestamp,
+		headerField(hostname, 255),
+		headerField(appName, 48),
+		sd,
+		formatted,
+	), nil
+}
+
+// severity maps a Datadog status to a syslog severity
+func severity(status string) int {
+	switch strings.ToLower(status) {
+	case "emerg", "emergency":
+		return 0
+	case "alert":
+		return 1
+	case "crit", "critical":
+		return 2
+	case "error", "err":
+		return 3
+	case "warn", "warning":
+		return 4
+	case "notice":
+		return 5
+	case "debug", "trace":
+		return 7
+	default:
+		return 6 // info
+	}
+}
+
+// headerField sanitizes a header field to printable US-ASCII without spaces
+func headerField(s string, maxLen int) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 32 && r < 127 {
+			b.WriteRune(r)
+		}
+		if b.Len() >= maxLen {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
+
+// escapeSDParam escapes a structured data parameter value
+func escapeSDParam(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
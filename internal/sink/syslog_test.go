@@ -0,0 +1,136 @@
+package sink
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+func TestSyslog_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer func() { _ = pc.Close() }()
+
+	s, err := NewSyslog("udp", pc.LocalAddr().String(), "", "local0", output.NewFormatter("text"))
+	if err != nil {
+		t.Fatalf("NewSyslog() error = %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	if err := s.Write(newTestLog("abc", "disk full")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	buf := make([]byte, 2048)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("failed to read datagram: %v", err)
+	}
+	msg := string(buf[:n])
+
+	// local0 (16) * 8 + error (3) = 131
+	if !strings.HasPrefix(msg, "<131>1 2022-01-20T16:00:00Z ") {
+		t.Errorf("unexpected header: %q", msg)
+	}
+	if !strings.Contains(msg, ` api - - [dlt@32473 id="abc"] `) {
+		t.Errorf("missing app name or structured data: %q", msg)
+	}
+	if !strings.HasSuffix(msg, "disk full [env:test]") {
+		t.Errorf("missing message: %q", msg)
+	}
+}
+
+func TestSyslog_TCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		lenStr, _ := r.ReadString(' ')
+		n, _ := strconv.Atoi(strings.TrimSpace(lenStr))
+		buf := make([]byte, n)
+		_, _ = r.Read(buf)
+		received <- string(buf)
+	}()
+
+	s, err := NewSyslog("tcp", ln.Addr().String(), "dlt", "", output.NewFormatter("text"))
+	if err != nil {
+		t.Fatalf("NewSyslog() error = %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	if err := s.Write(newTestLog("abc", "hello")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		// user (1) * 8 + error (3) = 11
+		if !strings.HasPrefix(msg, "<11>1 ") || !strings.Contains(msg, " dlt - - ") {
+			t.Errorf("unexpected message: %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for syslog message")
+	}
+}
+
+func TestSyslog_HostFromLog(t *testing.T) {
+	s := &Syslog{hostname: "local", facility: 1, formatter: output.NewFormatter("text")}
+
+	log := newTestLog("abc", "hello")
+	msg, err := s.message(log)
+	if err != nil {
+		t.Fatalf("message() error = %v", err)
+	}
+	if !strings.Contains(msg, " local api ") {
+		t.Errorf("expected local hostname without a log host: %q", msg)
+	}
+
+	log.Host = "web-1"
+	msg, err = s.message(log)
+	if err != nil {
+		t.Fatalf("message() error = %v", err)
+	}
+	if !strings.Contains(msg, " web-1 api ") {
+		t.Errorf("expected the log's host as HOSTNAME: %q", msg)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		status string
+		want   int
+	}{
+		{"error", 3},
+		{"ERROR", 3},
+		{"warn", 4},
+		{"warning", 4},
+		{"info", 6},
+		{"debug", 7},
+		{"critical", 2},
+		{"", 6},
+	}
+
+	for _, tt := range tests {
+		if got := severity(tt.status); got != tt.want {
+			t.Errorf("severity(%q) = %d, want %d", tt.status, got, tt.want)
+		}
+	}
+}
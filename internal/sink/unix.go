@@ -0,0 +1,42 @@
+package sink
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+// Unix writes newline-delimited log entries to a Unix domain socket
+type Unix struct {
+	path string
+	conn net.Conn
+	line *lineWriter
+}
+
+// NewUnix creates a new Unix domain socket sink
+func NewUnix(path string, formatter output.Formatter) (*Unix, error) {
+	conn, err := net.DialTimeout("unix", path, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to unix socket %s: %w", path, err)
+	}
+	return &Unix{
+		path: path,
+		conn: conn,
+		line: &lineWriter{w: conn, formatter: formatter},
+	}, nil
+}
+
+// Write sends a single log entry
+func (u *Unix) Write(log output.LogEntry) error {
+	if err := u.line.Write(log); err != nil {
+		return fmt.Errorf("failed to write to unix socket %s: %w", u.path, err)
+	}
+	return nil
+}
+
+// Close closes the connection
+func (u *Unix) Close() error {
+	return u.conn.Close()
+}
@@ -0,0 +1,56 @@
+package sink
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jedipunkz/datadog-log-tail/internal/output"
+)
+
+func TestUnix_Write(t *testing.T) {
+	dir, err := os.MkdirTemp("", "dlt")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// Keep the path short enough for sun_path limits
+	path := filepath.Join(dir, "s.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+
+	u, err := NewUnix(path, output.NewFormatter("json"))
+	if err != nil {
+		t.Fatalf("NewUnix() error = %v", err)
+	}
+	if err := u.Write(newTestLog("abc", "hello")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	defer func() { _ = u.Close() }()
+
+	var got mockLogEntry
+	if err := json.Unmarshal([]byte(<-received), &got); err != nil {
+		t.Fatalf("received line is not JSON: %v", err)
+	}
+	if got.ID != "abc" || got.Message != "hello" {
+		t.Errorf("received = %+v, want id abc and message hello", got)
+	}
+}
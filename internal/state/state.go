@@ -0,0 +1,79 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// currentVersion is the state file format version
+const currentVersion = 1
+
+// State is the persisted tail cursor
+type State struct {
+	Version       int       `json:"version"`
+	LastTimestamp time.Time `json:"last_timestamp"`
+	SeenIDs       []string  `json:"seen_ids"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Load reads the state file. A missing file yields an empty state.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &State{Version: currentVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	if s.Version > currentVersion {
+		return nil, fmt.Errorf("unsupported state file version %d in %s", s.Version, path)
+	}
+	return &s, nil
+}
+
+// Save writes the state file atomically so that a crash never leaves a
+// partially written file behind
+func Save(path string, s *State) error {
+	s.Version = currentVersion
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary state file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close state file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad_MissingFile(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !s.LastTimestamp.IsZero() || len(s.SeenIDs) != 0 {
+		t.Errorf("Load() = %+v, want empty state", s)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "dlt.state")
+	ts := time.Date(2024, 1, 15, 10, 0, 0, 123000000, time.UTC)
+
+	if err := Save(path, &State{LastTimestamp: ts, SeenIDs: []string{"a", "b"}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !s.LastTimestamp.Equal(ts) {
+		t.Errorf("LastTimestamp = %v, want %v", s.LastTimestamp, ts)
+	}
+	if len(s.SeenIDs) != 2 || s.SeenIDs[0] != "a" || s.SeenIDs[1] != "b" {
+		t.Errorf("SeenIDs = %v, want [a b]", s.SeenIDs)
+	}
+	if s.Version != currentVersion {
+		t.Errorf("Version = %d, want %d", s.Version, currentVersion)
+	}
+
+	// No temporary files are left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("state directory has %d entries, want 1", len(entries))
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		errorContains string
+	}{
+		{"Invalid JSON", "{not json", "failed to parse state file"},
+		{"Future version", `{"version": 99}`, "unsupported state file version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "dlt.state")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("failed to write state file: %v", err)
+			}
+			_, err := Load(path)
+			if err == nil {
+				t.Fatal("Load() expected error but got none")
+			}
+			if !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Load() error = %v, want to contain %v", err, tt.errorContains)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package timerange
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateTimeLayouts are the accepted layouts of values with a date. Layouts
+// without a zone are read in local time.
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// timeLayouts are the accepted layouts of time-only values
+var timeLayouts = []string{
+	"15:04:05Z07:00",
+	"15:04Z07:00",
+	"15:04:05",
+	"15:04",
+}
+
+// ParseTime parses a point in time. Accepted values are:
+//
+//	2024-01-15T10:00:00Z   RFC3339, seconds and zone optional (local time)
+//	2024-01-15             midnight, local time
+//	10:00Z, 10:00:30+09:00 a time of day on the date of ref; a time earlier
+//	                       than ref is taken on the following day. Without
+//	                       ref, the most recent past occurrence is taken.
+//	now, now-2h            relative to the current time
+func ParseTime(s string, ref time.Time) (time.Time, error) {
+	return parseTime(s, ref, time.Now())
+}
+
+// parseTime is ParseTime at the given current time
+func parseTime(s string, ref, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "now" {
+		return now, nil
+	}
+	if rest, ok := strings.CutPrefix(s, "now-"); ok {
+		d, err := time.ParseDuration(rest)
+		if err != nil || d < 0 {
+			return time.Time{}, fmt.Errorf("invalid time %q: now- must be followed by a duration such as 2h", s)
+		}
+		return now.Add(-d), nil
+	}
+
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	for _, layout := range timeLayouts {
+		clock, err := time.ParseInLocation(layout, s, time.Local)
+		if err != nil {
+			continue
+		}
+		// Take the date of the reference (or today) as seen in the value's zone
+		day := ref
+		if ref.IsZero() {
+			day = now
+		}
+		day = day.In(clock.Location())
+		t := time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), clock.Location())
+		switch {
+		case ref.IsZero() && t.After(now):
+			t = t.AddDate(0, 0, -1)
+		case !ref.IsZero() && t.Before(ref):
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use e.g. 2024-01-15T10:00:00Z, 10:00Z or now-1h)", s)
+}
+
+// Parse parses a from,to range. A time-only end inherits the date of the
+// start, and a time-only start the date of ref, or without ref is the most
+// recent past occurrence of the time.
+func Parse(s string, ref time.Time) (time.Time, time.Time, error) {
+	return parse(s, ref, time.Now())
+}
+
+// parse is Parse at the given current time
+func parse(s string, ref, now time.Time) (time.Time, time.Time, error) {
+	fromStr, toStr, ok := strings.Cut(s, ",")
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid time range %q: must be from,to", s)
+	}
+	from, err := parseTime(fromStr, ref, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseTime(toStr, from, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid time range %q: end must be after start", s)
+	}
+	return from, to, nil
+}
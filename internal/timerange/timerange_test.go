@@ -0,0 +1,111 @@
+package timerange
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	ref := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	jst := time.FixedZone("", 9*60*60)
+
+	tests := []struct {
+		name  string
+		input string
+		ref   time.Time
+		want  time.Time
+	}{
+		{"RFC3339", "2024-01-15T10:00:00Z", ref, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{"Without seconds", "2024-01-15T10:30Z", ref, time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"Without zone", "2024-01-15T10:30", ref, time.Date(2024, 1, 15, 10, 30, 0, 0, time.Local)},
+		{"Date only", "2024-01-16", ref, time.Date(2024, 1, 16, 0, 0, 0, 0, time.Local)},
+		{"Time inherits the date", "10:00Z", ref, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{"Time with offset", "18:30:15+09:00", ref, time.Date(2024, 1, 15, 18, 30, 15, 0, jst)},
+		{"Time before the reference is the next day", "01:00Z", time.Date(2024, 1, 15, 23, 0, 0, 0, time.UTC), time.Date(2024, 1, 16, 1, 0, 0, 0, time.UTC)},
+		{"Time equal to the reference", "09:00Z", ref, ref},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTime(tt.input, tt.ref)
+			if err != nil {
+				t.Fatalf("ParseTime(%q) error = %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTime(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_WithoutReference(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2024, 1, day, hour, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		input    string
+		now      time.Time
+		wantFrom time.Time
+		wantTo   time.Time
+	}{
+		{"Earlier today", "09:00Z,10:00Z", at(15, 15), at(15, 9), at(15, 10)},
+		{"Later than now is yesterday", "09:00Z,10:00Z", at(15, 8), at(14, 9), at(14, 10)},
+		{"Range across midnight", "23:00Z,01:00Z", at(15, 2), at(14, 23), at(15, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := parse(tt.input, time.Time{}, tt.now)
+			if err != nil {
+				t.Fatalf("parse(%q) error = %v", tt.input, err)
+			}
+			if !from.Equal(tt.wantFrom) || !to.Equal(tt.wantTo) {
+				t.Errorf("parse(%q) = %v, %v, want %v, %v", tt.input, from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestParseTime_Relative(t *testing.T) {
+	got, err := ParseTime("now-2h", time.Time{})
+	if err != nil {
+		t.Fatalf("ParseTime() error = %v", err)
+	}
+	if d := time.Since(got) - 2*time.Hour; d < 0 || d > time.Minute {
+		t.Errorf("ParseTime(now-2h) = %v, want two hours ago", got)
+	}
+
+	if _, err := ParseTime("now-yesterday", time.Time{}); err == nil {
+		t.Error("ParseTime(now-yesterday) expected error but got none")
+	}
+}
+
+func TestParse(t *testing.T) {
+	ref := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	from, to, err := Parse("2024-01-15T09:00Z,10:00Z", ref)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !from.Equal(time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Parse() = %v, %v", from, to)
+	}
+
+	tests := []struct {
+		name          string
+		input         string
+		errorContains string
+	}{
+		{"Not a range", "10:00Z", "must be from,to"},
+		{"Invalid time", "yesterday,10:00Z", "invalid time"},
+		{"Empty range", "2024-01-15T10:00Z,2024-01-15T10:00Z", "end must be after start"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Parse(tt.input, ref)
+			if err == nil || !strings.Contains(err.Error(), tt.errorContains) {
+				t.Errorf("Parse(%q) error = %v, want error containing %q", tt.input, err, tt.errorContains)
+			}
+		})
+	}
+}